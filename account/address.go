@@ -0,0 +1,127 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/segmentio/ksuid"
+)
+
+var (
+	ErrAddressNotFound  = errors.New("address not found")
+	ErrInvalidAddress   = errors.New("invalid address")
+	ErrTooManyAddresses = errors.New("address book is full")
+)
+
+// maxAddresses is the most addresses an account may keep.
+const maxAddresses = 20
+
+// Address is an entry of an account's address book. At most one address of an
+// account is its default shipping address and one its default billing
+// address; marking another as default clears the flag on the old one.
+type Address struct {
+	ID        string `json:"id"`
+	AccountID string `json:"account_id"`
+	// Name is who the address is for.
+	Name  string `json:"name"`
+	Line1 string `json:"line1"`
+	Line2 string `json:"line2,omitempty"`
+	City  string `json:"city"`
+	// Region is the state, province or county; ideally its ISO 3166-2 code
+	// such as "CA", which is also used to tax orders shipped there.
+	Region     string `json:"region,omitempty"`
+	PostalCode string `json:"postal_code,omitempty"`
+	// Country is an ISO 3166-1 alpha-2 code such as "DE".
+	Country         string    `json:"country"`
+	Phone           string    `json:"phone,omitempty"`
+	DefaultShipping bool      `json:"default_shipping"`
+	DefaultBilling  bool      `json:"default_billing"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// CreateAddress adds an address to the address book of an account that has
+// not been deleted. The first address an account adds becomes its default
+// shipping and billing address.
+func (s *accountService) CreateAddress(ctx context.Context, accountID string, address *Address) (*Address, error) {
+	a := *address
+	now := time.Now().UTC()
+	a.ID = ksuid.New().String()
+	a.AccountID = accountID
+	a.CreatedAt, a.UpdatedAt = now, now
+	if err := normalizeAddress(&a); err != nil {
+		return nil, err
+	}
+	if err := s.repo.PutAddress(ctx, &a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// GetAddresses returns the address book of an account, oldest first.
+func (s *accountService) GetAddresses(ctx context.Context, accountID string) ([]*Address, error) {
+	return s.repo.ListAddresses(ctx, accountID)
+}
+
+// GetAddress returns an address of an account. Addresses of other accounts
+// fail with ErrAddressNotFound.
+func (s *accountService) GetAddress(ctx context.Context, accountID, id string) (*Address, error) {
+	return s.repo.GetAddress(ctx, accountID, id)
+}
+
+// UpdateAddress replaces every field of an address of an account. Clearing
+// the default flags of an address leaves the account without that default.
+func (s *accountService) UpdateAddress(ctx context.Context, accountID string, address *Address) (*Address, error) {
+	existing, err := s.repo.GetAddress(ctx, accountID, address.ID)
+	if err != nil {
+		return nil, err
+	}
+	a := *address
+	a.AccountID = accountID
+	a.CreatedAt = existing.CreatedAt
+	a.UpdatedAt = time.Now().UTC()
+	if err := normalizeAddress(&a); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateAddress(ctx, &a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// DeleteAddress removes an address from the address book of an account and
+// returns it. Orders keep their own copy of the addresses they ship to.
+func (s *accountService) DeleteAddress(ctx context.Context, accountID, id string) (*Address, error) {
+	address, err := s.repo.GetAddress(ctx, accountID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.DeleteAddress(ctx, accountID, id); err != nil {
+		return nil, err
+	}
+	return address, nil
+}
+
+// normalizeAddress trims the fields of an address, upper-cases its country and
+// postal code and checks that it can be shipped to.
+func normalizeAddress(a *Address) error {
+	for _, field := range []*string{&a.Name, &a.Line1, &a.Line2, &a.City, &a.Region, &a.PostalCode, &a.Country, &a.Phone} {
+		*field = strings.TrimSpace(*field)
+	}
+	a.Country = strings.ToUpper(a.Country)
+	a.PostalCode = strings.ToUpper(a.PostalCode)
+	if a.Name == "" || a.Line1 == "" || a.City == "" {
+		return fmt.Errorf("%w: name, first line and city are required", ErrInvalidAddress)
+	}
+	if len(a.Country) != 2 || strings.Trim(a.Country, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return fmt.Errorf("%w: country must be a two-letter ISO 3166-1 code", ErrInvalidAddress)
+	}
+	if len(a.Name) > 255 || len(a.Line1) > 255 || len(a.Line2) > 255 || len(a.City) > 255 || len(a.Region) > 64 ||
+		len(a.PostalCode) > 16 || len(a.Phone) > 32 {
+		return fmt.Errorf("%w: a field is too long", ErrInvalidAddress)
+	}
+	return nil
+}
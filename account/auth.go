@@ -0,0 +1,172 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"microservice/auth"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/segmentio/ksuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrEmailTaken         = errors.New("email is already registered")
+	ErrInvalidCredentials = errors.New("invalid email or password")
+)
+
+const (
+	accessTokenTTL  = 15 * time.Minute
+	refreshTokenTTL = 30 * 24 * time.Hour
+
+	minPasswordLength = 8
+	// bcrypt ignores everything past 72 bytes, so longer passwords are refused
+	// rather than silently truncated.
+	maxPasswordLength = 72
+)
+
+// Tokens are the credentials handed out on registration and login. The access
+// token authorizes requests; the refresh token can be exchanged, once, for a
+// new pair.
+type Tokens struct {
+	AccessToken     string
+	RefreshToken    string
+	AccessExpiresAt time.Time
+}
+
+// dummyHash is compared against when an email is unknown, so that a login for
+// a missing account takes as long as one with a wrong password.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("not a real password"), bcrypt.DefaultCost)
+
+// Register creates an account that can log in with email and password, and
+// logs it in.
+func (s *accountService) Register(ctx context.Context, name, email, password string) (*Account, *Tokens, error) {
+	name = strings.TrimSpace(name)
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return nil, nil, err
+	}
+	if name == "" {
+		return nil, nil, fmt.Errorf("%w: name is required", ErrInvalidAccount)
+	}
+	if len(password) < minPasswordLength || len(password) > maxPasswordLength {
+		return nil, nil, fmt.Errorf("%w: password must be %d to %d bytes long", ErrInvalidAccount, minPasswordLength, maxPasswordLength)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	account := &Account{ID: ksuid.New().String(), Name: name, Email: email, Roles: []string{auth.RoleCustomer}}
+	if err := s.repo.RegisterAccount(ctx, account, string(hash)); err != nil {
+		return nil, nil, err
+	}
+	tokens, err := s.issueTokens(ctx, account)
+	if err != nil {
+		return nil, nil, err
+	}
+	return account, tokens, nil
+}
+
+// Login checks an email and password and issues tokens for the account.
+// Unknown emails, wrong passwords and deleted accounts all fail with
+// ErrInvalidCredentials.
+func (s *accountService) Login(ctx context.Context, email, password string) (*Account, *Tokens, error) {
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return nil, nil, ErrInvalidCredentials
+	}
+	account, hash, err := s.repo.GetCredentials(ctx, email)
+	if errors.Is(err, ErrNotFound) {
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return nil, nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) != nil || account.Deleted() {
+		return nil, nil, ErrInvalidCredentials
+	}
+	tokens, err := s.issueTokens(ctx, account)
+	if err != nil {
+		return nil, nil, err
+	}
+	return account, tokens, nil
+}
+
+// RefreshToken exchanges a refresh token for a new pair of tokens. Each refresh
+// token can only be used once.
+func (s *accountService) RefreshToken(ctx context.Context, refreshToken string) (*Account, *Tokens, error) {
+	claims, err := s.verifier.Verify(refreshToken, auth.RefreshToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	accountID, err := s.repo.UseRefreshToken(ctx, claims.ID, time.Now().UTC())
+	if errors.Is(err, ErrNotFound) || (err == nil && accountID != claims.AccountID()) {
+		return nil, nil, fmt.Errorf("%w: refresh token was already used or revoked", auth.ErrInvalidToken)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	account, err := s.repo.GetAccountById(ctx, accountID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if account.Deleted() {
+		return nil, nil, fmt.Errorf("%w: account has been deleted", auth.ErrInvalidToken)
+	}
+	tokens, err := s.issueTokens(ctx, account)
+	if err != nil {
+		return nil, nil, err
+	}
+	return account, tokens, nil
+}
+
+// issueTokens signs a new access and refresh token for an account and records
+// the refresh token so that it can only be used once.
+func (s *accountService) issueTokens(ctx context.Context, account *Account) (*Tokens, error) {
+	now := time.Now().UTC()
+	accessClaims := newClaims(account.ID, auth.AccessToken, now, accessTokenTTL)
+	// Roles are read again on every refresh, so changes apply within one
+	// access token lifetime.
+	accessClaims.Roles = account.Roles
+	access, err := s.signer.Sign(accessClaims)
+	if err != nil {
+		return nil, err
+	}
+	refreshClaims := newClaims(account.ID, auth.RefreshToken, now, refreshTokenTTL)
+	refresh, err := s.signer.Sign(refreshClaims)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.PutRefreshToken(ctx, refreshClaims.ID, account.ID, refreshClaims.ExpiresAt.Time); err != nil {
+		return nil, err
+	}
+	return &Tokens{AccessToken: access, RefreshToken: refresh, AccessExpiresAt: now.Add(accessTokenTTL)}, nil
+}
+
+func newClaims(accountID string, typ auth.TokenType, now time.Time, ttl time.Duration) *auth.Claims {
+	return &auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        ksuid.New().String(),
+			Issuer:    auth.Issuer,
+			Subject:   accountID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+		Type: typ,
+	}
+}
+
+func normalizeEmail(email string) (string, error) {
+	addr, err := mail.ParseAddress(strings.TrimSpace(email))
+	if err != nil || addr.Name != "" {
+		return "", fmt.Errorf("%w: invalid email address", ErrInvalidAccount)
+	}
+	return strings.ToLower(addr.Address), nil
+}
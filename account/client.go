@@ -3,6 +3,8 @@ package account
 import (
 	"context"
 	pb "microservice/account/pb"
+	"microservice/auth"
+	"time"
 
 	"google.golang.org/grpc"
 )
@@ -13,7 +15,8 @@ type Client struct {
 }
 
 func NewClient(address string) (*Client, error) {
-	conn, err := grpc.Dial(address, grpc.WithInsecure())
+	// Forward the caller's token, which SetAccountRoles requires
+	conn, err := grpc.Dial(address, grpc.WithInsecure(), grpc.WithUnaryInterceptor(auth.UnaryClientInterceptor()))
 	if err != nil {
 		return nil, err
 	}
@@ -31,7 +34,7 @@ func (c *Client) PostAccount(ctx context.Context, name string) (*Account, error)
 	if err != nil {
 		return nil, err
 	}
-	return &Account{ID: resp.Account.Id, Name: resp.Account.Name}, nil
+	return convertAccount(resp.Account), nil
 }
 
 func (c *Client) GetAccount(ctx context.Context, id string) (*Account, error) {
@@ -40,11 +43,11 @@ func (c *Client) GetAccount(ctx context.Context, id string) (*Account, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &Account{ID: resp.Account.Id, Name: resp.Account.Name}, nil
+	return convertAccount(resp.Account), nil
 }
 
-func (c *Client) GetAccounts(ctx context.Context, skip int, take int) ([]*Account, error) {
-	req := &pb.GetAccountsRequest{Skip: uint64(skip), Take: uint64(take)}
+func (c *Client) GetAccounts(ctx context.Context, skip int, take int, includeDeleted bool) ([]*Account, error) {
+	req := &pb.GetAccountsRequest{Skip: uint64(skip), Take: uint64(take), IncludeDeleted: includeDeleted}
 	resp, err := c.service.GetAccounts(ctx, req)
 	if err != nil {
 		return nil, err
@@ -55,7 +58,118 @@ func (c *Client) GetAccounts(ctx context.Context, skip int, take int) ([]*Accoun
 func convertAccounts(accounts []*pb.Account) []*Account {
 	var result []*Account
 	for _, acc := range accounts {
-		result = append(result, &Account{ID: acc.Id, Name: acc.Name})
+		result = append(result, convertAccount(acc))
 	}
 	return result
 }
+
+func convertAccount(acc *pb.Account) *Account {
+	account := &Account{ID: acc.Id, Name: acc.Name, Email: acc.Email, Roles: acc.Roles}
+	if len(acc.DeletedAt) > 0 {
+		deletedAt := time.Time{}
+		deletedAt.UnmarshalBinary(acc.DeletedAt)
+		account.DeletedAt = &deletedAt
+	}
+	return account
+}
+
+func (c *Client) UpdateAccount(ctx context.Context, id string, name string) (*Account, error) {
+	resp, err := c.service.UpdateAccount(ctx, &pb.UpdateAccountRequest{Id: id, Name: name})
+	if err != nil {
+		return nil, err
+	}
+	return convertAccount(resp.Account), nil
+}
+
+func (c *Client) DeleteAccount(ctx context.Context, id string) (*Account, error) {
+	resp, err := c.service.DeleteAccount(ctx, &pb.DeleteAccountRequest{Id: id})
+	if err != nil {
+		return nil, err
+	}
+	return convertAccount(resp.Account), nil
+}
+
+func (c *Client) SetAccountRoles(ctx context.Context, id string, roles []string) (*Account, error) {
+	resp, err := c.service.SetAccountRoles(ctx, &pb.SetAccountRolesRequest{Id: id, Roles: roles})
+	if err != nil {
+		return nil, err
+	}
+	return convertAccount(resp.Account), nil
+}
+
+func (c *Client) Register(ctx context.Context, name, email, password string) (*Account, *Tokens, error) {
+	resp, err := c.service.Register(ctx, &pb.RegisterRequest{Name: name, Email: email, Password: password})
+	if err != nil {
+		return nil, nil, err
+	}
+	return convertAccount(resp.Account), convertTokens(resp.Tokens), nil
+}
+
+func (c *Client) Login(ctx context.Context, email, password string) (*Account, *Tokens, error) {
+	resp, err := c.service.Login(ctx, &pb.LoginRequest{Email: email, Password: password})
+	if err != nil {
+		return nil, nil, err
+	}
+	return convertAccount(resp.Account), convertTokens(resp.Tokens), nil
+}
+
+func (c *Client) RefreshToken(ctx context.Context, refreshToken string) (*Account, *Tokens, error) {
+	resp, err := c.service.RefreshToken(ctx, &pb.RefreshTokenRequest{RefreshToken: refreshToken})
+	if err != nil {
+		return nil, nil, err
+	}
+	return convertAccount(resp.Account), convertTokens(resp.Tokens), nil
+}
+
+func convertTokens(t *pb.Tokens) *Tokens {
+	tokens := &Tokens{AccessToken: t.AccessToken, RefreshToken: t.RefreshToken}
+	tokens.AccessExpiresAt.UnmarshalBinary(t.AccessTokenExpiresAt)
+	return tokens
+}
+
+// CreateAddress adds an address to the address book of an account.
+func (c *Client) CreateAddress(ctx context.Context, accountID string, address *Address) (*Address, error) {
+	resp, err := c.service.CreateAddress(ctx, &pb.CreateAddressRequest{AccountId: accountID, Address: addressToProto(address)})
+	if err != nil {
+		return nil, err
+	}
+	return addressFromProto(resp.Address), nil
+}
+
+// GetAddresses returns the address book of an account, oldest first.
+func (c *Client) GetAddresses(ctx context.Context, accountID string) ([]*Address, error) {
+	resp, err := c.service.GetAddresses(ctx, &pb.GetAddressesRequest{AccountId: accountID})
+	if err != nil {
+		return nil, err
+	}
+	addresses := make([]*Address, 0, len(resp.Addresses))
+	for _, a := range resp.Addresses {
+		addresses = append(addresses, addressFromProto(a))
+	}
+	return addresses, nil
+}
+
+func (c *Client) GetAddress(ctx context.Context, accountID, id string) (*Address, error) {
+	resp, err := c.service.GetAddress(ctx, &pb.GetAddressRequest{AccountId: accountID, Id: id})
+	if err != nil {
+		return nil, err
+	}
+	return addressFromProto(resp.Address), nil
+}
+
+// UpdateAddress replaces every field of an address of an account.
+func (c *Client) UpdateAddress(ctx context.Context, accountID string, address *Address) (*Address, error) {
+	resp, err := c.service.UpdateAddress(ctx, &pb.UpdateAddressRequest{AccountId: accountID, Address: addressToProto(address)})
+	if err != nil {
+		return nil, err
+	}
+	return addressFromProto(resp.Address), nil
+}
+
+func (c *Client) DeleteAddress(ctx context.Context, accountID, id string) (*Address, error) {
+	resp, err := c.service.DeleteAddress(ctx, &pb.DeleteAddressRequest{AccountId: accountID, Id: id})
+	if err != nil {
+		return nil, err
+	}
+	return addressFromProto(resp.Address), nil
+}
@@ -3,15 +3,22 @@ package main
 import (
 	"fmt"
 	"log"
+	"net/http"
 	"time"
 
 	"microservice/account"
+	"microservice/auth"
 	envconfig "github.com/kelseyhightower/envconfig"
 	retry"github.com/tinrab/retry"
 )
 
 type Config struct {
-	databaseURL string `env:"DATABASE_URL,required"`
+	DatabaseURL string `envconfig:"DATABASE_URL" required:"true"`
+	// Tokens are signed with AuthSigningKey (a base64 Ed25519 seed, published
+	// as a JWKS document on JWKSPort) if set, else with the shared AuthSecret.
+	AuthSecret     string `envconfig:"AUTH_SECRET"`
+	AuthSigningKey string `envconfig:"AUTH_SIGNING_KEY"`
+	JWKSPort       int    `envconfig:"JWKS_PORT" default:"8081"`
 }
 
 
@@ -28,7 +35,7 @@ func main() {
 	var r account.Repository
 	retry.ForeverSleep(5*time.Second, func(attempt int) error {
 		var err error
-		r, err = account.NewPostgresRepository(cfg.databaseURL)
+		r, err = account.NewPostgresRepository(cfg.DatabaseURL)
 		if err != nil {
 			log.Printf("Failed to connect to Postgres (attempt %d): %v", attempt, err)
 		}
@@ -38,10 +45,30 @@ func main() {
 
 	fmt.Println("Connected to Postgres")
 	
+	// Set up token signing, publishing the public key if there is one.
+	var signer *auth.Signer
+	switch {
+	case cfg.AuthSigningKey != "":
+		key, err := auth.ParseEd25519Key(cfg.AuthSigningKey)
+		if err != nil {
+			log.Fatalf("Invalid AUTH_SIGNING_KEY: %v", err)
+		}
+		signer = auth.NewEd25519Signer(key)
+		go func() {
+			mux := http.NewServeMux()
+			mux.Handle(auth.JWKSPath, signer.JWKSHandler())
+			log.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", cfg.JWKSPort), mux))
+		}()
+	case cfg.AuthSecret != "":
+		signer = auth.NewHMACSigner([]byte(cfg.AuthSecret))
+	default:
+		log.Fatal("Either AUTH_SIGNING_KEY or AUTH_SECRET is required")
+	}
+
 	// Initialize the account service.
-	svc := account.NewService(r)
+	svc := account.NewService(r, signer)
 	// Start the gRPC server.
-	if err := account.ListenGRPC(svc, 8080); err != nil {
+	if err := account.ListenGRPC(svc, signer.Verifier(), 8080); err != nil {
 		log.Fatalf("Failed to start gRPC server: %v", err)
 	}
 }
\ No newline at end of file
@@ -3,16 +3,31 @@ package account
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"log"
+	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 type Repository interface {
 	Close()
 	PutAccount(ctx context.Context, account *Account) error
 	GetAccountById(ctx context.Context, id string) (*Account, error)
-	ListsAccounts(ctx context.Context, skip int, take int) ([]*Account, error)
+	ListsAccounts(ctx context.Context, skip int, take int, includeDeleted bool) ([]*Account, error)
+	UpdateAccount(ctx context.Context, account *Account) error
+	DeleteAccount(ctx context.Context, id string, at time.Time) error
+	SetAccountRoles(ctx context.Context, id string, roles []string) error
+	RegisterAccount(ctx context.Context, account *Account, passwordHash string) error
+	GetCredentials(ctx context.Context, email string) (*Account, string, error)
+	PutRefreshToken(ctx context.Context, id string, accountID string, expiresAt time.Time) error
+	UseRefreshToken(ctx context.Context, id string, at time.Time) (string, error)
+	PutAddress(ctx context.Context, address *Address) error
+	ListAddresses(ctx context.Context, accountID string) ([]*Address, error)
+	GetAddress(ctx context.Context, accountID, id string) (*Address, error)
+	UpdateAddress(ctx context.Context, address *Address) error
+	DeleteAddress(ctx context.Context, accountID, id string) error
 }
 
 type postgresRepository struct {
@@ -40,22 +55,27 @@ func (r *postgresRepository) Ping() error {
 }
 
 func (r *postgresRepository) PutAccount(ctx context.Context, account *Account) error {
-	_, err := r.db.ExecContext(ctx, "INSERT INTO accounts (id, name) VALUES ($1, $2)", account.ID, account.Name)
+	_, err := r.db.ExecContext(ctx, "INSERT INTO accounts (id, name, roles) VALUES ($1, $2, $3)", account.ID, account.Name, pq.Array(account.Roles))
 	return err
 }
 
 func (r *postgresRepository) GetAccountById(ctx context.Context, id string) (*Account, error) {
-	row := r.db.QueryRowContext(ctx, "SELECT id, name FROM accounts WHERE id = $1", id)
+	row := r.db.QueryRowContext(ctx, "SELECT id, name, COALESCE(email, ''), roles, deleted_at FROM accounts WHERE id = $1", id)
 	account := &Account{}
-	err := row.Scan(&account.ID, &account.Name)
+	err := row.Scan(&account.ID, &account.Name, &account.Email, pq.Array(&account.Roles), &account.DeletedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
 	return account, nil
 }
 
-func (r *postgresRepository) ListsAccounts(ctx context.Context, skip int, take int) ([]*Account, error) {
-	rows, err := r.db.QueryContext(ctx, "SELECT id, name FROM accounts ORDER BY id OFFSET $1 LIMIT $2", skip, take)
+func (r *postgresRepository) ListsAccounts(ctx context.Context, skip int, take int, includeDeleted bool) ([]*Account, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT id, name, COALESCE(email, ''), roles, deleted_at FROM accounts WHERE $3 OR deleted_at IS NULL ORDER BY id OFFSET $1 LIMIT $2",
+		skip, take, includeDeleted)
 	if err != nil {
 		return nil, err
 	}
@@ -64,11 +84,265 @@ func (r *postgresRepository) ListsAccounts(ctx context.Context, skip int, take i
 	var accounts []*Account
 	for rows.Next() {
 		account := &Account{}
-		err := rows.Scan(&account.ID, &account.Name)
+		err := rows.Scan(&account.ID, &account.Name, &account.Email, pq.Array(&account.Roles), &account.DeletedAt)
 		if err != nil {
 			return nil, err
 		}
 		accounts = append(accounts, account)
 	}
-	return accounts, nil
+	return accounts, rows.Err()
+}
+
+// UpdateAccount stores the account's new name. Deleted accounts cannot be
+// updated and fail with ErrNotFound.
+func (r *postgresRepository) UpdateAccount(ctx context.Context, account *Account) error {
+	res, err := r.db.ExecContext(ctx, "UPDATE accounts SET name = $2 WHERE id = $1 AND deleted_at IS NULL", account.ID, account.Name)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(res)
+}
+
+// DeleteAccount marks an account as deleted at the given time, keeping the row
+// so that existing references to it stay valid.
+func (r *postgresRepository) DeleteAccount(ctx context.Context, id string, at time.Time) error {
+	res, err := r.db.ExecContext(ctx, "UPDATE accounts SET deleted_at = $2 WHERE id = $1 AND deleted_at IS NULL", id, at)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(res)
+}
+
+// SetAccountRoles replaces the roles of an account. Deleted accounts fail with
+// ErrNotFound.
+func (r *postgresRepository) SetAccountRoles(ctx context.Context, id string, roles []string) error {
+	res, err := r.db.ExecContext(ctx, "UPDATE accounts SET roles = $2 WHERE id = $1 AND deleted_at IS NULL", id, pq.Array(roles))
+	if err != nil {
+		return err
+	}
+	return expectOneRow(res)
+}
+
+func expectOneRow(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// RegisterAccount stores an account together with its password hash. It fails
+// with ErrEmailTaken if the email belongs to another account.
+func (r *postgresRepository) RegisterAccount(ctx context.Context, account *Account, passwordHash string) error {
+	_, err := r.db.ExecContext(ctx,
+		"INSERT INTO accounts (id, name, email, password_hash, roles) VALUES ($1, $2, $3, $4, $5)",
+		account.ID, account.Name, account.Email, passwordHash, pq.Array(account.Roles))
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "accounts_email_key" {
+		return ErrEmailTaken
+	}
+	return err
+}
+
+// GetCredentials returns the account registered under email and its password
+// hash.
+func (r *postgresRepository) GetCredentials(ctx context.Context, email string) (*Account, string, error) {
+	row := r.db.QueryRowContext(ctx,
+		"SELECT id, name, email, roles, deleted_at, password_hash FROM accounts WHERE email = $1 AND password_hash IS NOT NULL", email)
+	account := &Account{}
+	var hash string
+	err := row.Scan(&account.ID, &account.Name, &account.Email, pq.Array(&account.Roles), &account.DeletedAt, &hash)
+	if err == sql.ErrNoRows {
+		return nil, "", ErrNotFound
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return account, hash, nil
+}
+
+func (r *postgresRepository) PutRefreshToken(ctx context.Context, id string, accountID string, expiresAt time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		"INSERT INTO account_refresh_tokens (id, account_id, expires_at) VALUES ($1, $2, $3)",
+		id, accountID, expiresAt)
+	return err
+}
+
+// UseRefreshToken marks an unexpired refresh token as used and returns the
+// account it belongs to. A token that is unknown, expired or already used
+// fails with ErrNotFound.
+func (r *postgresRepository) UseRefreshToken(ctx context.Context, id string, at time.Time) (string, error) {
+	var accountID string
+	err := r.db.QueryRowContext(ctx,
+		"UPDATE account_refresh_tokens SET used_at = $2 WHERE id = $1 AND used_at IS NULL AND expires_at > $2 RETURNING account_id",
+		id, at).Scan(&accountID)
+	if err == sql.ErrNoRows {
+		return "", ErrNotFound
+	}
+	return accountID, err
+}
+
+const addressColumns = "id, account_id, name, line1, line2, city, region, postal_code, country, phone, default_shipping, default_billing, created_at, updated_at"
+
+// PutAddress stores a new address. The account's first address becomes its
+// default for shipping and billing, and an address stored as a default takes
+// over from the previous one. Deleted accounts fail with ErrNotFound and
+// accounts with maxAddresses already with ErrTooManyAddresses.
+func (r *postgresRepository) PutAddress(ctx context.Context, address *Address) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	// Locking the account serializes changes to its address book
+	var id string
+	err = tx.QueryRowContext(ctx, "SELECT id FROM accounts WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", address.AccountID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	var count int
+	if err = tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM account_addresses WHERE account_id = $1", address.AccountID).Scan(&count); err != nil {
+		return err
+	}
+	if count >= maxAddresses {
+		return fmt.Errorf("%w: at most %d addresses", ErrTooManyAddresses, maxAddresses)
+	}
+	if count == 0 {
+		address.DefaultShipping, address.DefaultBilling = true, true
+	}
+	if err = clearDefaults(ctx, tx, address); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO account_addresses ("+addressColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)",
+		address.ID, address.AccountID, address.Name, address.Line1, address.Line2, address.City, address.Region,
+		address.PostalCode, address.Country, address.Phone, address.DefaultShipping, address.DefaultBilling,
+		address.CreatedAt, address.UpdatedAt)
+	return err
+}
+
+// clearDefaults takes the default flags that address is about to claim off
+// the account's other addresses.
+func clearDefaults(ctx context.Context, tx *sql.Tx, address *Address) error {
+	if address.DefaultShipping {
+		_, err := tx.ExecContext(ctx,
+			"UPDATE account_addresses SET default_shipping = FALSE WHERE account_id = $1 AND id <> $2 AND default_shipping",
+			address.AccountID, address.ID)
+		if err != nil {
+			return err
+		}
+	}
+	if address.DefaultBilling {
+		_, err := tx.ExecContext(ctx,
+			"UPDATE account_addresses SET default_billing = FALSE WHERE account_id = $1 AND id <> $2 AND default_billing",
+			address.AccountID, address.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *postgresRepository) ListAddresses(ctx context.Context, accountID string) ([]*Address, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT "+addressColumns+" FROM account_addresses WHERE account_id = $1 ORDER BY created_at, id", accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	addresses := []*Address{}
+	for rows.Next() {
+		address, err := scanAddress(rows)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, address)
+	}
+	return addresses, rows.Err()
+}
+
+func (r *postgresRepository) GetAddress(ctx context.Context, accountID, id string) (*Address, error) {
+	row := r.db.QueryRowContext(ctx,
+		"SELECT "+addressColumns+" FROM account_addresses WHERE account_id = $1 AND id = $2", accountID, id)
+	address, err := scanAddress(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrAddressNotFound
+	}
+	return address, err
+}
+
+// UpdateAddress replaces an address, taking the default flags it claims off
+// the account's other addresses.
+func (r *postgresRepository) UpdateAddress(ctx context.Context, address *Address) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	if err = clearDefaults(ctx, tx, address); err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx,
+		`UPDATE account_addresses SET name = $3, line1 = $4, line2 = $5, city = $6, region = $7, postal_code = $8,
+			country = $9, phone = $10, default_shipping = $11, default_billing = $12, updated_at = $13
+		WHERE account_id = $1 AND id = $2`,
+		address.AccountID, address.ID, address.Name, address.Line1, address.Line2, address.City, address.Region,
+		address.PostalCode, address.Country, address.Phone, address.DefaultShipping, address.DefaultBilling, address.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrAddressNotFound
+	}
+	return nil
+}
+
+func (r *postgresRepository) DeleteAddress(ctx context.Context, accountID, id string) error {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM account_addresses WHERE account_id = $1 AND id = $2", accountID, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrAddressNotFound
+	}
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAddress(row rowScanner) (*Address, error) {
+	a := &Address{}
+	err := row.Scan(&a.ID, &a.AccountID, &a.Name, &a.Line1, &a.Line2, &a.City, &a.Region, &a.PostalCode,
+		&a.Country, &a.Phone, &a.DefaultShipping, &a.DefaultBilling, &a.CreatedAt, &a.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
 }
@@ -2,13 +2,17 @@ package account
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 
-	pb "microservice/account/pb" // generated via: protoc --go_out=. --go-grpc_out=. account/account.proto
+	pb "microservice/account/pb"
+	"microservice/auth" // generated via: protoc --go_out=. --go-grpc_out=. account/account.proto
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 )
 
 // grpcServer implements the generated gRPC AccountServiceServer.
@@ -17,13 +21,20 @@ type grpcServer struct {
 	service Service
 }
 
-// ListenGRPC starts a gRPC server for the Account service.
-func ListenGRPC(service Service, port int) error {
+// requiredRoles lists the RPCs that need a role beyond being signed in, with
+// the role needed to call them.
+var requiredRoles = map[string]string{
+	pb.AccountService_SetAccountRoles_FullMethodName: auth.RoleAdmin,
+}
+
+// ListenGRPC starts a gRPC server for the Account service. Callers are
+// authenticated by the bearer token in their metadata, checked by verifier.
+func ListenGRPC(service Service, verifier *auth.Verifier, port int) error {
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
 	if err != nil {
 		return err
 	}
-	s := grpc.NewServer()
+	s := grpc.NewServer(grpc.UnaryInterceptor(auth.UnaryServerInterceptor(verifier, requiredRoles)))
 	pb.RegisterAccountServiceServer(s, &grpcServer{service: service})
 	reflection.Register(s)
 	return s.Serve(lis)
@@ -32,27 +43,200 @@ func ListenGRPC(service Service, port int) error {
 func (s *grpcServer) PostAccount(ctx context.Context, req *pb.PostAccountRequest) (*pb.PostAccountResponse, error) {
 	acc, err := s.service.PostAccount(ctx, req.Name)
 	if err != nil {
-		return nil, err
+		return nil, grpcError(err)
 	}
-	return &pb.PostAccountResponse{Account: &pb.Account{Id: acc.ID, Name: acc.Name}}, nil
+	return &pb.PostAccountResponse{Account: accountToProto(acc)}, nil
 }
 
 func (s *grpcServer) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
 	acc, err := s.service.GetAccount(ctx, req.Id)
 	if err != nil {
-		return nil, err
+		return nil, grpcError(err)
 	}
-	return &pb.GetAccountResponse{Account: &pb.Account{Id: acc.ID, Name: acc.Name}}, nil
+	return &pb.GetAccountResponse{Account: accountToProto(acc)}, nil
 }
 
 func (s *grpcServer) GetAccounts(ctx context.Context, req *pb.GetAccountsRequest) (*pb.GetAccountsResponse, error) {
-	accounts, err := s.service.GetAccounts(ctx, int(req.Skip), int(req.Take))
+	accounts, err := s.service.GetAccounts(ctx, int(req.Skip), int(req.Take), req.IncludeDeleted)
 	if err != nil {
-		return nil, err
+		return nil, grpcError(err)
 	}
 	resp := make([]*pb.Account, 0, len(accounts))
 	for _, a := range accounts {
-		resp = append(resp, &pb.Account{Id: a.ID, Name: a.Name})
+		resp = append(resp, accountToProto(a))
 	}
 	return &pb.GetAccountsResponse{Accounts: resp}, nil
 }
+
+func (s *grpcServer) UpdateAccount(ctx context.Context, req *pb.UpdateAccountRequest) (*pb.UpdateAccountResponse, error) {
+	acc, err := s.service.UpdateAccount(ctx, req.Id, req.Name)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.UpdateAccountResponse{Account: accountToProto(acc)}, nil
+}
+
+func (s *grpcServer) DeleteAccount(ctx context.Context, req *pb.DeleteAccountRequest) (*pb.DeleteAccountResponse, error) {
+	acc, err := s.service.DeleteAccount(ctx, req.Id)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.DeleteAccountResponse{Account: accountToProto(acc)}, nil
+}
+
+func (s *grpcServer) SetAccountRoles(ctx context.Context, req *pb.SetAccountRolesRequest) (*pb.SetAccountRolesResponse, error) {
+	acc, err := s.service.SetAccountRoles(ctx, req.Id, req.Roles)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.SetAccountRolesResponse{Account: accountToProto(acc)}, nil
+}
+
+func (s *grpcServer) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.RegisterResponse, error) {
+	acc, tokens, err := s.service.Register(ctx, req.Name, req.Email, req.Password)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.RegisterResponse{Account: accountToProto(acc), Tokens: tokensToProto(tokens)}, nil
+}
+
+func (s *grpcServer) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
+	acc, tokens, err := s.service.Login(ctx, req.Email, req.Password)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.LoginResponse{Account: accountToProto(acc), Tokens: tokensToProto(tokens)}, nil
+}
+
+func (s *grpcServer) RefreshToken(ctx context.Context, req *pb.RefreshTokenRequest) (*pb.RefreshTokenResponse, error) {
+	acc, tokens, err := s.service.RefreshToken(ctx, req.RefreshToken)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.RefreshTokenResponse{Account: accountToProto(acc), Tokens: tokensToProto(tokens)}, nil
+}
+
+func (s *grpcServer) CreateAddress(ctx context.Context, req *pb.CreateAddressRequest) (*pb.CreateAddressResponse, error) {
+	if req.Address == nil {
+		return nil, status.Error(codes.InvalidArgument, "address is required")
+	}
+	address, err := s.service.CreateAddress(ctx, req.AccountId, addressFromProto(req.Address))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.CreateAddressResponse{Address: addressToProto(address)}, nil
+}
+
+func (s *grpcServer) GetAddresses(ctx context.Context, req *pb.GetAddressesRequest) (*pb.GetAddressesResponse, error) {
+	addresses, err := s.service.GetAddresses(ctx, req.AccountId)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	resp := make([]*pb.Address, 0, len(addresses))
+	for _, a := range addresses {
+		resp = append(resp, addressToProto(a))
+	}
+	return &pb.GetAddressesResponse{Addresses: resp}, nil
+}
+
+func (s *grpcServer) GetAddress(ctx context.Context, req *pb.GetAddressRequest) (*pb.GetAddressResponse, error) {
+	address, err := s.service.GetAddress(ctx, req.AccountId, req.Id)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.GetAddressResponse{Address: addressToProto(address)}, nil
+}
+
+func (s *grpcServer) UpdateAddress(ctx context.Context, req *pb.UpdateAddressRequest) (*pb.UpdateAddressResponse, error) {
+	if req.Address == nil {
+		return nil, status.Error(codes.InvalidArgument, "address is required")
+	}
+	address, err := s.service.UpdateAddress(ctx, req.AccountId, addressFromProto(req.Address))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.UpdateAddressResponse{Address: addressToProto(address)}, nil
+}
+
+func (s *grpcServer) DeleteAddress(ctx context.Context, req *pb.DeleteAddressRequest) (*pb.DeleteAddressResponse, error) {
+	address, err := s.service.DeleteAddress(ctx, req.AccountId, req.Id)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.DeleteAddressResponse{Address: addressToProto(address)}, nil
+}
+
+// grpcError maps domain errors to gRPC status errors.
+func grpcError(err error) error {
+	switch {
+	case errors.Is(err, ErrNotFound), errors.Is(err, ErrAddressNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, ErrInvalidAccount), errors.Is(err, ErrInvalidAddress):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, ErrEmailTaken):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, ErrTooManyAddresses):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, ErrInvalidCredentials), errors.Is(err, auth.ErrInvalidToken):
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+	return err
+}
+
+func tokensToProto(t *Tokens) *pb.Tokens {
+	tokensProto := &pb.Tokens{AccessToken: t.AccessToken, RefreshToken: t.RefreshToken}
+	tokensProto.AccessTokenExpiresAt, _ = t.AccessExpiresAt.MarshalBinary()
+	return tokensProto
+}
+
+func accountToProto(a *Account) *pb.Account {
+	accountProto := &pb.Account{Id: a.ID, Name: a.Name, Email: a.Email, Roles: a.Roles}
+	if a.DeletedAt != nil {
+		accountProto.DeletedAt, _ = a.DeletedAt.MarshalBinary()
+	}
+	return accountProto
+}
+
+func addressToProto(a *Address) *pb.Address {
+	addressProto := &pb.Address{
+		Id:              a.ID,
+		AccountId:       a.AccountID,
+		Name:            a.Name,
+		Line1:           a.Line1,
+		Line2:           a.Line2,
+		City:            a.City,
+		Region:          a.Region,
+		PostalCode:      a.PostalCode,
+		Country:         a.Country,
+		Phone:           a.Phone,
+		DefaultShipping: a.DefaultShipping,
+		DefaultBilling:  a.DefaultBilling,
+	}
+	addressProto.CreatedAt, _ = a.CreatedAt.MarshalBinary()
+	addressProto.UpdatedAt, _ = a.UpdatedAt.MarshalBinary()
+	return addressProto
+}
+
+func addressFromProto(a *pb.Address) *Address {
+	address := &Address{
+		ID:              a.Id,
+		AccountID:       a.AccountId,
+		Name:            a.Name,
+		Line1:           a.Line1,
+		Line2:           a.Line2,
+		City:            a.City,
+		Region:          a.Region,
+		PostalCode:      a.PostalCode,
+		Country:         a.Country,
+		Phone:           a.Phone,
+		DefaultShipping: a.DefaultShipping,
+		DefaultBilling:  a.DefaultBilling,
+	}
+	if len(a.CreatedAt) > 0 {
+		address.CreatedAt.UnmarshalBinary(a.CreatedAt)
+	}
+	if len(a.UpdatedAt) > 0 {
+		address.UpdatedAt.UnmarshalBinary(a.UpdatedAt)
+	}
+	return address
+}
@@ -2,10 +2,21 @@ package account
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"microservice/auth"
 
 	"github.com/segmentio/ksuid"
 )
 
+var (
+	ErrNotFound       = errors.New("account not found")
+	ErrInvalidAccount = errors.New("invalid account")
+)
+
 
 // protoc --go_out=./pb --go-grpc_out=./pb account.proto
 
@@ -13,46 +24,110 @@ import (
 type Service interface {
 	PostAccount(ctx context.Context, name string) (*Account, error)
 	GetAccount(ctx context.Context, id string) (*Account, error)
-	GetAccounts(ctx context.Context, skip int, take int) ([]*Account, error)
+	GetAccounts(ctx context.Context, skip int, take int, includeDeleted bool) ([]*Account, error)
+	UpdateAccount(ctx context.Context, id string, name string) (*Account, error)
+	DeleteAccount(ctx context.Context, id string) (*Account, error)
+	SetAccountRoles(ctx context.Context, id string, roles []string) (*Account, error)
+	Register(ctx context.Context, name, email, password string) (*Account, *Tokens, error)
+	Login(ctx context.Context, email, password string) (*Account, *Tokens, error)
+	RefreshToken(ctx context.Context, refreshToken string) (*Account, *Tokens, error)
+	CreateAddress(ctx context.Context, accountID string, address *Address) (*Address, error)
+	GetAddresses(ctx context.Context, accountID string) ([]*Address, error)
+	GetAddress(ctx context.Context, accountID, id string) (*Address, error)
+	UpdateAddress(ctx context.Context, accountID string, address *Address) (*Address, error)
+	DeleteAddress(ctx context.Context, accountID, id string) (*Address, error)
 }
 
-// Account domain model.
+// Account domain model. Deleted accounts are only soft-deleted: they keep
+// their ID so that orders placed by them still resolve.
 type Account struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Email     string     `json:"email,omitempty"`
+	Roles     []string   `json:"roles"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// Deleted reports whether the account has been deleted.
+func (a *Account) Deleted() bool {
+	return a.DeletedAt != nil
 }
 
 // accountService implements Service using a Repository.
 type accountService struct {
-	repo Repository
+	repo     Repository
+	signer   *auth.Signer
+	verifier *auth.Verifier
 }
 
-// NewService constructs a Service.
-func NewService(repo Repository) Service {
-	return &accountService{repo: repo}
+// NewService constructs a Service that signs its tokens with signer.
+func NewService(repo Repository, signer *auth.Signer) Service {
+	return &accountService{repo: repo, signer: signer, verifier: signer.Verifier()}
 }
 
 // PostAccount creates and stores a new account.
 func (s *accountService) PostAccount(ctx context.Context, name string) (*Account, error) {
-	account := &Account{ID: ksuid.New().String(), Name: name}
+	account := &Account{ID: ksuid.New().String(), Name: name, Roles: []string{auth.RoleCustomer}}
 	if err := s.repo.PutAccount(ctx, account); err != nil {
 		return nil, err
 	}
 	return account, nil
 }
 
-// GetAccount fetches an account by ID.
+// GetAccount fetches an account by ID, including a deleted one.
 func (s *accountService) GetAccount(ctx context.Context, id string) (*Account, error) {
 	return s.repo.GetAccountById(ctx, id)
 }
 
-// GetAccounts lists accounts with basic bounds on pagination.
-func (s *accountService) GetAccounts(ctx context.Context, skip int, take int) ([]*Account, error) {
+// GetAccounts lists accounts with basic bounds on pagination. Deleted accounts
+// are left out unless includeDeleted is set.
+func (s *accountService) GetAccounts(ctx context.Context, skip int, take int, includeDeleted bool) ([]*Account, error) {
 	if take <= 0 {
 		take = 10
 	}
 	if take > 100 {
 		take = 100
 	}
-	return s.repo.ListsAccounts(ctx, skip, take)
+	return s.repo.ListsAccounts(ctx, skip, take, includeDeleted)
+}
+
+// UpdateAccount renames an account that has not been deleted.
+func (s *accountService) UpdateAccount(ctx context.Context, id string, name string) (*Account, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidAccount)
+	}
+	if err := s.repo.UpdateAccount(ctx, &Account{ID: id, Name: name}); err != nil {
+		return nil, err
+	}
+	return s.repo.GetAccountById(ctx, id)
+}
+
+// DeleteAccount soft-deletes an account. Deleting an account twice fails with
+// ErrNotFound.
+func (s *accountService) DeleteAccount(ctx context.Context, id string) (*Account, error) {
+	if err := s.repo.DeleteAccount(ctx, id, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	return s.repo.GetAccountById(ctx, id)
+}
+
+// SetAccountRoles replaces the roles of an account that has not been deleted.
+// The account's tokens keep their old roles until they are refreshed.
+func (s *accountService) SetAccountRoles(ctx context.Context, id string, roles []string) (*Account, error) {
+	seen := map[string]bool{}
+	unique := []string{}
+	for _, role := range roles {
+		if !auth.ValidRole(role) {
+			return nil, fmt.Errorf("%w: unknown role %q", ErrInvalidAccount, role)
+		}
+		if !seen[role] {
+			seen[role] = true
+			unique = append(unique, role)
+		}
+	}
+	if err := s.repo.SetAccountRoles(ctx, id, unique); err != nil {
+		return nil, err
+	}
+	return s.repo.GetAccountById(ctx, id)
 }
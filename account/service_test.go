@@ -0,0 +1,116 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"microservice/auth"
+)
+
+// memoryRepository keeps accounts and refresh tokens in memory, going by the
+// same rules as the database. Methods the tests do not reach are left to the
+// embedded nil Repository.
+type memoryRepository struct {
+	Repository
+	accounts      map[string]*Account
+	hashes        map[string]string
+	refreshTokens map[string]string
+}
+
+func newMemoryRepository() *memoryRepository {
+	return &memoryRepository{accounts: map[string]*Account{}, hashes: map[string]string{}, refreshTokens: map[string]string{}}
+}
+
+func (r *memoryRepository) GetAccountById(ctx context.Context, id string) (*Account, error) {
+	account, ok := r.accounts[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	copied := *account
+	return &copied, nil
+}
+
+func (r *memoryRepository) UpdateAccount(ctx context.Context, account *Account) error {
+	stored, ok := r.accounts[account.ID]
+	if !ok || stored.Deleted() {
+		return ErrNotFound
+	}
+	stored.Name = account.Name
+	return nil
+}
+
+func (r *memoryRepository) DeleteAccount(ctx context.Context, id string, at time.Time) error {
+	stored, ok := r.accounts[id]
+	if !ok || stored.Deleted() {
+		return ErrNotFound
+	}
+	stored.DeletedAt = &at
+	return nil
+}
+
+func (r *memoryRepository) RegisterAccount(ctx context.Context, account *Account, passwordHash string) error {
+	copied := *account
+	r.accounts[account.ID] = &copied
+	r.hashes[account.Email] = passwordHash
+	return nil
+}
+
+func (r *memoryRepository) GetCredentials(ctx context.Context, email string) (*Account, string, error) {
+	for _, account := range r.accounts {
+		if account.Email == email {
+			copied := *account
+			return &copied, r.hashes[email], nil
+		}
+	}
+	return nil, "", ErrNotFound
+}
+
+func (r *memoryRepository) PutRefreshToken(ctx context.Context, id string, accountID string, expiresAt time.Time) error {
+	r.refreshTokens[id] = accountID
+	return nil
+}
+
+func (r *memoryRepository) UseRefreshToken(ctx context.Context, id string, at time.Time) (string, error) {
+	accountID, ok := r.refreshTokens[id]
+	if !ok {
+		return "", ErrNotFound
+	}
+	delete(r.refreshTokens, id)
+	return accountID, nil
+}
+
+func TestDeleteAccount(t *testing.T) {
+	service := NewService(newMemoryRepository(), auth.NewHMACSigner([]byte("secret")))
+	ctx := context.Background()
+	account, tokens, err := service.Register(ctx, "Ada", "ada@example.com", "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deleted, err := service.DeleteAccount(ctx, account.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !deleted.Deleted() {
+		t.Fatal("account is not marked as deleted")
+	}
+	// The account is kept, so that its orders still resolve
+	if got, err := service.GetAccount(ctx, account.ID); err != nil || !got.Deleted() {
+		t.Errorf("GetAccount = %v, %v; want the deleted account", got, err)
+	}
+
+	if _, _, err := service.Login(ctx, "ada@example.com", "correct horse"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Login error = %v, want %v", err, ErrInvalidCredentials)
+	}
+	if _, _, err := service.RefreshToken(ctx, tokens.RefreshToken); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Errorf("RefreshToken error = %v, want %v", err, auth.ErrInvalidToken)
+	}
+	if _, err := service.UpdateAccount(ctx, account.ID, "Ada L."); !errors.Is(err, ErrNotFound) {
+		t.Errorf("UpdateAccount error = %v, want %v", err, ErrNotFound)
+	}
+	if _, err := service.DeleteAccount(ctx, account.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("deleting again: error = %v, want %v", err, ErrNotFound)
+	}
+}
@@ -1,4 +1,40 @@
-CREATE TABLE accounts IF NOT EXISTS (
+CREATE TABLE IF NOT EXISTS accounts (
     id char(36) PRIMARY KEY,
-    name varchar(255) NOT NULL
-);
\ No newline at end of file
+    name varchar(255) NOT NULL,
+    -- Accounts created with PostAccount have no credentials and cannot log in.
+    email varchar(255) UNIQUE,
+    password_hash varchar(60),
+    roles TEXT[] NOT NULL DEFAULT '{customer}',
+    -- Deleted accounts are kept so that orders still reference a valid account.
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE IF NOT EXISTS account_refresh_tokens (
+    id char(27) PRIMARY KEY,
+    account_id char(36) NOT NULL REFERENCES accounts (id),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Address books. Orders copy the address they ship to, so entries can be
+-- changed and deleted freely.
+CREATE TABLE IF NOT EXISTS account_addresses (
+    id char(27) PRIMARY KEY,
+    account_id char(36) NOT NULL REFERENCES accounts (id),
+    name varchar(255) NOT NULL,
+    line1 varchar(255) NOT NULL,
+    line2 varchar(255) NOT NULL DEFAULT '',
+    city varchar(255) NOT NULL,
+    region varchar(64) NOT NULL DEFAULT '',
+    postal_code varchar(16) NOT NULL DEFAULT '',
+    country char(2) NOT NULL,
+    phone varchar(32) NOT NULL DEFAULT '',
+    default_shipping BOOLEAN NOT NULL DEFAULT FALSE,
+    default_billing BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS account_addresses_account_idx ON account_addresses (account_id, created_at);
+CREATE UNIQUE INDEX IF NOT EXISTS account_addresses_default_shipping_idx ON account_addresses (account_id) WHERE default_shipping;
+CREATE UNIQUE INDEX IF NOT EXISTS account_addresses_default_billing_idx ON account_addresses (account_id) WHERE default_billing;
@@ -0,0 +1,264 @@
+// Package auth issues and verifies the JSON Web Tokens that authenticate
+// accounts. Tokens are signed by the account service and verified offline by
+// the other services, either with a shared HMAC secret or with the Ed25519
+// public keys the account service publishes as a JWKS document.
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+)
+
+// Issuer is the iss claim of every token.
+const Issuer = "microservice/account"
+
+// JWKSPath is where the account service serves its public keys.
+const JWKSPath = "/.well-known/jwks.json"
+
+// TokenType tells access tokens, which authorize requests, apart from refresh
+// tokens, which can only be exchanged for new tokens.
+type TokenType string
+
+const (
+	AccessToken  TokenType = "access"
+	RefreshToken TokenType = "refresh"
+)
+
+// Roles an account can hold. Customers shop, merchandisers manage the catalog
+// and admins can do anything.
+const (
+	RoleCustomer     = "customer"
+	RoleMerchandiser = "merchandiser"
+	RoleAdmin        = "admin"
+)
+
+// ValidRole reports whether role is one of the known roles.
+func ValidRole(role string) bool {
+	switch role {
+	case RoleCustomer, RoleMerchandiser, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+// Claims are the claims carried by a token. The subject is the account ID.
+type Claims struct {
+	jwt.RegisteredClaims
+	Type  TokenType `json:"token_type"`
+	Roles []string  `json:"roles,omitempty"`
+}
+
+// AccountID returns the ID of the account the token was issued to.
+func (c *Claims) AccountID() string {
+	return c.Subject
+}
+
+// Signer signs tokens with either a shared secret or an Ed25519 private key.
+type Signer struct {
+	method jwt.SigningMethod
+	key    interface{}
+	keyID  string
+	public ed25519.PublicKey
+}
+
+// NewHMACSigner signs tokens with HS256 under a secret shared with verifiers.
+func NewHMACSigner(secret []byte) *Signer {
+	return &Signer{method: jwt.SigningMethodHS256, key: secret, keyID: hmacKeyID(secret)}
+}
+
+// NewEd25519Signer signs tokens with EdDSA. Verifiers only need the public key,
+// which JWKS publishes.
+func NewEd25519Signer(key ed25519.PrivateKey) *Signer {
+	public := key.Public().(ed25519.PublicKey)
+	return &Signer{method: jwt.SigningMethodEdDSA, key: key, keyID: ed25519KeyID(public), public: public}
+}
+
+// Sign returns the signed, compact form of claims.
+func (s *Signer) Sign(claims *Claims) (string, error) {
+	token := jwt.NewWithClaims(s.method, claims)
+	token.Header["kid"] = s.keyID
+	return token.SignedString(s.key)
+}
+
+// Verifier returns a Verifier that accepts the tokens the signer signs.
+func (s *Signer) Verifier() *Verifier {
+	if s.public != nil {
+		return &Verifier{keys: map[string]verifyKey{s.keyID: {method: s.method.Alg(), key: s.public}}}
+	}
+	return &Verifier{keys: map[string]verifyKey{s.keyID: {method: s.method.Alg(), key: s.key}}}
+}
+
+// JWKS returns the JSON Web Key Set holding the signer's public key. A signer
+// using a shared secret has nothing to publish and returns an empty set.
+func (s *Signer) JWKS() ([]byte, error) {
+	set := jwkSet{Keys: []jwk{}}
+	if s.public != nil {
+		set.Keys = append(set.Keys, jwk{
+			KeyType: "OKP",
+			Curve:   "Ed25519",
+			X:       base64.RawURLEncoding.EncodeToString(s.public),
+			KeyID:   s.keyID,
+			Alg:     jwt.SigningMethodEdDSA.Alg(),
+			Use:     "sig",
+		})
+	}
+	return json.Marshal(set)
+}
+
+// JWKSHandler serves the signer's JWKS document.
+func (s *Signer) JWKSHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		doc, err := s.JWKS()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/jwk-set+json")
+		w.Header().Set("Cache-Control", "max-age=300")
+		w.Write(doc)
+	})
+}
+
+// Verifier checks tokens offline, without calling the account service.
+type Verifier struct {
+	keys map[string]verifyKey
+}
+
+// verifyKey is a key tokens may be signed with, by key ID. Only tokens signed
+// with a service key may hold RoleService.
+type verifyKey struct {
+	method  string
+	key     interface{}
+	service bool
+}
+
+// NewHMACVerifier verifies tokens signed by NewHMACSigner with the same secret.
+func NewHMACVerifier(secret []byte) *Verifier {
+	return &Verifier{
+		keys: map[string]verifyKey{hmacKeyID(secret): {method: jwt.SigningMethodHS256.Alg(), key: secret}},
+	}
+}
+
+// NewJWKSVerifier verifies tokens against the Ed25519 keys of a JWKS document.
+func NewJWKSVerifier(doc []byte) (*Verifier, error) {
+	var set jwkSet
+	if err := json.Unmarshal(doc, &set); err != nil {
+		return nil, fmt.Errorf("parse JWKS: %w", err)
+	}
+	v := &Verifier{keys: map[string]verifyKey{}}
+	for _, k := range set.Keys {
+		if k.KeyType != "OKP" || k.Curve != "Ed25519" {
+			continue
+		}
+		public, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(public) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("parse JWKS: invalid key %q", k.KeyID)
+		}
+		v.keys[k.KeyID] = verifyKey{method: jwt.SigningMethodEdDSA.Alg(), key: ed25519.PublicKey(public)}
+	}
+	if len(v.keys) == 0 {
+		return nil, errors.New("parse JWKS: no Ed25519 keys")
+	}
+	return v, nil
+}
+
+// FetchJWKSVerifier downloads a JWKS document and verifies tokens against it.
+func FetchJWKSVerifier(client *http.Client, url string) (*Verifier, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch JWKS: %s", resp.Status)
+	}
+	var doc json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("fetch JWKS: %w", err)
+	}
+	return NewJWKSVerifier(doc)
+}
+
+// Verify checks a token's signature, expiry, issuer and type, and returns its
+// claims. Any failure is reported as ErrInvalidToken.
+func (v *Verifier) Verify(token string, typ TokenType) (*Claims, error) {
+	claims := &Claims{}
+	var signedWith verifyKey
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, ok := v.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key %q", kid)
+		}
+		if t.Method.Alg() != key.method {
+			return nil, fmt.Errorf("key %q does not sign with %s", kid, t.Method.Alg())
+		}
+		signedWith = key
+		return key.key, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithIssuer(Issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30*time.Second),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if claims.Type != typ || claims.Subject == "" {
+		return nil, fmt.Errorf("%w: not an %s token", ErrInvalidToken, typ)
+	}
+	if !signedWith.service && slices.Contains(claims.Roles, RoleService) {
+		return nil, fmt.Errorf("%w: %s role from a non-service key", ErrInvalidToken, RoleService)
+	}
+	return claims, nil
+}
+
+type jwkSet struct {
+	Keys []jwk `json:"keys"`
+}
+
+type jwk struct {
+	KeyType string `json:"kty"`
+	Curve   string `json:"crv,omitempty"`
+	X       string `json:"x,omitempty"`
+	KeyID   string `json:"kid"`
+	Alg     string `json:"alg,omitempty"`
+	Use     string `json:"use,omitempty"`
+}
+
+// ed25519KeyID derives a stable key ID from a public key, so that rotated keys
+// get distinct IDs without configuration.
+func ed25519KeyID(public ed25519.PublicKey) string {
+	sum := sha256.Sum256(public)
+	return base64.RawURLEncoding.EncodeToString(sum[:8])
+}
+
+// hmacKeyID derives a key ID that identifies a secret without revealing it.
+func hmacKeyID(secret []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("kid"))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:8])
+}
+
+// ParseEd25519Key decodes a base64 encoded 32 byte Ed25519 seed.
+func ParseEd25519Key(s string) (ed25519.PrivateKey, error) {
+	seed, err := base64.StdEncoding.DecodeString(s)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, errors.New("signing key must be a base64 encoded 32 byte Ed25519 seed")
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
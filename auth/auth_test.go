@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func newClaims(typ TokenType, expiresIn time.Duration, roles ...string) *Claims {
+	now := time.Now()
+	return &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    Issuer,
+			Subject:   "account1",
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(expiresIn)),
+		},
+		Type:  typ,
+		Roles: roles,
+	}
+}
+
+func TestVerify(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ed25519Signer := NewEd25519Signer(key)
+	jwks, err := ed25519Signer.JWKS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwksVerifier, err := NewJWKSVerifier(jwks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hmacSigner := NewHMACSigner([]byte("secret"))
+
+	tests := []struct {
+		name     string
+		signer   *Signer
+		verifier *Verifier
+		claims   *Claims
+		typ      TokenType
+		wantErr  error
+	}{
+		{"hmac", hmacSigner, NewHMACVerifier([]byte("secret")), newClaims(AccessToken, time.Hour, RoleAdmin), AccessToken, nil},
+		{"ed25519 through JWKS", ed25519Signer, jwksVerifier, newClaims(AccessToken, time.Hour, RoleAdmin), AccessToken, nil},
+		{"refresh token", hmacSigner, hmacSigner.Verifier(), newClaims(RefreshToken, time.Hour), RefreshToken, nil},
+		{"refresh token used as access token", hmacSigner, hmacSigner.Verifier(), newClaims(RefreshToken, time.Hour), AccessToken, ErrInvalidToken},
+		{"expired", hmacSigner, hmacSigner.Verifier(), newClaims(AccessToken, -time.Hour), AccessToken, ErrInvalidToken},
+		{"other secret", hmacSigner, NewHMACVerifier([]byte("other")), newClaims(AccessToken, time.Hour), AccessToken, ErrInvalidToken},
+		{"hmac token against JWKS", hmacSigner, jwksVerifier, newClaims(AccessToken, time.Hour), AccessToken, ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := tt.signer.Sign(tt.claims)
+			if err != nil {
+				t.Fatal(err)
+			}
+			claims, err := tt.verifier.Verify(token, tt.typ)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if claims.AccountID() != "account1" || len(claims.Roles) != len(tt.claims.Roles) {
+				t.Errorf("claims = %s %v, want account1 %v", claims.AccountID(), claims.Roles, tt.claims.Roles)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsUnsignedToken(t *testing.T) {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodNone, newClaims(AccessToken, time.Hour, RoleAdmin)).
+		SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewHMACVerifier([]byte("secret")).Verify(token, AccessToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Verify error = %v, want %v", err, ErrInvalidToken)
+	}
+}
@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/tinrab/retry"
+)
+
+// LoadVerifier returns a verifier for the JWKS document at jwksURL if set,
+// else for the shared secret. The JWKS document is fetched once, retrying
+// until the account service serves it.
+func LoadVerifier(secret, jwksURL string) (*Verifier, error) {
+	switch {
+	case jwksURL != "":
+		client := &http.Client{Timeout: 5 * time.Second}
+		var verifier *Verifier
+		retry.ForeverSleep(2*time.Second, func(_ int) (err error) {
+			verifier, err = FetchJWKSVerifier(client, jwksURL)
+			if err != nil {
+				log.Println("retry fetching JWKS ", err)
+			}
+			return
+		})
+		return verifier, nil
+	case secret != "":
+		return NewHMACVerifier([]byte(secret)), nil
+	}
+	return nil, errors.New("either AUTH_JWKS_URL or AUTH_SECRET is required")
+}
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	signer := NewHMACSigner([]byte("secret"))
+	required := map[string]string{"/catalog/PostProduct": RoleMerchandiser}
+	interceptor := UnaryServerInterceptor(signer.Verifier(), required)
+	bearer := func(roles ...string) string {
+		token, err := signer.Sign(newClaims(AccessToken, time.Hour, roles...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return "Bearer " + token
+	}
+
+	tests := []struct {
+		name          string
+		method        string
+		authorization string
+		wantCode      codes.Code
+		wantPrincipal bool
+	}{
+		{"anonymous public call", "/catalog/GetProduct", "", codes.OK, false},
+		{"customer public call", "/catalog/GetProduct", bearer(RoleCustomer), codes.OK, true},
+		{"anonymous", "/catalog/PostProduct", "", codes.Unauthenticated, false},
+		{"customer", "/catalog/PostProduct", bearer(RoleCustomer), codes.PermissionDenied, false},
+		{"merchandiser", "/catalog/PostProduct", bearer(RoleMerchandiser), codes.OK, true},
+		{"admin", "/catalog/PostProduct", bearer(RoleAdmin), codes.OK, true},
+		{"not a bearer token", "/catalog/GetProduct", "Basic abc", codes.Unauthenticated, false},
+		{"invalid token", "/catalog/GetProduct", "Bearer abc", codes.Unauthenticated, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.authorization != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(authorizationKey, tt.authorization))
+			}
+			var principal *Principal
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				principal = FromContext(ctx)
+				return nil, nil
+			}
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("code = %s, want %s", code, tt.wantCode)
+			}
+			if (principal != nil) != tt.wantPrincipal {
+				t.Errorf("principal = %v, want one: %t", principal, tt.wantPrincipal)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package auth
+
+import "context"
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	AccountID string
+	Roles     []string
+}
+
+// PrincipalFromClaims returns the principal a verified access token speaks for.
+func PrincipalFromClaims(c *Claims) *Principal {
+	return &Principal{AccountID: c.AccountID(), Roles: c.Roles}
+}
+
+// HasRole reports whether the principal holds role.
+func (p *Principal) HasRole(role string) bool {
+	if p == nil {
+		return false
+	}
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Can reports whether the principal may do what requires role. Admins can do
+// everything but what only services may do.
+func (p *Principal) Can(role string) bool {
+	return p.HasRole(role) || (p.IsAdmin() && role != RoleService)
+}
+
+// IsAdmin reports whether the principal may act on any account's behalf.
+func (p *Principal) IsAdmin() bool {
+	return p.HasRole(RoleAdmin)
+}
+
+// CanAccessAccount reports whether the principal may read or act on the
+// account: its own, or any if it is an admin.
+func (p *Principal) CanAccessAccount(accountID string) bool {
+	return p != nil && (p.AccountID == accountID || p.IsAdmin())
+}
+
+type principalKey struct{}
+
+type tokenKey struct{}
+
+// NewContext returns a copy of ctx carrying the principal.
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal carried by ctx, or nil for an anonymous
+// caller.
+func FromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
+
+// WithToken returns a copy of ctx carrying the caller's raw access token, so
+// that calls to other services can be made on the caller's behalf.
+func WithToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, tokenKey{}, token)
+}
+
+// TokenFromContext returns the access token carried by ctx, if any.
+func TokenFromContext(ctx context.Context) string {
+	token, _ := ctx.Value(tokenKey{}).(string)
+	return token
+}
@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestServiceTokens(t *testing.T) {
+	accounts := NewHMACSigner([]byte("secret"))
+	services := NewServiceSigner([]byte("service secret"))
+	verifier := accounts.Verifier().WithServiceSecret([]byte("service secret"))
+
+	token, err := services.SignServiceToken("order")
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := verifier.Verify(token, AccessToken)
+	if err != nil {
+		t.Fatalf("service token: %v", err)
+	}
+	if !PrincipalFromClaims(claims).HasRole(RoleService) {
+		t.Errorf("roles = %v, want %s", claims.Roles, RoleService)
+	}
+
+	forged, err := accounts.Sign(newClaims(AccessToken, time.Hour, RoleService))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := verifier.Verify(forged, AccessToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("service role signed by the account key: error = %v, want %v", err, ErrInvalidToken)
+	}
+
+	account, err := accounts.Sign(newClaims(AccessToken, time.Hour, RoleAdmin))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewServiceVerifier([]byte("service secret")).Verify(account, AccessToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("account token against a service verifier: error = %v, want %v", err, ErrInvalidToken)
+	}
+}
+
+func TestServiceOnlyMethods(t *testing.T) {
+	accounts := NewHMACSigner([]byte("secret"))
+	services := NewServiceSigner([]byte("service secret"))
+	verifier := accounts.Verifier().WithServiceSecret([]byte("service secret"))
+	interceptor := UnaryServerInterceptor(verifier, map[string]string{"/catalog/ReserveStock": RoleService})
+
+	admin, err := accounts.Sign(newClaims(AccessToken, time.Hour, RoleAdmin))
+	if err != nil {
+		t.Fatal(err)
+	}
+	service, err := services.SignServiceToken("order")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		token    string
+		wantCode codes.Code
+	}{
+		{"admin", admin, codes.PermissionDenied},
+		{"service", service, codes.OK},
+	}
+	for _, tt := range tests {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(authorizationKey, "Bearer "+tt.token))
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/catalog/ReserveStock"}, handler)
+		if code := status.Code(err); code != tt.wantCode {
+			t.Errorf("%s: code = %s, want %s", tt.name, code, tt.wantCode)
+		}
+	}
+}
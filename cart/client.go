@@ -0,0 +1,136 @@
+package cart
+
+import (
+	"context"
+
+	pb "microservice/cart/pb"
+	"microservice/money"
+	"microservice/order"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+type Client struct {
+	conn    *grpc.ClientConn
+	service pb.CartServiceClient
+}
+
+func NewClient(url string) (*Client, error) {
+	conn, err := grpc.Dial(url, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+	service := pb.NewCartServiceClient(conn)
+	return &Client{conn: conn, service: service}, nil
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Client) GetCart(ctx context.Context, accountID string) (*Cart, error) {
+	resp, err := c.service.GetCart(ctx, &pb.GetCartRequest{AccountId: accountID})
+	if err != nil {
+		return nil, err
+	}
+	return cartFromProto(resp.Cart), nil
+}
+
+// AddItem adds quantity units of a product, or of its variant with sku, to
+// the cart of an account.
+func (c *Client) AddItem(ctx context.Context, accountID, productID, sku string, quantity int) (*Cart, error) {
+	resp, err := c.service.AddCartItem(ctx, &pb.AddCartItemRequest{
+		AccountId: accountID,
+		ProductId: productID,
+		Sku:       sku,
+		Quantity:  uint32(quantity),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cartFromProto(resp.Cart), nil
+}
+
+// UpdateItem sets the quantity of a line of the cart; 0 removes it.
+func (c *Client) UpdateItem(ctx context.Context, accountID, productID, sku string, quantity int) (*Cart, error) {
+	resp, err := c.service.UpdateCartItem(ctx, &pb.UpdateCartItemRequest{
+		AccountId: accountID,
+		ProductId: productID,
+		Sku:       sku,
+		Quantity:  uint32(quantity),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cartFromProto(resp.Cart), nil
+}
+
+func (c *Client) RemoveItem(ctx context.Context, accountID, productID, sku string) (*Cart, error) {
+	resp, err := c.service.RemoveCartItem(ctx, &pb.RemoveCartItemRequest{
+		AccountId: accountID,
+		ProductId: productID,
+		Sku:       sku,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cartFromProto(resp.Cart), nil
+}
+
+func (c *Client) ClearCart(ctx context.Context, accountID string) (*Cart, error) {
+	resp, err := c.service.ClearCart(ctx, &pb.ClearCartRequest{AccountId: accountID})
+	if err != nil {
+		return nil, err
+	}
+	return cartFromProto(resp.Cart), nil
+}
+
+// CheckoutCart orders everything in the cart of an account, applying the
+// coupons and paying with paymentMethod, and returns the order along with what
+// is left in the cart. A non-empty idempotencyKey makes retries return the
+// original order.
+func (c *Client) CheckoutCart(ctx context.Context, accountID string, couponCodes []string, paymentMethod, idempotencyKey string) (*order.Order, *Cart, error) {
+	resp, err := c.service.CheckoutCart(ctx, &pb.CheckoutCartRequest{
+		AccountId:      accountID,
+		IdempotencyKey: idempotencyKey,
+		CouponCodes:    couponCodes,
+		PaymentMethod:  paymentMethod,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return order.FromProto(resp.Order), cartFromProto(resp.Cart), nil
+}
+
+func cartFromProto(c *pb.Cart) *Cart {
+	cart := &Cart{AccountID: c.AccountId, Lines: []*Line{}}
+	if len(c.UpdatedAt) > 0 {
+		cart.UpdatedAt.UnmarshalBinary(c.UpdatedAt)
+	}
+	if c.Subtotal != nil {
+		subtotal := money.FromProto(c.Subtotal)
+		cart.Subtotal = &subtotal
+	}
+	for _, l := range c.Lines {
+		line := &Line{
+			ProductID: l.ProductId,
+			SKU:       l.Sku,
+			Quantity:  int(l.Quantity),
+			Available: l.Available,
+			Name:      l.Name,
+			Options:   l.Options,
+		}
+		line.AddedAt.UnmarshalBinary(l.AddedAt)
+		if l.UnitPrice != nil {
+			price := money.FromProto(l.UnitPrice)
+			line.UnitPrice = &price
+		}
+		if l.LineTotal != nil {
+			total := money.FromProto(l.LineTotal)
+			line.Total = &total
+		}
+		cart.Lines = append(cart.Lines, line)
+	}
+	return cart
+}
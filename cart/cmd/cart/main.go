@@ -0,0 +1,51 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"microservice/cart"
+	"microservice/catalog"
+	"microservice/order"
+
+	githubenv "github.com/kelseyhightower/envconfig"
+	"github.com/tinrab/retry"
+)
+
+type Config struct {
+	DatabaseURL string `envconfig:"DATABASE_URL" required:"true"`
+	CatalogURL  string `envconfig:"CATALOG_SERVICE_URL"`
+	OrderURL    string `envconfig:"ORDER_SERVICE_URL"`
+}
+
+func main() {
+	var cfg Config
+	if err := githubenv.Process("", &cfg); err != nil {
+		log.Fatal(err)
+	}
+
+	var r cart.Repository
+	retry.ForeverSleep(2*time.Second, func(_ int) (err error) {
+		r, err = cart.NewPostgresRepository(cfg.DatabaseURL)
+		if err != nil {
+			log.Println("retry connecting ", err)
+		}
+		return
+	})
+	defer r.Close()
+
+	catalogClient, err := catalog.NewClient(cfg.CatalogURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer catalogClient.Close()
+	orderClient, err := order.NewClient(cfg.OrderURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer orderClient.Close()
+
+	log.Println("Listening on port 8080...")
+	s := cart.NewService(r, catalogClient, orderClient)
+	log.Fatal(cart.ListenGRPC(s, 8080))
+}
@@ -0,0 +1,226 @@
+package cart
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	_ "github.com/lib/pq"
+)
+
+var ErrCheckoutNotFound = errors.New("checkout not found")
+
+type Repository interface {
+	Close() error
+	GetCart(ctx context.Context, accountID string) (*Cart, error)
+	AddLine(ctx context.Context, accountID string, line *Line, maxQuantity int) error
+	SetLineQuantity(ctx context.Context, accountID, productID, sku string, quantity int, at time.Time) error
+	RemoveLine(ctx context.Context, accountID, productID, sku string, at time.Time) error
+	ClearCart(ctx context.Context, accountID string, at time.Time) error
+	GetCheckout(ctx context.Context, accountID, idempotencyKey string) ([]*Line, []string, error)
+	CompleteCheckout(ctx context.Context, accountID, idempotencyKey string, lines []*Line, couponCodes []string, at time.Time) error
+}
+
+type postgresRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresRepository(databaseURL string) (Repository, error) {
+	db, err := sql.Open("postgres", databaseURL)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Ping()
+	if err != nil {
+		return nil, err
+	}
+	return &postgresRepository{db: db}, nil
+}
+
+func (r *postgresRepository) Close() error {
+	return r.db.Close()
+}
+
+// GetCart returns the stored lines of a cart, oldest first. Accounts that
+// never had a cart get an empty one.
+func (r *postgresRepository) GetCart(ctx context.Context, accountID string) (*Cart, error) {
+	cart := &Cart{AccountID: accountID, Lines: []*Line{}}
+	err := r.db.QueryRowContext(ctx, "SELECT updated_at FROM carts WHERE account_id = $1", accountID).Scan(&cart.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return cart, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT product_id, sku, quantity, added_at FROM cart_lines WHERE account_id = $1 ORDER BY added_at, product_id, sku",
+		accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		line := &Line{}
+		if err := rows.Scan(&line.ProductID, &line.SKU, &line.Quantity, &line.AddedAt); err != nil {
+			return nil, err
+		}
+		cart.Lines = append(cart.Lines, line)
+	}
+	return cart, rows.Err()
+}
+
+// AddLine adds a line to a cart, or its quantity to the line already there as
+// long as that stays within maxQuantity.
+func (r *postgresRepository) AddLine(ctx context.Context, accountID string, line *Line, maxQuantity int) error {
+	return r.update(ctx, accountID, line.AddedAt, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO cart_lines (account_id, product_id, sku, quantity, added_at) VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (account_id, product_id, sku) DO UPDATE SET quantity = cart_lines.quantity + EXCLUDED.quantity
+			WHERE cart_lines.quantity + EXCLUDED.quantity <= $6
+			`, accountID, line.ProductID, line.SKU, line.Quantity, line.AddedAt, maxQuantity)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return fmt.Errorf("%w: at most %d units of a product fit in the cart", ErrInvalidCart, maxQuantity)
+		}
+		return nil
+	})
+}
+
+// SetLineQuantity replaces the quantity of a line, failing with
+// ErrLineNotFound if the cart does not have it.
+func (r *postgresRepository) SetLineQuantity(ctx context.Context, accountID, productID, sku string, quantity int, at time.Time) error {
+	return r.update(ctx, accountID, at, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx,
+			"UPDATE cart_lines SET quantity = $4 WHERE account_id = $1 AND product_id = $2 AND sku = $3",
+			accountID, productID, sku, quantity)
+		if err != nil {
+			return err
+		}
+		return expectOneRow(res)
+	})
+}
+
+// RemoveLine deletes a line, failing with ErrLineNotFound if the cart does not
+// have it.
+func (r *postgresRepository) RemoveLine(ctx context.Context, accountID, productID, sku string, at time.Time) error {
+	return r.update(ctx, accountID, at, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx,
+			"DELETE FROM cart_lines WHERE account_id = $1 AND product_id = $2 AND sku = $3",
+			accountID, productID, sku)
+		if err != nil {
+			return err
+		}
+		return expectOneRow(res)
+	})
+}
+
+func (r *postgresRepository) ClearCart(ctx context.Context, accountID string, at time.Time) error {
+	return r.update(ctx, accountID, at, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, "DELETE FROM cart_lines WHERE account_id = $1", accountID)
+		return err
+	})
+}
+
+// GetCheckout returns the lines and coupon codes ordered by the checkout made
+// with idempotencyKey.
+func (r *postgresRepository) GetCheckout(ctx context.Context, accountID, idempotencyKey string) ([]*Line, []string, error) {
+	var data []byte
+	var couponCodes []string
+	err := r.db.QueryRowContext(ctx,
+		"SELECT lines, coupon_codes FROM cart_checkouts WHERE account_id = $1 AND idempotency_key = $2",
+		accountID, idempotencyKey).Scan(&data, pq.Array(&couponCodes))
+	if err == sql.ErrNoRows {
+		return nil, nil, ErrCheckoutNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	var lines []*Line
+	if err := json.Unmarshal(data, &lines); err != nil {
+		return nil, nil, err
+	}
+	return lines, couponCodes, nil
+}
+
+// CompleteCheckout takes the ordered lines out of a cart and, if there is an
+// idempotency key, records them and the coupon codes under it. Units added to
+// a line after it was ordered are left in the cart.
+func (r *postgresRepository) CompleteCheckout(ctx context.Context, accountID, idempotencyKey string, lines []*Line, couponCodes []string, at time.Time) error {
+	return r.update(ctx, accountID, at, func(tx *sql.Tx) error {
+		for _, l := range lines {
+			_, err := tx.ExecContext(ctx,
+				"DELETE FROM cart_lines WHERE account_id = $1 AND product_id = $2 AND sku = $3 AND quantity <= $4",
+				accountID, l.ProductID, l.SKU, l.Quantity)
+			if err != nil {
+				return err
+			}
+			_, err = tx.ExecContext(ctx,
+				"UPDATE cart_lines SET quantity = quantity - $4 WHERE account_id = $1 AND product_id = $2 AND sku = $3",
+				accountID, l.ProductID, l.SKU, l.Quantity)
+			if err != nil {
+				return err
+			}
+		}
+		if idempotencyKey == "" {
+			return nil
+		}
+		ordered := make([]*Line, 0, len(lines))
+		for _, l := range lines {
+			ordered = append(ordered, &Line{ProductID: l.ProductID, SKU: l.SKU, Quantity: l.Quantity, AddedAt: l.AddedAt})
+		}
+		data, err := json.Marshal(ordered)
+		if err != nil {
+			return err
+		}
+		if couponCodes == nil {
+			couponCodes = []string{}
+		}
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO cart_checkouts (account_id, idempotency_key, lines, coupon_codes, created_at) VALUES ($1, $2, $3, $4, $5) ON CONFLICT DO NOTHING",
+			accountID, idempotencyKey, data, pq.Array(couponCodes), at)
+		return err
+	})
+}
+
+// update runs fn in a transaction that also marks the cart as updated at the
+// given time, creating the cart if needed.
+func (r *postgresRepository) update(ctx context.Context, accountID string, at time.Time, fn func(tx *sql.Tx) error) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO carts (account_id, updated_at) VALUES ($1, $2) ON CONFLICT (account_id) DO UPDATE SET updated_at = EXCLUDED.updated_at",
+		accountID, at)
+	if err != nil {
+		return err
+	}
+	return fn(tx)
+}
+
+func expectOneRow(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrLineNotFound
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package cart
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	pb "microservice/cart/pb"
+	"microservice/money"
+	"microservice/order"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// grpcServer implements the generated gRPC CartServiceServer.
+type grpcServer struct {
+	pb.UnimplementedCartServiceServer
+	service Service
+}
+
+// ListenGRPC starts a gRPC server for the Cart service.
+func ListenGRPC(service Service, port int) error {
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return err
+	}
+	s := grpc.NewServer()
+	pb.RegisterCartServiceServer(s, &grpcServer{service: service})
+	reflection.Register(s)
+	return s.Serve(lis)
+}
+
+func (s *grpcServer) GetCart(ctx context.Context, req *pb.GetCartRequest) (*pb.CartResponse, error) {
+	cart, err := s.service.GetCart(ctx, req.AccountId)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.CartResponse{Cart: cartToProto(cart)}, nil
+}
+
+func (s *grpcServer) AddCartItem(ctx context.Context, req *pb.AddCartItemRequest) (*pb.CartResponse, error) {
+	cart, err := s.service.AddItem(ctx, req.AccountId, req.ProductId, req.Sku, int(req.Quantity))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.CartResponse{Cart: cartToProto(cart)}, nil
+}
+
+func (s *grpcServer) UpdateCartItem(ctx context.Context, req *pb.UpdateCartItemRequest) (*pb.CartResponse, error) {
+	cart, err := s.service.UpdateItem(ctx, req.AccountId, req.ProductId, req.Sku, int(req.Quantity))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.CartResponse{Cart: cartToProto(cart)}, nil
+}
+
+func (s *grpcServer) RemoveCartItem(ctx context.Context, req *pb.RemoveCartItemRequest) (*pb.CartResponse, error) {
+	cart, err := s.service.RemoveItem(ctx, req.AccountId, req.ProductId, req.Sku)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.CartResponse{Cart: cartToProto(cart)}, nil
+}
+
+func (s *grpcServer) ClearCart(ctx context.Context, req *pb.ClearCartRequest) (*pb.CartResponse, error) {
+	cart, err := s.service.ClearCart(ctx, req.AccountId)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.CartResponse{Cart: cartToProto(cart)}, nil
+}
+
+func (s *grpcServer) CheckoutCart(ctx context.Context, req *pb.CheckoutCartRequest) (*pb.CheckoutCartResponse, error) {
+	placed, cart, err := s.service.CheckoutCart(ctx, req.AccountId, req.CouponCodes, req.PaymentMethod, req.IdempotencyKey)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.CheckoutCartResponse{Order: order.ToProto(placed), Cart: cartToProto(cart)}, nil
+}
+
+// grpcError maps cart domain errors onto gRPC status codes. Errors of the
+// catalog and order services already carry a status and pass through.
+func grpcError(err error) error {
+	switch {
+	case errors.Is(err, ErrLineNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, ErrInvalidCart):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, ErrEmptyCart), errors.Is(err, ErrUnavailable), errors.Is(err, ErrMixedCurrency):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return err
+}
+
+func cartToProto(c *Cart) *pb.Cart {
+	cartProto := &pb.Cart{AccountId: c.AccountID, Lines: []*pb.CartLine{}}
+	if !c.UpdatedAt.IsZero() {
+		cartProto.UpdatedAt, _ = c.UpdatedAt.MarshalBinary()
+	}
+	if c.Subtotal != nil {
+		cartProto.Subtotal = money.ToProto(*c.Subtotal)
+	}
+	for _, l := range c.Lines {
+		lineProto := &pb.CartLine{
+			ProductId: l.ProductID,
+			Sku:       l.SKU,
+			Quantity:  uint32(l.Quantity),
+			Available: l.Available,
+			Name:      l.Name,
+			Options:   l.Options,
+		}
+		lineProto.AddedAt, _ = l.AddedAt.MarshalBinary()
+		if l.UnitPrice != nil {
+			lineProto.UnitPrice = money.ToProto(*l.UnitPrice)
+		}
+		if l.Total != nil {
+			lineProto.LineTotal = money.ToProto(*l.Total)
+		}
+		cartProto.Lines = append(cartProto.Lines, lineProto)
+	}
+	return cartProto
+}
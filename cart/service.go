@@ -0,0 +1,297 @@
+package cart
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"microservice/catalog"
+	"microservice/money"
+	"microservice/order"
+)
+
+var (
+	ErrInvalidCart   = errors.New("invalid cart")
+	ErrLineNotFound  = errors.New("product is not in the cart")
+	ErrEmptyCart     = errors.New("cart is empty")
+	ErrUnavailable   = errors.New("cart has products that can no longer be ordered")
+	ErrMixedCurrency = errors.New("cart has products priced in different currencies")
+)
+
+// maxQuantity is the most units of a single product or variant a cart holds.
+const maxQuantity = 1000
+
+// Service defines cart business operations. Carts belong to accounts; every
+// account has one, empty until something is added.
+type Service interface {
+	GetCart(ctx context.Context, accountID string) (*Cart, error)
+	AddItem(ctx context.Context, accountID, productID, sku string, quantity int) (*Cart, error)
+	UpdateItem(ctx context.Context, accountID, productID, sku string, quantity int) (*Cart, error)
+	RemoveItem(ctx context.Context, accountID, productID, sku string) (*Cart, error)
+	ClearCart(ctx context.Context, accountID string) (*Cart, error)
+	CheckoutCart(ctx context.Context, accountID string, couponCodes []string, paymentMethod, idempotencyKey string) (*order.Order, *Cart, error)
+}
+
+// Cart is the basket of an account. Only the lines are stored; the rest is
+// priced against the catalog whenever the cart is read.
+type Cart struct {
+	AccountID string  `json:"account_id"`
+	Lines     []*Line `json:"lines"`
+	// Subtotal sums the available lines. It is nil when there are none.
+	Subtotal  *money.Money `json:"subtotal,omitempty"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+// Line is a quantity of a product, or of its variant with SKU, in a cart.
+// Name, Options and prices are filled in from the catalog.
+type Line struct {
+	ProductID string    `json:"product_id"`
+	SKU       string    `json:"sku,omitempty"`
+	Quantity  int       `json:"quantity"`
+	AddedAt   time.Time `json:"added_at"`
+	// Available is false when the product has been archived or deleted, or
+	// the variant removed, since it was added, or when it is now priced in
+	// another currency than the rest of the cart.
+	Available bool              `json:"available"`
+	Name      string            `json:"name,omitempty"`
+	Options   map[string]string `json:"options,omitempty"`
+	UnitPrice *money.Money      `json:"unit_price,omitempty"`
+	Total     *money.Money      `json:"total,omitempty"`
+}
+
+// productCatalog is what carts need of the catalog, and orderPlacer what
+// checkout needs of the order service. Their clients satisfy them.
+type productCatalog interface {
+	GetProduct(ctx context.Context, id string, includeInactive bool) (*catalog.Product, error)
+	GetProducts(ctx context.Context, ids []string, query string, skip int, take int, categoryID string, includeInactive bool) ([]*catalog.Product, error)
+}
+
+type orderPlacer interface {
+	PostOrder(ctx context.Context, accountID string, products []*order.OrderedProduct, couponCodes []string, taxRegion string, shipping *order.Address, paymentMethod, idempotencyKey string) (*order.Order, error)
+}
+
+// cartService implements Service using a Repository, the catalog for prices
+// and the order service for checkout.
+type cartService struct {
+	repo          Repository
+	catalogClient productCatalog
+	orderClient   orderPlacer
+}
+
+// NewService constructs a Service.
+func NewService(repo Repository, catalogClient *catalog.Client, orderClient *order.Client) Service {
+	return &cartService{repo: repo, catalogClient: catalogClient, orderClient: orderClient}
+}
+
+// GetCart returns the priced cart of an account.
+func (s *cartService) GetCart(ctx context.Context, accountID string) (*Cart, error) {
+	if accountID == "" {
+		return nil, fmt.Errorf("%w: account ID is required", ErrInvalidCart)
+	}
+	cart, err := s.repo.GetCart(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.price(ctx, cart); err != nil {
+		return nil, err
+	}
+	return cart, nil
+}
+
+// AddItem adds quantity units of a product, or of its variant with sku, to
+// the cart. The product must be orderable now; stock is only reserved at
+// checkout.
+func (s *cartService) AddItem(ctx context.Context, accountID, productID, sku string, quantity int) (*Cart, error) {
+	if err := validateItem(accountID, productID, quantity); err != nil {
+		return nil, err
+	}
+	product, err := s.catalogClient.GetProduct(ctx, productID, false)
+	if err != nil {
+		return nil, err
+	}
+	variant, err := product.Resolve(sku)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCart, err)
+	}
+	cart, err := s.GetCart(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if price := product.PriceOf(variant); cart.Subtotal != nil && cart.Subtotal.Currency != price.Currency {
+		return nil, fmt.Errorf("%w: the cart is priced in %s", ErrMixedCurrency, cart.Subtotal.Currency)
+	}
+	line := &Line{ProductID: productID, SKU: sku, Quantity: quantity, AddedAt: time.Now().UTC()}
+	if err := s.repo.AddLine(ctx, accountID, line, maxQuantity); err != nil {
+		return nil, err
+	}
+	return s.GetCart(ctx, accountID)
+}
+
+// UpdateItem sets the quantity of a line of the cart. A quantity of 0 removes
+// the line.
+func (s *cartService) UpdateItem(ctx context.Context, accountID, productID, sku string, quantity int) (*Cart, error) {
+	if quantity == 0 {
+		return s.RemoveItem(ctx, accountID, productID, sku)
+	}
+	if err := validateItem(accountID, productID, quantity); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetLineQuantity(ctx, accountID, productID, sku, quantity, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	return s.GetCart(ctx, accountID)
+}
+
+// RemoveItem takes a line out of the cart.
+func (s *cartService) RemoveItem(ctx context.Context, accountID, productID, sku string) (*Cart, error) {
+	if accountID == "" || productID == "" {
+		return nil, fmt.Errorf("%w: account and product IDs are required", ErrInvalidCart)
+	}
+	if err := s.repo.RemoveLine(ctx, accountID, productID, sku, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	return s.GetCart(ctx, accountID)
+}
+
+// ClearCart empties the cart.
+func (s *cartService) ClearCart(ctx context.Context, accountID string) (*Cart, error) {
+	if accountID == "" {
+		return nil, fmt.Errorf("%w: account ID is required", ErrInvalidCart)
+	}
+	if err := s.repo.ClearCart(ctx, accountID, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	return s.GetCart(ctx, accountID)
+}
+
+// CheckoutCart places an order for everything in the cart, applying the
+// coupons and paying with paymentMethod, and takes the ordered lines out of
+// it. The order ships to the account's default shipping address, if it has
+// one. The order service prices the order itself, so the cart's prices are
+// only a preview.
+//
+// Checking out again with the same idempotencyKey replays the lines and
+// coupons ordered the first time, which makes the order service return the
+// original order, and leaves the cart alone.
+func (s *cartService) CheckoutCart(ctx context.Context, accountID string, couponCodes []string, paymentMethod, idempotencyKey string) (*order.Order, *Cart, error) {
+	if accountID == "" {
+		return nil, nil, fmt.Errorf("%w: account ID is required", ErrInvalidCart)
+	}
+	var lines []*Line
+	replay := false
+	if idempotencyKey != "" {
+		checkout, codes, err := s.repo.GetCheckout(ctx, accountID, idempotencyKey)
+		switch {
+		case err == nil:
+			lines, couponCodes, replay = checkout, codes, true
+		case !errors.Is(err, ErrCheckoutNotFound):
+			return nil, nil, err
+		}
+	}
+	if !replay {
+		cart, err := s.GetCart(ctx, accountID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(cart.Lines) == 0 {
+			return nil, nil, ErrEmptyCart
+		}
+		for _, l := range cart.Lines {
+			if !l.Available {
+				return nil, nil, fmt.Errorf("%w: remove product %s", ErrUnavailable, l.ProductID)
+			}
+		}
+		lines = cart.Lines
+	}
+
+	products := make([]*order.OrderedProduct, 0, len(lines))
+	for _, l := range lines {
+		products = append(products, &order.OrderedProduct{ProductID: l.ProductID, SKU: l.SKU, Quantity: l.Quantity})
+	}
+	placed, err := s.orderClient.PostOrder(ctx, accountID, products, couponCodes, "", nil, paymentMethod, idempotencyKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !replay {
+		// Lines added or topped up while the order was placed stay behind
+		if err := s.repo.CompleteCheckout(ctx, accountID, idempotencyKey, lines, couponCodes, time.Now().UTC()); err != nil {
+			return nil, nil, err
+		}
+	}
+	cart, err := s.GetCart(ctx, accountID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return placed, cart, nil
+}
+
+func validateItem(accountID, productID string, quantity int) error {
+	if accountID == "" || productID == "" {
+		return fmt.Errorf("%w: account and product IDs are required", ErrInvalidCart)
+	}
+	if quantity <= 0 || quantity > maxQuantity {
+		return fmt.Errorf("%w: quantity must be between 1 and %d", ErrInvalidCart, maxQuantity)
+	}
+	return nil
+}
+
+// price fills in the lines of a cart from the catalog and sums them up.
+func (s *cartService) price(ctx context.Context, cart *Cart) error {
+	if len(cart.Lines) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(cart.Lines))
+	seen := map[string]bool{}
+	for _, l := range cart.Lines {
+		if !seen[l.ProductID] {
+			seen[l.ProductID] = true
+			ids = append(ids, l.ProductID)
+		}
+	}
+	// Draft and archived products are left out, and so become unavailable
+	products, err := s.catalogClient.GetProducts(ctx, ids, "", 0, len(ids), "", false)
+	if err != nil {
+		return err
+	}
+	byID := make(map[string]*catalog.Product, len(products))
+	for _, p := range products {
+		byID[p.ID] = p
+	}
+
+	for _, l := range cart.Lines {
+		product, ok := byID[l.ProductID]
+		if !ok {
+			continue
+		}
+		variant, err := product.Resolve(l.SKU)
+		if err != nil {
+			continue
+		}
+		price := product.PriceOf(variant)
+		if cart.Subtotal != nil && cart.Subtotal.Currency != price.Currency {
+			continue
+		}
+		total, err := price.Mul(int64(l.Quantity))
+		if err != nil {
+			return err
+		}
+		l.Available = true
+		l.Name = product.Name
+		if variant != nil {
+			l.Options = variant.Options
+		}
+		l.UnitPrice, l.Total = &price, &total
+
+		if cart.Subtotal == nil {
+			subtotal := money.New(0, total.Currency)
+			cart.Subtotal = &subtotal
+		}
+		subtotal, err := cart.Subtotal.Add(total)
+		if err != nil {
+			return err
+		}
+		cart.Subtotal = &subtotal
+	}
+	return nil
+}
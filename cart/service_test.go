@@ -0,0 +1,145 @@
+package cart
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"microservice/catalog"
+	"microservice/money"
+	"microservice/order"
+)
+
+// memoryRepository keeps carts and checkouts in memory. Methods the tests do
+// not reach are left to the embedded nil Repository.
+type memoryRepository struct {
+	Repository
+	lines     map[string][]*Line
+	checkouts map[string][]*Line
+	coupons   map[string][]string
+}
+
+func (r *memoryRepository) GetCart(ctx context.Context, accountID string) (*Cart, error) {
+	cart := &Cart{AccountID: accountID}
+	for _, l := range r.lines[accountID] {
+		line := *l
+		cart.Lines = append(cart.Lines, &line)
+	}
+	return cart, nil
+}
+
+func (r *memoryRepository) GetCheckout(ctx context.Context, accountID, idempotencyKey string) ([]*Line, []string, error) {
+	lines, ok := r.checkouts[accountID+"/"+idempotencyKey]
+	if !ok {
+		return nil, nil, ErrCheckoutNotFound
+	}
+	return lines, r.coupons[accountID+"/"+idempotencyKey], nil
+}
+
+func (r *memoryRepository) CompleteCheckout(ctx context.Context, accountID, idempotencyKey string, lines []*Line, couponCodes []string, at time.Time) error {
+	var left []*Line
+	for _, l := range r.lines[accountID] {
+		for _, ordered := range lines {
+			if ordered.ProductID == l.ProductID && ordered.SKU == l.SKU {
+				l.Quantity -= ordered.Quantity
+			}
+		}
+		if l.Quantity > 0 {
+			left = append(left, l)
+		}
+	}
+	r.lines[accountID] = left
+	if idempotencyKey != "" {
+		r.checkouts[accountID+"/"+idempotencyKey] = lines
+		r.coupons[accountID+"/"+idempotencyKey] = couponCodes
+	}
+	return nil
+}
+
+type fakeCatalog struct {
+	products []*catalog.Product
+}
+
+func (c *fakeCatalog) GetProduct(ctx context.Context, id string, includeInactive bool) (*catalog.Product, error) {
+	for _, p := range c.products {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	return nil, catalog.ErrNotFound
+}
+
+func (c *fakeCatalog) GetProducts(ctx context.Context, ids []string, query string, skip int, take int, categoryID string, includeInactive bool) ([]*catalog.Product, error) {
+	return c.products, nil
+}
+
+// fakeOrders places an order per idempotency key and replays it for the same
+// key, as the order service does.
+type fakeOrders struct {
+	placed map[string]*order.Order
+	calls  []orderCall
+}
+
+type orderCall struct {
+	products    []*order.OrderedProduct
+	couponCodes []string
+}
+
+func (o *fakeOrders) PostOrder(ctx context.Context, accountID string, products []*order.OrderedProduct, couponCodes []string, taxRegion string, shipping *order.Address, paymentMethod, idempotencyKey string) (*order.Order, error) {
+	o.calls = append(o.calls, orderCall{products, couponCodes})
+	if placed, ok := o.placed[idempotencyKey]; ok && idempotencyKey != "" {
+		return placed, nil
+	}
+	placed := &order.Order{ID: "order" + idempotencyKey, AccountID: accountID, Products: products, CouponCodes: couponCodes}
+	o.placed[idempotencyKey] = placed
+	return placed, nil
+}
+
+func TestCheckoutCartReplaysIdempotencyKey(t *testing.T) {
+	repo := &memoryRepository{
+		lines:     map[string][]*Line{"a1": {{ProductID: "p1", Quantity: 2}}},
+		checkouts: map[string][]*Line{},
+		coupons:   map[string][]string{},
+	}
+	orders := &fakeOrders{placed: map[string]*order.Order{}}
+	service := NewService(repo, nil, nil).(*cartService)
+	service.catalogClient = &fakeCatalog{products: []*catalog.Product{
+		{ID: "p1", Name: "Mug", Price: money.New(1250, "USD"), Status: catalog.ProductActive},
+		{ID: "p2", Name: "Cup", Price: money.New(800, "USD"), Status: catalog.ProductActive},
+	}}
+	service.orderClient = orders
+	ctx := context.Background()
+
+	placed, cart, err := service.CheckoutCart(ctx, "a1", []string{"SAVE10"}, "card", "k1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cart.Lines) != 0 {
+		t.Fatalf("cart after checkout has %d lines, want none", len(cart.Lines))
+	}
+
+	// Something is added to the cart before the checkout is retried
+	repo.lines["a1"] = []*Line{{ProductID: "p2", Quantity: 1}}
+	replayed, cart, err := service.CheckoutCart(ctx, "a1", nil, "card", "k1")
+	if err != nil {
+		t.Fatalf("retried checkout: %v", err)
+	}
+	if replayed.ID != placed.ID {
+		t.Errorf("retried checkout placed order %s, want %s", replayed.ID, placed.ID)
+	}
+	want := orderCall{[]*order.OrderedProduct{{ProductID: "p1", Quantity: 2}}, []string{"SAVE10"}}
+	if got := orders.calls[1]; !reflect.DeepEqual(got, want) {
+		t.Errorf("retried checkout ordered %v with %v, want the original lines with SAVE10", got.products, got.couponCodes)
+	}
+	if len(cart.Lines) != 1 || cart.Lines[0].ProductID != "p2" || cart.Lines[0].Quantity != 1 {
+		t.Errorf("cart after the retry = %+v, want the line added since", cart.Lines)
+	}
+
+	// Without a key the same cart is a new checkout
+	repo.lines["a1"] = nil
+	if _, _, err := service.CheckoutCart(ctx, "a1", nil, "card", ""); !errors.Is(err, ErrEmptyCart) {
+		t.Errorf("checkout of an empty cart: error = %v, want %v", err, ErrEmptyCart)
+	}
+}
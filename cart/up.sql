@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS carts (
+    account_id CHAR(27) PRIMARY KEY,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS cart_lines (
+    account_id CHAR(27) REFERENCES carts (account_id) ON DELETE CASCADE,
+    product_id CHAR(27) NOT NULL,
+    -- Empty for products sold without variants.
+    sku VARCHAR(64) NOT NULL DEFAULT '',
+    quantity INT NOT NULL CHECK (quantity > 0),
+    added_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (account_id, product_id, sku)
+);
+
+-- The lines and coupons ordered by each checkout made with an idempotency key,
+-- so that a retry orders the same lines again even though they have left the
+-- cart.
+CREATE TABLE IF NOT EXISTS cart_checkouts (
+    account_id CHAR(27) NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    lines JSONB NOT NULL,
+    coupon_codes TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (account_id, idempotency_key)
+);
@@ -0,0 +1,112 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrProductExists = errors.New("product already exists")
+)
+
+// importBatchSize is how many imported products are written to Elasticsearch
+// in one bulk request.
+const importBatchSize = 500
+
+// maxIDLength is the longest document ID Elasticsearch accepts, in bytes.
+const maxIDLength = 512
+
+// RowError reports why one row of an import was rejected. Rows are numbered
+// from 1 in the order they were sent.
+type RowError struct {
+	Row       int
+	ProductID string
+	Err       error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+}
+
+// ImportProducts creates products in bulk. Each draft is checked as PostProduct
+// checks it and may bring its own ID, which must not be taken, and creation
+// time; both are generated otherwise. Rows that fail are reported and the
+// others are created regardless. firstRow is the number of the first draft,
+// so that an import sent in batches numbers its rows from start to end.
+func (s *CatalogService) ImportProducts(ctx context.Context, drafts []*Product, firstRow int) (int, []*RowError, error) {
+	var tree *categoryTree
+	for _, draft := range drafts {
+		if len(draft.CategoryIDs) > 0 {
+			categories, err := s.repo.ListCategories(ctx)
+			if err != nil {
+				return 0, nil, err
+			}
+			tree = newCategoryTree(categories)
+			break
+		}
+	}
+
+	var rowErrors []*RowError
+	var products []*Product
+	var rows []int
+	for i, draft := range drafts {
+		row := firstRow + i
+		product, err := importedProduct(draft, tree)
+		if err != nil {
+			rowErrors = append(rowErrors, &RowError{Row: row, ProductID: draft.ID, Err: err})
+			continue
+		}
+		products = append(products, product)
+		rows = append(rows, row)
+	}
+	if len(products) == 0 {
+		return 0, rowErrors, nil
+	}
+
+	errs, err := s.repo.BulkPutProducts(ctx, products)
+	if err != nil {
+		return 0, nil, err
+	}
+	imported := 0
+	for i, err := range errs {
+		if err != nil {
+			rowErrors = append(rowErrors, &RowError{Row: rows[i], ProductID: products[i].ID, Err: err})
+			continue
+		}
+		imported++
+	}
+	return imported, rowErrors, nil
+}
+
+func importedProduct(draft *Product, tree *categoryTree) (*Product, error) {
+	var categoryIDs []string
+	if len(draft.CategoryIDs) > 0 {
+		var err error
+		if categoryIDs, err = tree.productCategories(draft.CategoryIDs); err != nil {
+			return nil, err
+		}
+	}
+	product, err := newProduct(draft, categoryIDs)
+	if err != nil {
+		return nil, err
+	}
+	if id := strings.TrimSpace(draft.ID); id != "" {
+		if len(id) > maxIDLength {
+			return nil, fmt.Errorf("%w: ID is longer than %d bytes", ErrInvalidProduct, maxIDLength)
+		}
+		product.ID = id
+	}
+	if !draft.CreatedAt.IsZero() {
+		product.CreatedAt = draft.CreatedAt.UTC()
+	}
+	return product, nil
+}
+
+// ExportProducts calls handle with every product, in no particular order,
+// until handle fails. Draft and archived products are left out unless
+// includeInactive is set.
+func (s *CatalogService) ExportProducts(ctx context.Context, includeInactive bool, handle func(*Product) error) error {
+	return s.repo.ScanProducts(ctx, includeInactive, handle)
+}
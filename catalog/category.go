@@ -0,0 +1,206 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/segmentio/ksuid"
+)
+
+var (
+	ErrCategoryNotFound = errors.New("category not found")
+	ErrInvalidCategory  = errors.New("invalid category")
+	ErrCategoryInUse    = errors.New("category is not empty")
+)
+
+// Category is a node of the catalog taxonomy. Top-level categories have no
+// parent.
+type Category struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	ParentID string `json:"parent_id,omitempty"`
+}
+
+// CreateCategory adds a category under parentID, or at the top level if
+// parentID is empty.
+func (s *CatalogService) CreateCategory(ctx context.Context, name string, parentID string) (*Category, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidCategory)
+	}
+	if parentID != "" {
+		if _, err := s.repo.GetCategoryById(ctx, parentID); err != nil {
+			return nil, parentError(err)
+		}
+	}
+	category := &Category{ID: ksuid.New().String(), Name: name, ParentID: parentID}
+	if err := s.repo.PutCategory(ctx, category); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// MoveCategory moves a category, with everything below it, under parentID or
+// to the top level. A category cannot be moved below itself.
+func (s *CatalogService) MoveCategory(ctx context.Context, id string, parentID string) (*Category, error) {
+	categories, err := s.repo.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tree := newCategoryTree(categories)
+	category, ok := tree.byID[id]
+	if !ok {
+		return nil, ErrCategoryNotFound
+	}
+	if parentID != "" {
+		if _, ok := tree.byID[parentID]; !ok {
+			return nil, fmt.Errorf("%w: parent %q does not exist", ErrInvalidCategory, parentID)
+		}
+		for _, descendant := range tree.descendants(id) {
+			if descendant == parentID {
+				return nil, fmt.Errorf("%w: cannot move a category below itself", ErrInvalidCategory)
+			}
+		}
+	}
+	category.ParentID = parentID
+	if err := s.repo.PutCategory(ctx, category); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// DeleteCategory deletes a category that has no subcategories and no products.
+func (s *CatalogService) DeleteCategory(ctx context.Context, id string) (*Category, error) {
+	categories, err := s.repo.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tree := newCategoryTree(categories)
+	category, ok := tree.byID[id]
+	if !ok {
+		return nil, ErrCategoryNotFound
+	}
+	if len(tree.children[id]) > 0 {
+		return nil, fmt.Errorf("%w: it has subcategories", ErrCategoryInUse)
+	}
+	n, err := s.repo.CountProductsInCategories(ctx, []string{id})
+	if err != nil {
+		return nil, err
+	}
+	if n > 0 {
+		return nil, fmt.Errorf("%w: %d products are assigned to it", ErrCategoryInUse, n)
+	}
+	if err := s.repo.DeleteCategory(ctx, id); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// GetCategories returns the categories with the given IDs, or every category
+// if no IDs are given. Unknown IDs are skipped.
+func (s *CatalogService) GetCategories(ctx context.Context, ids []string) ([]*Category, error) {
+	categories, err := s.repo.ListCategories(ctx)
+	if err != nil || len(ids) == 0 {
+		return categories, err
+	}
+	tree := newCategoryTree(categories)
+	var result []*Category
+	for _, id := range ids {
+		if c, ok := tree.byID[id]; ok {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+// categoryWithDescendants returns the ID of a category followed by the IDs of
+// every category below it, for filtering products by category.
+func (s *CatalogService) categoryWithDescendants(ctx context.Context, id string) ([]string, error) {
+	if id == "" {
+		return nil, nil
+	}
+	categories, err := s.repo.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tree := newCategoryTree(categories)
+	if _, ok := tree.byID[id]; !ok {
+		return nil, ErrCategoryNotFound
+	}
+	return append([]string{id}, tree.descendants(id)...), nil
+}
+
+// validateCategories checks that every category a product is assigned to
+// exists, and drops duplicates.
+func (s *CatalogService) validateCategories(ctx context.Context, ids []string) ([]string, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	categories, err := s.repo.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newCategoryTree(categories).productCategories(ids)
+}
+
+// productCategories checks that every category a product is assigned to
+// exists, and drops duplicates.
+func (t *categoryTree) productCategories(ids []string) ([]string, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	seen := map[string]bool{}
+	var result []string
+	for _, id := range ids {
+		if _, ok := t.byID[id]; !ok {
+			return nil, fmt.Errorf("%w: category %q does not exist", ErrInvalidProduct, id)
+		}
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result, nil
+}
+
+func parentError(err error) error {
+	if errors.Is(err, ErrCategoryNotFound) {
+		return fmt.Errorf("%w: parent does not exist", ErrInvalidCategory)
+	}
+	return err
+}
+
+// categoryTree indexes categories by ID and by parent.
+type categoryTree struct {
+	byID     map[string]*Category
+	children map[string][]string
+}
+
+func newCategoryTree(categories []*Category) *categoryTree {
+	t := &categoryTree{byID: map[string]*Category{}, children: map[string][]string{}}
+	for _, c := range categories {
+		t.byID[c.ID] = c
+		t.children[c.ParentID] = append(t.children[c.ParentID], c.ID)
+	}
+	return t
+}
+
+// descendants returns the IDs of every category below id, breadth first.
+func (t *categoryTree) descendants(id string) []string {
+	var result []string
+	queue := append([]string(nil), t.children[id]...)
+	seen := map[string]bool{id: true}
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		if seen[next] {
+			continue
+		}
+		seen[next] = true
+		result = append(result, next)
+		queue = append(queue, t.children[next]...)
+	}
+	return result
+}
@@ -2,10 +2,15 @@ package catalog
 
 import (
 	"context"
+	"errors"
+	"io"
+	"microservice/auth"
 	pb "microservice/catalog/pb"
+	"microservice/money"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
 type Client struct {
@@ -13,8 +18,27 @@ type Client struct {
 	service pb.CatalogServiceClient
 }
 
+// NewClient returns a client that calls the catalog on behalf of the caller
+// whose token the context carries.
 func NewClient(url string) (*Client, error) {
-	conn, err := grpc.Dial(url, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	return dial(url,
+		grpc.WithUnaryInterceptor(auth.UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(auth.StreamClientInterceptor()),
+	)
+}
+
+// NewServiceClient returns a client that calls the catalog as the named
+// service, with tokens signed by signer. Only service clients may reserve,
+// release and commit stock.
+func NewServiceClient(url string, signer *auth.Signer, name string) (*Client, error) {
+	return dial(url,
+		grpc.WithUnaryInterceptor(auth.ServiceUnaryClientInterceptor(signer, name)),
+		grpc.WithStreamInterceptor(auth.ServiceStreamClientInterceptor(signer, name)),
+	)
+}
+
+func dial(url string, opts ...grpc.DialOption) (*Client, error) {
+	conn, err := grpc.Dial(url, append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, opts...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -26,26 +50,144 @@ func (c *Client) Close() error {
 	return c.conn.Close()
 }
 
-func (c *Client) PostProduct(ctx context.Context, name, description string, price float64) (*Product, error) {
-	req := &pb.PostProductRequest{Name: name, Description: description, Price: price}
-	resp, err := c.service.PostProduct(ctx, req)
+// PostProduct creates a product from the name, description, price, stock,
+// status, tax class, categories, attributes and variants of product.
+func (c *Client) PostProduct(ctx context.Context, product *Product) (*Product, error) {
+	resp, err := c.service.PostProduct(ctx, draftToProto(product))
+	if err != nil {
+		return nil, err
+	}
+	return convertProduct(resp.Product), nil
+}
+
+func draftToProto(product *Product) *pb.PostProductRequest {
+	return &pb.PostProductRequest{
+		Name:        product.Name,
+		Description: product.Description,
+		Price:       money.ToProto(product.Price),
+		Stock:       uint32(product.Stock),
+		Status:      string(product.Status),
+		TaxClass:    product.TaxClass,
+		CategoryIds: product.CategoryIDs,
+		Attributes:  product.Attributes,
+		Variants:    variantsToProto(product.Variants),
+	}
+}
+
+// ImportResult is the outcome of an import.
+type ImportResult struct {
+	Imported int
+	Errors   []*RowError
+}
+
+// ImportProducts streams the products next returns, until it returns io.EOF,
+// to be created in bulk. Products may carry their own ID and creation time.
+// Rows the service rejects are listed in the result rather than failing the
+// import. If next fails the stream is cancelled, but batches the service has
+// already written stay imported.
+func (c *Client) ImportProducts(ctx context.Context, next func() (*Product, error)) (*ImportResult, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.service.ImportProducts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		product, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		req := &pb.ImportProductsRequest{Id: product.ID, Product: draftToProto(product)}
+		if !product.CreatedAt.IsZero() {
+			req.CreatedAt, _ = product.CreatedAt.MarshalBinary()
+		}
+		if err := stream.Send(req); err != nil {
+			// The server ended the stream; CloseAndRecv returns why
+			break
+		}
+	}
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return nil, err
+	}
+	result := &ImportResult{Imported: int(resp.Imported)}
+	for _, e := range resp.Errors {
+		result.Errors = append(result.Errors, &RowError{
+			Row:       int(e.Row),
+			ProductID: e.ProductId,
+			Err:       errors.New(e.Message),
+		})
+	}
+	return result, nil
+}
+
+// ExportProducts calls handle with every product until the export ends or
+// handle fails.
+func (c *Client) ExportProducts(ctx context.Context, includeInactive bool, handle func(*Product) error) error {
+	stream, err := c.service.ExportProducts(ctx, &pb.ExportProductsRequest{IncludeInactive: includeInactive})
+	if err != nil {
+		return err
+	}
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := handle(convertProduct(resp.Product)); err != nil {
+			return err
+		}
+	}
+}
+
+// UpdateProduct changes the fields of a product named by paths (see PathName
+// and friends) to their values in update. A non-zero update.Version makes the
+// update conditional on the product still being at that version; a conflict
+// is an Aborted status with reason ReasonVersionConflict.
+func (c *Client) UpdateProduct(ctx context.Context, id string, update *Product, paths []string) (*Product, error) {
+	req := &pb.UpdateProductRequest{
+		Id:         id,
+		Product:    productToProto(update),
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: paths},
+	}
+	resp, err := c.service.UpdateProduct(ctx, req)
 	if err != nil {
 		return nil, err
 	}
-	return &Product{ID: resp.Product.Id, Name: resp.Product.Name, Description: resp.Product.Description, Price: resp.Product.Price}, nil
+	return convertProduct(resp.Product), nil
 }
 
-func (c *Client) GetProduct(ctx context.Context, id string) (*Product, error) {
-	req := &pb.GetProductRequest{Id: id}
+func (c *Client) DeleteProduct(ctx context.Context, id string) (*Product, error) {
+	resp, err := c.service.DeleteProduct(ctx, &pb.DeleteProductRequest{Id: id})
+	if err != nil {
+		return nil, err
+	}
+	return convertProduct(resp.Product), nil
+}
+
+func (c *Client) GetProduct(ctx context.Context, id string, includeInactive bool) (*Product, error) {
+	req := &pb.GetProductRequest{Id: id, IncludeInactive: includeInactive}
 	resp, err := c.service.GetProduct(ctx, req)
 	if err != nil {
 		return nil, err
 	}
-	return &Product{ID: resp.Product.Id, Name: resp.Product.Name, Description: resp.Product.Description, Price: resp.Product.Price}, nil
+	return convertProduct(resp.Product), nil
 }
 
-func (c *Client) GetProducts(ctx context.Context, ids []string, query string, skip int, take int) ([]*Product, error) {
-	req := &pb.GetProductsRequest{Ids: ids, Query: query, Skip: uint64(skip), Take: uint64(take)}
+func (c *Client) GetProducts(ctx context.Context, ids []string, query string, skip int, take int, categoryID string, includeInactive bool) ([]*Product, error) {
+	req := &pb.GetProductsRequest{
+		Ids:             ids,
+		Query:           query,
+		Skip:            uint64(skip),
+		Take:            uint64(take),
+		CategoryId:      categoryID,
+		IncludeInactive: includeInactive,
+	}
 	resp, err := c.service.GetProducts(ctx, req)
 	if err != nil {
 		return nil, err
@@ -53,10 +195,168 @@ func (c *Client) GetProducts(ctx context.Context, ids []string, query string, sk
 	return convertProducts(resp.Products), nil
 }
 
+// SearchProducts finds a page of products matching search, with facets if
+// search.WithFacets is set.
+func (c *Client) SearchProducts(ctx context.Context, search *ProductSearch) (*SearchResult, error) {
+	req := &pb.GetProductsRequest{
+		Query:           search.Query,
+		CategoryId:      search.CategoryID,
+		Sort:            string(search.Sort),
+		Skip:            uint64(search.Skip),
+		Take:            uint64(search.Take),
+		IncludeInactive: search.IncludeInactive,
+		IncludeFacets:   search.WithFacets,
+	}
+	if search.MinPrice != nil {
+		req.MinPrice = money.ToProto(*search.MinPrice)
+	}
+	if search.MaxPrice != nil {
+		req.MaxPrice = money.ToProto(*search.MaxPrice)
+	}
+	for name, values := range search.Attributes {
+		req.Attributes = append(req.Attributes, &pb.AttributeFilter{Name: name, Values: values})
+	}
+	resp, err := c.service.GetProducts(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &SearchResult{
+		Products: convertProducts(resp.Products),
+		Total:    int64(resp.Total),
+		Facets:   convertFacets(resp.Facets),
+	}, nil
+}
+
+// SuggestProducts completes prefix to up to size active products. A size of
+// zero asks for the service's default.
+func (c *Client) SuggestProducts(ctx context.Context, prefix string, size int) ([]*Product, error) {
+	resp, err := c.service.SuggestProducts(ctx, &pb.SuggestProductsRequest{Prefix: prefix, Size: uint32(size)})
+	if err != nil {
+		return nil, err
+	}
+	return convertProducts(resp.Products), nil
+}
+
+func convertFacets(f *pb.Facets) *Facets {
+	if f == nil {
+		return nil
+	}
+	facets := &Facets{Categories: convertFacetCounts(f.Categories)}
+	for _, a := range f.Attributes {
+		facets.Attributes = append(facets.Attributes, &AttributeFacet{Name: a.Name, Values: convertFacetCounts(a.Values)})
+	}
+	for _, p := range f.Prices {
+		price := &PriceRangeFacet{Count: int64(p.Count)}
+		if p.From != nil {
+			from := money.FromProto(p.From)
+			price.From = &from
+		}
+		if p.To != nil {
+			to := money.FromProto(p.To)
+			price.To = &to
+		}
+		facets.Prices = append(facets.Prices, price)
+	}
+	return facets
+}
+
+func convertFacetCounts(counts []*pb.FacetCount) []*FacetCount {
+	result := make([]*FacetCount, 0, len(counts))
+	for _, c := range counts {
+		result = append(result, &FacetCount{Value: c.Value, Count: int64(c.Count)})
+	}
+	return result
+}
+
 func convertProducts(products []*pb.Product) []*Product {
 	var result []*Product
 	for _, p := range products {
-		result = append(result, &Product{ID: p.Id, Name: p.Name, Description: p.Description, Price: p.Price})
+		result = append(result, convertProduct(p))
 	}
 	return result
 }
+
+func convertProduct(p *pb.Product) *Product {
+	product := &Product{
+		ID:          p.Id,
+		Name:        p.Name,
+		Description: p.Description,
+		Price:       money.FromProto(p.Price),
+		Stock:       int(p.Stock),
+		Reserved:    int(p.Reserved),
+		Status:      ProductStatus(p.Status),
+		TaxClass:    p.TaxClass,
+		Version:     p.Version,
+		CategoryIDs: p.CategoryIds,
+		Attributes:  p.Attributes,
+		Variants:    variantsFromProto(p.Variants),
+	}
+	if len(p.CreatedAt) > 0 {
+		product.CreatedAt.UnmarshalBinary(p.CreatedAt)
+	}
+	return product
+}
+
+func (c *Client) CreateCategory(ctx context.Context, name, parentID string) (*Category, error) {
+	resp, err := c.service.CreateCategory(ctx, &pb.CreateCategoryRequest{Name: name, ParentId: parentID})
+	if err != nil {
+		return nil, err
+	}
+	return convertCategory(resp.Category), nil
+}
+
+func (c *Client) MoveCategory(ctx context.Context, id, parentID string) (*Category, error) {
+	resp, err := c.service.MoveCategory(ctx, &pb.MoveCategoryRequest{Id: id, ParentId: parentID})
+	if err != nil {
+		return nil, err
+	}
+	return convertCategory(resp.Category), nil
+}
+
+func (c *Client) DeleteCategory(ctx context.Context, id string) (*Category, error) {
+	resp, err := c.service.DeleteCategory(ctx, &pb.DeleteCategoryRequest{Id: id})
+	if err != nil {
+		return nil, err
+	}
+	return convertCategory(resp.Category), nil
+}
+
+// GetCategories returns the categories with the given IDs, or all of them if
+// ids is empty.
+func (c *Client) GetCategories(ctx context.Context, ids []string) ([]*Category, error) {
+	resp, err := c.service.GetCategories(ctx, &pb.GetCategoriesRequest{Ids: ids})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*Category, 0, len(resp.Categories))
+	for _, c := range resp.Categories {
+		result = append(result, convertCategory(c))
+	}
+	return result, nil
+}
+
+func convertCategory(c *pb.Category) *Category {
+	return &Category{ID: c.Id, Name: c.Name, ParentID: c.ParentId}
+}
+
+// ReserveStock holds stock for all items under reservationID, or for none of
+// them. A shortage is reported as a FailedPrecondition status carrying an
+// ErrorInfo with reason ReasonInsufficientStock.
+func (c *Client) ReserveStock(ctx context.Context, reservationID string, items []*StockItem) error {
+	req := &pb.ReserveStockRequest{ReservationId: reservationID}
+	for _, item := range items {
+		req.Items = append(req.Items, &pb.StockItem{ProductId: item.ProductID, Sku: item.SKU, Quantity: uint32(item.Quantity)})
+	}
+	_, err := c.service.ReserveStock(ctx, req)
+	return err
+}
+
+func (c *Client) ReleaseStock(ctx context.Context, reservationID string) error {
+	_, err := c.service.ReleaseStock(ctx, &pb.ReleaseStockRequest{ReservationId: reservationID})
+	return err
+}
+
+func (c *Client) CommitStock(ctx context.Context, reservationID string) error {
+	_, err := c.service.CommitStock(ctx, &pb.CommitStockRequest{ReservationId: reservationID})
+	return err
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"log"
+	"microservice/auth"
 	"microservice/catalog"
 	"time"
 
@@ -11,6 +12,10 @@ import (
 
 type Config struct {
 	DatabaseURL string `envconfig:"DATABASE_URL"`
+	AuthSecret  string `envconfig:"AUTH_SECRET"`
+	AuthJWKSURL string `envconfig:"AUTH_JWKS_URL"`
+	// ServiceSecret signs the tokens of the services allowed to move stock.
+	ServiceSecret string `envconfig:"SERVICE_SECRET"`
 }
 
 func main() {
@@ -23,6 +28,14 @@ func main() {
 	// 	panic("DATABASE_URL is required")
 	// }
 
+	verifier, err := auth.LoadVerifier(cfg.AuthSecret, cfg.AuthJWKSURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if cfg.ServiceSecret != "" {
+		verifier = verifier.WithServiceSecret([]byte(cfg.ServiceSecret))
+	}
+
 	var r catalog.Repository
 	retry.ForeverSleep(2*time.Second, func(_ int) (err error) {
 		r, err = catalog.NewElasticRepository(cfg.DatabaseURL)
@@ -34,5 +47,5 @@ func main() {
 	defer r.Close()
 	log.Println("Listening on port 8080...")
 	s := catalog.NewCatalogService(r)
-	log.Fatal(catalog.ListenGRPC(s, 8080))
+	log.Fatal(catalog.ListenGRPC(s, verifier, 8080))
 }
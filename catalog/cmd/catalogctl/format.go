@@ -0,0 +1,373 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"microservice/catalog"
+	"microservice/money"
+)
+
+// Formats catalogctl reads and writes.
+const (
+	formatCSV    = "csv"
+	formatNDJSON = "ndjson"
+)
+
+// record is a product as it appears in a file. Prices are decimal amounts in
+// major units, as people write them. Only NDJSON files carry variants.
+type record struct {
+	ID          string            `json:"id,omitempty"`
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Price       string            `json:"price"`
+	Currency    string            `json:"currency"`
+	Stock       int               `json:"stock"`
+	Status      string            `json:"status,omitempty"`
+	TaxClass    string            `json:"tax_class,omitempty"`
+	CategoryIDs []string          `json:"category_ids,omitempty"`
+	Attributes  map[string]string `json:"attributes,omitempty"`
+	Variants    []*variantRecord  `json:"variants,omitempty"`
+	CreatedAt   *time.Time        `json:"created_at,omitempty"`
+}
+
+// variantRecord is a variant as it appears in a file. An empty price means
+// the product's price.
+type variantRecord struct {
+	SKU     string            `json:"sku"`
+	Options map[string]string `json:"options"`
+	Price   string            `json:"price,omitempty"`
+	Stock   int               `json:"stock"`
+}
+
+func toRecord(p *catalog.Product) *record {
+	rec := &record{
+		ID:          p.ID,
+		Name:        p.Name,
+		Description: p.Description,
+		Price:       p.Price.Decimal(),
+		Currency:    p.Price.Currency,
+		Stock:       p.Stock,
+		Status:      string(p.Status),
+		TaxClass:    p.TaxClass,
+		CategoryIDs: p.CategoryIDs,
+		Attributes:  p.Attributes,
+	}
+	for _, v := range p.Variants {
+		vrec := &variantRecord{SKU: v.SKU, Options: v.Options, Stock: v.Stock}
+		if v.Price != nil {
+			vrec.Price = v.Price.Decimal()
+		}
+		rec.Variants = append(rec.Variants, vrec)
+	}
+	if !p.CreatedAt.IsZero() {
+		createdAt := p.CreatedAt
+		rec.CreatedAt = &createdAt
+	}
+	return rec
+}
+
+func (rec *record) product() (*catalog.Product, error) {
+	price, err := money.Parse(rec.Price, rec.Currency)
+	if err != nil {
+		return nil, err
+	}
+	p := &catalog.Product{
+		ID:          rec.ID,
+		Name:        rec.Name,
+		Description: rec.Description,
+		Price:       price,
+		Stock:       rec.Stock,
+		Status:      catalog.ProductStatus(rec.Status),
+		TaxClass:    rec.TaxClass,
+		CategoryIDs: rec.CategoryIDs,
+		Attributes:  rec.Attributes,
+	}
+	for _, vrec := range rec.Variants {
+		v := &catalog.Variant{SKU: vrec.SKU, Options: vrec.Options, Stock: vrec.Stock}
+		if vrec.Price != "" {
+			price, err := money.Parse(vrec.Price, rec.Currency)
+			if err != nil {
+				return nil, fmt.Errorf("variant %s: %w", vrec.SKU, err)
+			}
+			v.Price = &price
+		}
+		p.Variants = append(p.Variants, v)
+	}
+	if rec.CreatedAt != nil {
+		p.CreatedAt = *rec.CreatedAt
+	}
+	return p, nil
+}
+
+// reader reads products from a file one row at a time. A row that cannot be
+// parsed is returned as a *parseError, after which reading can go on.
+type reader interface {
+	Read() (*catalog.Product, error)
+}
+
+// writer writes products to a file.
+type writer interface {
+	Write(*catalog.Product) error
+	Flush() error
+}
+
+// parseError is a row of the file that could not be read. Rows are numbered
+// from 1, not counting a CSV header.
+type parseError struct {
+	Row int
+	Err error
+}
+
+func (e *parseError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+}
+
+func newReader(format string, r io.Reader) (reader, error) {
+	switch format {
+	case formatCSV:
+		return newCSVReader(r)
+	case formatNDJSON:
+		return &ndjsonReader{scanner: newLineScanner(r)}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+func newWriter(format string, w io.Writer) (writer, error) {
+	switch format {
+	case formatCSV:
+		return newCSVWriter(w)
+	case formatNDJSON:
+		bw := bufio.NewWriter(w)
+		return &ndjsonWriter{w: bw, enc: json.NewEncoder(bw)}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// maxLineLength bounds an NDJSON line, which holds one product.
+const maxLineLength = 1 << 20
+
+func newLineScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineLength)
+	return scanner
+}
+
+type ndjsonReader struct {
+	scanner *bufio.Scanner
+	row     int
+}
+
+func (r *ndjsonReader) Read() (*catalog.Product, error) {
+	for r.scanner.Scan() {
+		line := strings.TrimSpace(r.scanner.Text())
+		if line == "" {
+			continue
+		}
+		r.row++
+		var rec record
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			return nil, &parseError{Row: r.row, Err: err}
+		}
+		p, err := rec.product()
+		if err != nil {
+			return nil, &parseError{Row: r.row, Err: err}
+		}
+		return p, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+type ndjsonWriter struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func (w *ndjsonWriter) Write(p *catalog.Product) error {
+	return w.enc.Encode(toRecord(p))
+}
+
+func (w *ndjsonWriter) Flush() error {
+	return w.w.Flush()
+}
+
+// CSV files have a header row naming their columns, in any order. Lists are
+// joined with listSeparator, and attributes are written as name=value. CSV
+// has no room for variants, so products with variants can only be exported
+// to NDJSON.
+var csvColumns = []string{"id", "name", "description", "price", "currency", "stock", "status", "tax_class", "category_ids", "attributes", "created_at"}
+
+const listSeparator = ";"
+
+type csvReader struct {
+	r       *csv.Reader
+	columns map[string]int
+	row     int
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, errors.New("CSV file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !knownColumn(name) {
+			return nil, fmt.Errorf("unknown CSV column %q", name)
+		}
+		columns[name] = i
+	}
+	for _, name := range []string{"name", "price", "currency"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("CSV header has no %q column", name)
+		}
+	}
+	return &csvReader{r: cr, columns: columns}, nil
+}
+
+func knownColumn(name string) bool {
+	for _, c := range csvColumns {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *csvReader) Read() (*catalog.Product, error) {
+	fields, err := r.r.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	r.row++
+	if err != nil {
+		var csvErr *csv.ParseError
+		if errors.As(err, &csvErr) {
+			return nil, &parseError{Row: r.row, Err: err}
+		}
+		return nil, err
+	}
+	rec, err := r.record(fields)
+	if err != nil {
+		return nil, &parseError{Row: r.row, Err: err}
+	}
+	p, err := rec.product()
+	if err != nil {
+		return nil, &parseError{Row: r.row, Err: err}
+	}
+	return p, nil
+}
+
+func (r *csvReader) record(fields []string) (*record, error) {
+	get := func(name string) string {
+		i, ok := r.columns[name]
+		if !ok || i >= len(fields) {
+			return ""
+		}
+		return strings.TrimSpace(fields[i])
+	}
+	rec := &record{
+		ID:          get("id"),
+		Name:        get("name"),
+		Description: get("description"),
+		Price:       get("price"),
+		Currency:    get("currency"),
+		Status:      get("status"),
+		TaxClass:    get("tax_class"),
+	}
+	if s := get("stock"); s != "" {
+		stock, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid stock %q", s)
+		}
+		rec.Stock = stock
+	}
+	if s := get("category_ids"); s != "" {
+		rec.CategoryIDs = strings.Split(s, listSeparator)
+	}
+	if s := get("attributes"); s != "" {
+		rec.Attributes = map[string]string{}
+		for _, pair := range strings.Split(s, listSeparator) {
+			name, value, ok := strings.Cut(pair, "=")
+			if !ok {
+				return nil, fmt.Errorf("attribute %q is not name=value", pair)
+			}
+			rec.Attributes[name] = value
+		}
+	}
+	if s := get("created_at"); s != "" {
+		createdAt, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid created_at %q", s)
+		}
+		rec.CreatedAt = &createdAt
+	}
+	return rec, nil
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvColumns); err != nil {
+		return nil, err
+	}
+	return &csvWriter{w: cw}, nil
+}
+
+func (w *csvWriter) Write(p *catalog.Product) error {
+	if len(p.Variants) > 0 {
+		return fmt.Errorf("product %s has variants, which CSV cannot hold; export to NDJSON instead", p.ID)
+	}
+	rec := toRecord(p)
+	names := make([]string, 0, len(rec.Attributes))
+	for name := range rec.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	attributes := make([]string, 0, len(names))
+	for _, name := range names {
+		attributes = append(attributes, name+"="+rec.Attributes[name])
+	}
+	var createdAt string
+	if rec.CreatedAt != nil {
+		createdAt = rec.CreatedAt.Format(time.RFC3339)
+	}
+	return w.w.Write([]string{
+		rec.ID,
+		rec.Name,
+		rec.Description,
+		rec.Price,
+		rec.Currency,
+		strconv.Itoa(rec.Stock),
+		rec.Status,
+		rec.TaxClass,
+		strings.Join(rec.CategoryIDs, listSeparator),
+		strings.Join(attributes, listSeparator),
+		createdAt,
+	})
+}
+
+func (w *csvWriter) Flush() error {
+	w.w.Flush()
+	return w.w.Error()
+}
@@ -0,0 +1,196 @@
+// Command catalogctl imports products into the catalog from CSV or NDJSON
+// files and exports them back out, through the catalog service's bulk RPCs.
+//
+//	catalogctl import [-format csv|ndjson] FILE
+//	catalogctl export [-format csv|ndjson] [-include-inactive] [-o FILE]
+//
+// FILE may be "-" for standard input or output. The format defaults to the
+// file's extension (.csv, .ndjson or .jsonl), or to NDJSON. The catalog is at
+// CATALOG_URL, and ACCESS_TOKEN must belong to a merchandiser or an admin.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"microservice/auth"
+	"microservice/catalog"
+
+	githubenv "github.com/kelseyhightower/envconfig"
+)
+
+type Config struct {
+	CatalogURL  string `envconfig:"CATALOG_URL" default:"localhost:8081"`
+	AccessToken string `envconfig:"ACCESS_TOKEN"`
+}
+
+func main() {
+	log.SetFlags(0)
+	var cfg Config
+	if err := githubenv.Process("", &cfg); err != nil {
+		log.Fatal(err)
+	}
+	if len(os.Args) < 2 {
+		usage()
+	}
+	if cfg.AccessToken == "" {
+		log.Fatal("ACCESS_TOKEN is required")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx = auth.WithToken(ctx, cfg.AccessToken)
+
+	client, err := catalog.NewClient(cfg.CatalogURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	switch os.Args[1] {
+	case "import":
+		err = runImport(ctx, client, os.Args[2:])
+	case "export":
+		err = runExport(ctx, client, os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: catalogctl import [-format csv|ndjson] FILE")
+	fmt.Fprintln(os.Stderr, "       catalogctl export [-format csv|ndjson] [-include-inactive] [-o FILE]")
+	os.Exit(2)
+}
+
+// errRowsFailed makes the command exit non-zero when some rows were not
+// imported, after they have been reported.
+var errRowsFailed = errors.New("some rows were not imported")
+
+func runImport(ctx context.Context, client *catalog.Client, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	format := flags.String("format", "", "file format: csv or ndjson")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		usage()
+	}
+	path := flags.Arg(0)
+
+	in := os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	r, err := newReader(formatFor(*format, path), in)
+	if err != nil {
+		return err
+	}
+
+	// The service numbers the rows it was sent, which skips rows that could
+	// not be parsed, so fileRows maps them back to rows of the file.
+	var fileRows []int
+	failed := 0
+	next := func() (*catalog.Product, error) {
+		for {
+			p, err := r.Read()
+			var parseErr *parseError
+			if errors.As(err, &parseErr) {
+				log.Print(parseErr)
+				failed++
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			fileRows = append(fileRows, len(fileRows)+failed+1)
+			return p, nil
+		}
+	}
+	result, err := client.ImportProducts(ctx, next)
+	if err != nil {
+		return err
+	}
+	for _, e := range result.Errors {
+		row := e.Row
+		if row >= 1 && row <= len(fileRows) {
+			row = fileRows[row-1]
+		}
+		if e.ProductID != "" {
+			log.Printf("row %d (%s): %v", row, e.ProductID, e.Err)
+		} else {
+			log.Printf("row %d: %v", row, e.Err)
+		}
+	}
+	failed += len(result.Errors)
+	log.Printf("imported %d products, %d rows failed", result.Imported, failed)
+	if failed > 0 {
+		return errRowsFailed
+	}
+	return nil
+}
+
+func runExport(ctx context.Context, client *catalog.Client, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	format := flags.String("format", "", "file format: csv or ndjson")
+	output := flags.String("o", "-", "file to write, or - for standard output")
+	includeInactive := flags.Bool("include-inactive", false, "also export draft and archived products")
+	flags.Parse(args)
+	if flags.NArg() != 0 {
+		usage()
+	}
+
+	var out io.Writer = os.Stdout
+	if *output != "-" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	w, err := newWriter(formatFor(*format, *output), out)
+	if err != nil {
+		return err
+	}
+	n := 0
+	err = client.ExportProducts(ctx, *includeInactive, func(p *catalog.Product) error {
+		n++
+		return w.Write(p)
+	})
+	if err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	log.Printf("exported %d products", n)
+	return nil
+}
+
+// formatFor returns the format asked for or, failing that, the one the file's
+// extension suggests.
+func formatFor(format, path string) string {
+	if format != "" {
+		return format
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return formatCSV
+	}
+	return formatNDJSON
+}
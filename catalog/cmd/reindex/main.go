@@ -0,0 +1,37 @@
+// Command reindex rebuilds the catalog index with the next index definition
+// and moves the catalog alias to it, while the catalog service keeps running.
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"microservice/catalog"
+
+	githubenv "github.com/kelseyhightower/envconfig"
+)
+
+type Config struct {
+	DatabaseURL string `envconfig:"DATABASE_URL"`
+}
+
+func main() {
+	var cfg Config
+	if err := githubenv.Process("", &cfg); err != nil {
+		log.Fatal(err)
+	}
+	if cfg.DatabaseURL == "" {
+		log.Fatal("DATABASE_URL is required")
+	}
+
+	result, err := catalog.Reindex(context.Background(), cfg.DatabaseURL)
+	if errors.Is(err, catalog.ErrIndexUpToDate) {
+		log.Println(err)
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("copied %d products from %s to %s, %d of them deleted during the copy; the catalog alias now points at %s", result.Copied, result.From, result.To, result.Deleted, result.To)
+	log.Printf("%s is kept for rollback; delete it once %s is known to be good", result.From, result.To)
+}
@@ -0,0 +1,621 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+
+	elastic "gopkg.in/olivere/elastic.v5"
+)
+
+// productAlias is the name every product request uses. It is an alias of the
+// versioned index that holds the products, catalog_v1, catalog_v2 and so on,
+// so that the index can be rebuilt with a new mapping and swapped in without
+// downtime.
+const productAlias = "catalog"
+
+var (
+	ErrIndexUpToDate = errors.New("catalog index is already at the latest definition")
+)
+
+// indexDefinition is one version of the catalog index.
+type indexDefinition struct {
+	version int
+	mapping string
+}
+
+// productIndexes are the versions of the catalog index, oldest first. A
+// mapping change that an existing index does not allow, such as a new
+// analyzer, is made by appending a definition and running the reindex
+// command. Definitions are never edited once released.
+var productIndexes = []indexDefinition{
+	{version: 1, mapping: productMappingV1},
+	{version: 2, mapping: productMappingV2},
+	{version: 3, mapping: productMappingV3},
+	{version: 4, mapping: productMappingV4},
+}
+
+// productMappingV1 keeps string fields in the shape dynamic mapping gave them,
+// text with a keyword subfield, so that queries written against it still work;
+// numbers and dates are typed explicitly so that the first document indexed
+// cannot decide, say, that prices are integers.
+const productMappingV1 = `{
+  "product": {
+    "properties": {
+      "name": {
+        "type": "text",
+        "fields": {"keyword": {"type": "keyword", "ignore_above": 256}}
+      },
+      "name_suggest": {
+        "type": "completion",
+        "analyzer": "simple",
+        "max_input_length": 100
+      },
+      "description": {"type": "text"},
+      "price_units": {"type": "long"},
+      "currency": {
+        "type": "text",
+        "fields": {"keyword": {"type": "keyword", "ignore_above": 256}}
+      },
+      "price_amount": {"type": "double"},
+      "stock": {"type": "integer"},
+      "reserved": {"type": "integer"},
+      "status": {
+        "type": "text",
+        "fields": {"keyword": {"type": "keyword", "ignore_above": 256}}
+      },
+      "category_ids": {
+        "type": "text",
+        "fields": {"keyword": {"type": "keyword", "ignore_above": 256}}
+      },
+      "attributes": {
+        "properties": {
+          "name": {"type": "keyword"},
+          "value": {"type": "keyword"}
+        }
+      },
+      "attribute_values": {
+        "type": "text",
+        "fields": {"keyword": {"type": "keyword", "ignore_above": 256}}
+      },
+      "created_at": {"type": "date"}
+    }
+  }
+}`
+
+// productMappingV2 adds product variants. SKUs are keywords, to be matched
+// exactly.
+const productMappingV2 = `{
+  "product": {
+    "properties": {
+      "name": {
+        "type": "text",
+        "fields": {"keyword": {"type": "keyword", "ignore_above": 256}}
+      },
+      "name_suggest": {
+        "type": "completion",
+        "analyzer": "simple",
+        "max_input_length": 100
+      },
+      "description": {"type": "text"},
+      "price_units": {"type": "long"},
+      "currency": {
+        "type": "text",
+        "fields": {"keyword": {"type": "keyword", "ignore_above": 256}}
+      },
+      "price_amount": {"type": "double"},
+      "stock": {"type": "integer"},
+      "reserved": {"type": "integer"},
+      "status": {
+        "type": "text",
+        "fields": {"keyword": {"type": "keyword", "ignore_above": 256}}
+      },
+      "category_ids": {
+        "type": "text",
+        "fields": {"keyword": {"type": "keyword", "ignore_above": 256}}
+      },
+      "attributes": {
+        "properties": {
+          "name": {"type": "keyword"},
+          "value": {"type": "keyword"}
+        }
+      },
+      "attribute_values": {
+        "type": "text",
+        "fields": {"keyword": {"type": "keyword", "ignore_above": 256}}
+      },
+      "variants": {
+        "properties": {
+          "sku": {"type": "keyword"},
+          "options": {
+            "properties": {
+              "name": {"type": "keyword"},
+              "value": {"type": "keyword"}
+            }
+          },
+          "price_units": {"type": "long"},
+          "stock": {"type": "integer"},
+          "reserved": {"type": "integer"}
+        }
+      },
+      "created_at": {"type": "date"}
+    }
+  }
+}`
+
+// productMappingV3 adds the tax class of products, a keyword since it is only
+// ever matched exactly.
+const productMappingV3 = `{
+  "product": {
+    "properties": {
+      "name": {
+        "type": "text",
+        "fields": {"keyword": {"type": "keyword", "ignore_above": 256}}
+      },
+      "name_suggest": {
+        "type": "completion",
+        "analyzer": "simple",
+        "max_input_length": 100
+      },
+      "description": {"type": "text"},
+      "price_units": {"type": "long"},
+      "currency": {
+        "type": "text",
+        "fields": {"keyword": {"type": "keyword", "ignore_above": 256}}
+      },
+      "price_amount": {"type": "double"},
+      "stock": {"type": "integer"},
+      "reserved": {"type": "integer"},
+      "status": {
+        "type": "text",
+        "fields": {"keyword": {"type": "keyword", "ignore_above": 256}}
+      },
+      "tax_class": {"type": "keyword"},
+      "category_ids": {
+        "type": "text",
+        "fields": {"keyword": {"type": "keyword", "ignore_above": 256}}
+      },
+      "attributes": {
+        "properties": {
+          "name": {"type": "keyword"},
+          "value": {"type": "keyword"}
+        }
+      },
+      "attribute_values": {
+        "type": "text",
+        "fields": {"keyword": {"type": "keyword", "ignore_above": 256}}
+      },
+      "variants": {
+        "properties": {
+          "sku": {"type": "keyword"},
+          "options": {
+            "properties": {
+              "name": {"type": "keyword"},
+              "value": {"type": "keyword"}
+            }
+          },
+          "price_units": {"type": "long"},
+          "stock": {"type": "integer"},
+          "reserved": {"type": "integer"}
+        }
+      },
+      "created_at": {"type": "date"}
+    }
+  }
+}`
+
+// productMappingV4 adds the stock reservations holding a product, which are
+// only ever read by the stock scripts and so are neither indexed nor
+// aggregated, and the product's edit version, which is only ever read back.
+const productMappingV4 = `{
+  "product": {
+    "properties": {
+      "name": {
+        "type": "text",
+        "fields": {"keyword": {"type": "keyword", "ignore_above": 256}}
+      },
+      "name_suggest": {
+        "type": "completion",
+        "analyzer": "simple",
+        "max_input_length": 100
+      },
+      "description": {"type": "text"},
+      "price_units": {"type": "long"},
+      "currency": {
+        "type": "text",
+        "fields": {"keyword": {"type": "keyword", "ignore_above": 256}}
+      },
+      "price_amount": {"type": "double"},
+      "stock": {"type": "integer"},
+      "reserved": {"type": "integer"},
+      "status": {
+        "type": "text",
+        "fields": {"keyword": {"type": "keyword", "ignore_above": 256}}
+      },
+      "tax_class": {"type": "keyword"},
+      "category_ids": {
+        "type": "text",
+        "fields": {"keyword": {"type": "keyword", "ignore_above": 256}}
+      },
+      "attributes": {
+        "properties": {
+          "name": {"type": "keyword"},
+          "value": {"type": "keyword"}
+        }
+      },
+      "attribute_values": {
+        "type": "text",
+        "fields": {"keyword": {"type": "keyword", "ignore_above": 256}}
+      },
+      "variants": {
+        "properties": {
+          "sku": {"type": "keyword"},
+          "options": {
+            "properties": {
+              "name": {"type": "keyword"},
+              "value": {"type": "keyword"}
+            }
+          },
+          "price_units": {"type": "long"},
+          "stock": {"type": "integer"},
+          "reserved": {"type": "integer"}
+        }
+      },
+      "created_at": {"type": "date"},
+      "held_by": {"type": "keyword", "index": false, "doc_values": false},
+      "edit_version": {"type": "long", "index": false, "doc_values": false}
+    }
+  }
+}`
+
+// indexName is the name of a version of the catalog index.
+func indexName(version int) string {
+	return fmt.Sprintf("%s_v%d", productAlias, version)
+}
+
+// indexVersion parses the version out of an index name.
+func indexVersion(name string) (int, bool) {
+	version, err := strconv.Atoi(strings.TrimPrefix(name, productAlias+"_v"))
+	if err != nil || indexName(version) != name {
+		return 0, false
+	}
+	return version, true
+}
+
+func definition(version int) (indexDefinition, bool) {
+	for _, d := range productIndexes {
+		if d.version == version {
+			return d, true
+		}
+	}
+	return indexDefinition{}, false
+}
+
+// ensureIndex makes sure the catalog alias exists. On a new cluster it creates
+// the latest version of the index behind it. A catalog index from before
+// aliases, which has the alias's name, is copied into the latest version and
+// replaced by the alias; writes made to it while it is copied are lost, so the
+// first start after upgrading should happen while the catalog is quiet.
+//
+// An alias pointing at an older version is left alone: moving it to the
+// latest one is the reindex command's job.
+func (r *elasticRepository) ensureIndex(ctx context.Context) error {
+	current, err := r.aliasedIndex(ctx)
+	if err != nil || current != "" {
+		return err
+	}
+	latest := productIndexes[len(productIndexes)-1]
+	legacy, err := r.client.IndexExists(productAlias).Do(ctx)
+	if err != nil {
+		return err
+	}
+	if !legacy {
+		return r.createIndex(ctx, latest, true)
+	}
+	if err := r.createIndex(ctx, latest, false); err != nil {
+		return err
+	}
+	target := indexName(latest.version)
+	if _, err := r.copyIndex(ctx, productAlias, target); err != nil {
+		return err
+	}
+	return r.updateAliases(ctx,
+		map[string]interface{}{"add": map[string]string{"index": target, "alias": productAlias}},
+		map[string]interface{}{"remove_index": map[string]string{"index": productAlias}},
+	)
+}
+
+// ReindexResult describes a completed reindex.
+type ReindexResult struct {
+	// From is the index the alias pointed at before. It is kept, so that the
+	// alias can be pointed back at it, until it is deleted by hand.
+	From string
+	To   string
+	// Copied counts the products copied into the new index.
+	Copied int64
+	// Deleted counts the copied products that were deleted while the copy
+	// ran, and so were removed from the new index again.
+	Deleted int64
+}
+
+// Reindex builds the next version of the catalog index from the one behind
+// the alias and then atomically moves the alias to it. The service keeps
+// reading through the alias throughout, and writing while the bulk of the
+// products is copied. Writes to the old index are then blocked while the
+// new one catches up on the products written and deleted during the copy,
+// and until the alias has moved, so that none are lost; the service fails
+// them with Unavailable meanwhile.
+//
+// Documents keep their versions, so version tokens held by clients stay valid.
+// The exception is a product deleted and created again during the copy, whose
+// document version may go down: it is copied with a new one, which only
+// matters to clients holding a version of it that was never written, as
+// written products go by their edit version.
+func Reindex(ctx context.Context, url string) (_ *ReindexResult, err error) {
+	client, err := newElasticClient(url)
+	if err != nil {
+		return nil, err
+	}
+	r := &elasticRepository{client: client}
+	if err := r.ensureIndex(ctx); err != nil {
+		return nil, err
+	}
+	from, err := r.aliasedIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	version, ok := indexVersion(from)
+	if !ok {
+		return nil, fmt.Errorf("alias %q points at unexpected index %q", productAlias, from)
+	}
+	next, ok := definition(version + 1)
+	if !ok {
+		return nil, fmt.Errorf("%w: v%d", ErrIndexUpToDate, version)
+	}
+	to := indexName(next.version)
+	if err := r.createIndex(ctx, next, false); err != nil {
+		return nil, err
+	}
+	copied, err := r.copyIndex(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.blockWrites(ctx, from, true); err != nil {
+		return nil, err
+	}
+	defer func() {
+		// Once the alias has moved the old index takes writes again too, so
+		// that the alias can be moved back to it
+		if unblockErr := r.blockWrites(context.WithoutCancel(ctx), from, false); unblockErr != nil {
+			err = errors.Join(err, unblockErr)
+		}
+	}()
+	// Catch up on writes that reached the old index during the copy. Only
+	// documents with a higher version than their copy are taken; nothing has
+	// written to the new index, so no write can be at the same version.
+	if _, err := r.copyIndex(ctx, from, to); err != nil {
+		return nil, err
+	}
+	deleted, err := r.reconcileIndex(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	err = r.updateAliases(ctx,
+		map[string]interface{}{"remove": map[string]string{"index": from, "alias": productAlias}},
+		map[string]interface{}{"add": map[string]string{"index": to, "alias": productAlias}},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &ReindexResult{From: from, To: to, Copied: copied, Deleted: deleted}, nil
+}
+
+// aliasedIndex returns the index behind the catalog alias, or "" if there is
+// no alias.
+func (r *elasticRepository) aliasedIndex(ctx context.Context) (string, error) {
+	res, err := r.client.Aliases().Do(ctx)
+	if err != nil {
+		return "", err
+	}
+	indices := res.IndicesByAlias(productAlias)
+	switch len(indices) {
+	case 0:
+		return "", nil
+	case 1:
+		return indices[0], nil
+	}
+	return "", fmt.Errorf("alias %q points at %d indices", productAlias, len(indices))
+}
+
+// createIndex creates a version of the catalog index, with the alias if
+// withAlias is set. Another catalog instance creating the same index at the
+// same time is not an error.
+//
+// The cluster runs Elasticsearch 7 while the client speaks the 5.x API, so
+// the mapping is sent with include_type_name to keep the "product" type every
+// other request names.
+func (r *elasticRepository) createIndex(ctx context.Context, d indexDefinition, withAlias bool) error {
+	body := map[string]interface{}{"mappings": json.RawMessage(d.mapping)}
+	if withAlias {
+		body["aliases"] = map[string]interface{}{productAlias: map[string]interface{}{}}
+	}
+	params := url.Values{"include_type_name": []string{"true"}}
+	_, err := r.client.PerformRequest(ctx, "PUT", "/"+indexName(d.version), params, body)
+	var esErr *elastic.Error
+	if errors.As(err, &esErr) && esErr.Details != nil && esErr.Details.Type == "resource_already_exists_exception" {
+		if withAlias {
+			return nil
+		}
+		return fmt.Errorf("index %s already exists; delete it if it is left over from a failed reindex", indexName(d.version))
+	}
+	if err != nil {
+		return fmt.Errorf("create index %s: %w", indexName(d.version), err)
+	}
+	return nil
+}
+
+// copyIndex copies every product from one index into another, keeping
+// document versions. A document already in the destination at the same or a
+// higher version is left as it is.
+func (r *elasticRepository) copyIndex(ctx context.Context, from, to string) (int64, error) {
+	res, err := r.client.Reindex().
+		Source(elastic.NewReindexSource().Index(from)).
+		Destination(elastic.NewReindexDestination().Index(to).VersionType("external")).
+		ProceedOnVersionConflict().
+		WaitForCompletion(true).
+		Refresh("true").
+		Do(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("copy %s to %s: %w", from, to, err)
+	}
+	if len(res.Failures) > 0 {
+		return 0, fmt.Errorf("copy %s to %s: %d products failed", from, to, len(res.Failures))
+	}
+	return res.Created + res.Updated, nil
+}
+
+// reconcileIndex makes a copy of a write blocked index hold exactly its
+// products: it deletes the copies of products deleted since they were copied,
+// and copies again, with a new version, the products whose versions differ
+// from their copies'. It returns how many products were deleted.
+func (r *elasticRepository) reconcileIndex(ctx context.Context, from, to string) (int64, error) {
+	want, err := r.documentVersions(ctx, from)
+	if err != nil {
+		return 0, err
+	}
+	have, err := r.documentVersions(ctx, to)
+	if err != nil {
+		return 0, err
+	}
+
+	var stale []string
+	for id, version := range want {
+		if have[id] != version {
+			stale = append(stale, id)
+		}
+	}
+	if len(stale) > 0 {
+		// Internal versioning overwrites the copies whatever their version
+		res, err := r.client.Reindex().
+			Source(elastic.NewReindexSource().Index(from).Query(elastic.NewIdsQuery().Ids(stale...))).
+			Destination(elastic.NewReindexDestination().Index(to)).
+			WaitForCompletion(true).
+			Refresh("true").
+			Do(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("copy %d changed products from %s to %s: %w", len(stale), from, to, err)
+		}
+		if len(res.Failures) > 0 {
+			return 0, fmt.Errorf("copy changed products from %s to %s: %d products failed", from, to, len(res.Failures))
+		}
+	}
+
+	bulk := r.client.Bulk().Index(to).Type("product").Refresh("true")
+	for id := range have {
+		if _, ok := want[id]; !ok {
+			bulk.Add(elastic.NewBulkDeleteRequest().Id(id))
+		}
+	}
+	if bulk.NumberOfActions() == 0 {
+		return 0, nil
+	}
+	res, err := bulk.Do(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("delete products deleted from %s from %s: %w", from, to, err)
+	}
+	var deleted int64
+	for _, item := range res.Deleted() {
+		if item.Error != nil {
+			return deleted, fmt.Errorf("delete product %s from %s: %s: %s", item.Id, to, item.Error.Type, item.Error.Reason)
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// documentVersions returns the version of every product in an index by ID.
+func (r *elasticRepository) documentVersions(ctx context.Context, index string) (map[string]int64, error) {
+	versions := map[string]int64{}
+	scroll := r.client.Scroll(index).
+		Type("product").
+		FetchSource(false).
+		Version(true).
+		Size(scanBatchSize)
+	defer scroll.Clear(context.Background())
+	for {
+		res, err := scroll.Do(ctx)
+		if err == io.EOF {
+			return versions, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("list products in %s: %w", index, err)
+		}
+		for _, hit := range res.Hits.Hits {
+			if hit.Version != nil {
+				versions[hit.Id] = *hit.Version
+			}
+		}
+	}
+}
+
+// blockWrites sets or lifts the write block on an index. Reads still work
+// while it is set; writes fail with a cluster_block_exception.
+func (r *elasticRepository) blockWrites(ctx context.Context, index string, block bool) error {
+	body := map[string]interface{}{"index.blocks.write": block}
+	if _, err := r.client.PerformRequest(ctx, "PUT", "/"+index+"/_settings", nil, body); err != nil {
+		return fmt.Errorf("set write block on %s to %t: %w", index, block, err)
+	}
+	return nil
+}
+
+// writeBlocked reports whether a write failed because the index was write
+// blocked by a reindex.
+func writeBlocked(err error) bool {
+	var esErr *elastic.Error
+	return errors.As(err, &esErr) && esErr.Details != nil && esErr.Details.Type == "cluster_block_exception"
+}
+
+// updateAliases applies alias actions in one atomic request.
+func (r *elasticRepository) updateAliases(ctx context.Context, actions ...interface{}) error {
+	body := map[string]interface{}{"actions": actions}
+	if _, err := r.client.PerformRequest(ctx, "POST", "/_aliases", nil, body); err != nil {
+		return fmt.Errorf("update alias %q: %w", productAlias, err)
+	}
+	return nil
+}
+
+// maxSuggestInputs caps how many inputs a name is suggested under.
+const maxSuggestInputs = 10
+
+// suggestDocument is the value of a completion field.
+type suggestDocument struct {
+	Input []string `json:"input"`
+}
+
+// nameSuggestion lists the inputs a product is suggested for: its whole name
+// and the rest of the name from each later word, so that "Trail Running Shoes"
+// is found by typing "run" as well as "trail". Only active products are
+// suggested, so other products get none.
+func nameSuggestion(name string, status ProductStatus) *suggestDocument {
+	if status != ProductActive {
+		return nil
+	}
+	words := strings.Fields(name)
+	if len(words) == 0 {
+		return nil
+	}
+	suggestion := &suggestDocument{}
+	for i := range words {
+		if i == maxSuggestInputs {
+			break
+		}
+		suggestion.Input = append(suggestion.Input, strings.Join(words[i:], " "))
+	}
+	return suggestion
+}
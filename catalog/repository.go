@@ -4,6 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"microservice/money"
+
 	elastic "gopkg.in/olivere/elastic.v5"
 )
 
@@ -14,28 +23,226 @@ var (
 type Repository interface {
 	Close()
 	PutProduct(ctx context.Context, product *Product) error
+	BulkPutProducts(ctx context.Context, products []*Product) ([]error, error)
+	ScanProducts(ctx context.Context, includeInactive bool, handle func(*Product) error) error
+	UpdateProduct(ctx context.Context, product *Product, paths []string) (int64, error)
+	DeleteProduct(ctx context.Context, id string, docVersion int64) error
 	GetProductById(ctx context.Context, id string) (*Product, error)
-	ListsProducts(ctx context.Context, skip int, take int) ([]*Product, error)
-	ListsProductsWithIDs(ctx context.Context, ids []string) ([]*Product, error)
-	SearchProducts(ctx context.Context, query string, skip int, take int) ([]*Product, error)
+	ListsProductsWithIDs(ctx context.Context, ids []string, includeInactive bool) ([]*Product, error)
+	SearchProducts(ctx context.Context, search *ProductSearch, categoryIDs []string) (*SearchResult, error)
+	SuggestProducts(ctx context.Context, prefix string, size int) ([]*Product, error)
+	CountProductsInCategories(ctx context.Context, categoryIDs []string) (int64, error)
+	PutCategory(ctx context.Context, category *Category) error
+	GetCategoryById(ctx context.Context, id string) (*Category, error)
+	ListCategories(ctx context.Context) ([]*Category, error)
+	DeleteCategory(ctx context.Context, id string) error
+	ReserveStock(ctx context.Context, reservationID string, items []*StockItem) error
+	ReleaseStock(ctx context.Context, reservationID string) error
+	CommitStock(ctx context.Context, reservationID string) error
 }
 
 type elasticRepository struct {
 	client *elastic.Client
 }
 
+// ProductDocument stores the price as integer minor units plus a currency code
+// so that it round-trips through Elasticsearch exactly. PriceAmount repeats
+// the price in major units for range filters, sorting and facets only.
 type ProductDocument struct {
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	PriceUnits  int64         `json:"price_units"`
+	Currency    string        `json:"currency"`
+	PriceAmount float64       `json:"price_amount"`
+	Stock       int           `json:"stock"`
+	Reserved    int           `json:"reserved"`
+	Status      ProductStatus `json:"status,omitempty"`
+	TaxClass    string        `json:"tax_class,omitempty"`
+	CategoryIDs []string      `json:"category_ids,omitempty"`
+	// Attributes are kept as name/value pairs rather than an object so that
+	// every attribute name does not become a field of the index mapping.
+	// AttributeValues holds each pair as "name=value" for exact filtering.
+	Attributes      []attributeDocument `json:"attributes,omitempty"`
+	AttributeValues []string            `json:"attribute_values,omitempty"`
+	Variants        []variantDocument   `json:"variants,omitempty"`
+	CreatedAt       *time.Time          `json:"created_at,omitempty"`
+	// NameSuggest feeds autocomplete. It is derived from the name and status
+	// and never read back.
+	NameSuggest *suggestDocument `json:"name_suggest,omitempty"`
+	// EditVersion is the product's version as clients see it. Only product
+	// writes move it, not the stock scripts, which bump the document version
+	// on every reservation. Documents from before it existed go by their
+	// document version until they are first written.
+	EditVersion int64 `json:"edit_version,omitempty"`
+}
+
+type attributeDocument struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// variantDocument is a variant as stored in its product's document. Its price,
+// if it has one, is in the product's currency.
+type variantDocument struct {
+	SKU        string              `json:"sku"`
+	Options    []attributeDocument `json:"options"`
+	PriceUnits *int64              `json:"price_units,omitempty"`
+	Stock      int                 `json:"stock"`
+	Reserved   int                 `json:"reserved"`
+}
+
+// attributeSeparator joins an attribute's name and value in AttributeValues.
+const attributeSeparator = "="
+
+// categoryDocument is a category as stored in Elasticsearch.
+type categoryDocument struct {
+	Name     string `json:"name"`
+	ParentID string `json:"parent_id,omitempty"`
 }
 
+// maxCategories caps how many categories are read at once. The taxonomy is
+// loaded whole to resolve subtrees, so it is expected to stay small.
+const maxCategories = 10000
+
+func productDocument(p *Product) ProductDocument {
+	doc := ProductDocument{
+		Name:        p.Name,
+		Description: p.Description,
+		PriceUnits:  p.Price.Units,
+		Currency:    p.Price.Currency,
+		PriceAmount: priceAmount(p.Price),
+		Stock:       p.Stock,
+		Reserved:    p.Reserved,
+		Status:      p.Status,
+		TaxClass:    p.TaxClass,
+		CategoryIDs: p.CategoryIDs,
+		NameSuggest: nameSuggestion(p.Name, p.Status),
+		EditVersion: p.Version,
+	}
+	doc.Attributes = attributeDocuments(p.Attributes)
+	for _, a := range doc.Attributes {
+		doc.AttributeValues = append(doc.AttributeValues, a.Name+attributeSeparator+a.Value)
+	}
+	for _, v := range p.Variants {
+		vdoc := variantDocument{
+			SKU:      v.SKU,
+			Options:  attributeDocuments(v.Options),
+			Stock:    v.Stock,
+			Reserved: v.Reserved,
+		}
+		if v.Price != nil {
+			units := v.Price.Units
+			vdoc.PriceUnits = &units
+		}
+		doc.Variants = append(doc.Variants, vdoc)
+	}
+	if !p.CreatedAt.IsZero() {
+		createdAt := p.CreatedAt
+		doc.CreatedAt = &createdAt
+	}
+	return doc
+}
+
+// attributeDocuments turns attributes into name/value pairs sorted by name.
+func attributeDocuments(attributes map[string]string) []attributeDocument {
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var docs []attributeDocument
+	for _, name := range names {
+		docs = append(docs, attributeDocument{Name: name, Value: attributes[name]})
+	}
+	return docs
+}
+
+// attributeMap turns name/value pairs back into attributes.
+func attributeMap(docs []attributeDocument) map[string]string {
+	if len(docs) == 0 {
+		return nil
+	}
+	attributes := make(map[string]string, len(docs))
+	for _, a := range docs {
+		attributes[a.Name] = a.Value
+	}
+	return attributes
+}
+
+// priceAmount converts a price to major units, e.g. 1999 USD cents to 19.99.
+func priceAmount(m money.Money) float64 {
+	return float64(m.Units) / math.Pow10(money.Exponent(m.Currency))
+}
+
+func (doc *ProductDocument) product(id string, version *int64) *Product {
+	status := doc.Status
+	if status == "" {
+		// Indexed before products had a status, when everything was for sale
+		status = ProductActive
+	}
+	product := &Product{
+		ID:          id,
+		Name:        doc.Name,
+		Description: doc.Description,
+		Price:       money.New(doc.PriceUnits, doc.Currency),
+		Stock:       doc.Stock,
+		Reserved:    doc.Reserved,
+		Status:      status,
+		TaxClass:    doc.TaxClass,
+		CategoryIDs: doc.CategoryIDs,
+	}
+	product.Attributes = attributeMap(doc.Attributes)
+	for _, vdoc := range doc.Variants {
+		v := &Variant{
+			SKU:      vdoc.SKU,
+			Options:  attributeMap(vdoc.Options),
+			Stock:    vdoc.Stock,
+			Reserved: vdoc.Reserved,
+		}
+		if vdoc.PriceUnits != nil {
+			price := money.New(*vdoc.PriceUnits, doc.Currency)
+			v.Price = &price
+		}
+		product.Variants = append(product.Variants, v)
+	}
+	if doc.CreatedAt != nil {
+		product.CreatedAt = *doc.CreatedAt
+	}
+	if version != nil {
+		product.Version = *version
+		product.docVersion = *version
+	}
+	if doc.EditVersion != 0 {
+		product.Version = doc.EditVersion
+	}
+	return product
+}
+
+// reservationDocument is a stock reservation as stored in Elasticsearch.
+// Tracked is set on reservations whose holds are recorded on the products
+// they hold; older reservations are closed without checking.
+type reservationDocument struct {
+	State   ReservationState `json:"state"`
+	Items   []*StockItem     `json:"items"`
+	Tracked bool             `json:"tracked,omitempty"`
+}
+
+// NewElasticRepository connects to Elasticsearch and makes sure the catalog
+// index and its alias exist.
 func NewElasticRepository(url string) (Repository, error) {
-	client, err := elastic.NewClient(elastic.SetURL(url), elastic.SetSniff(false))
+	client, err := newElasticClient(url)
 	if err != nil {
 		return nil, err
 	}
-	return &elasticRepository{client: client}, nil
+	r := &elasticRepository{client: client}
+	if err := r.ensureIndex(context.Background()); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func newElasticClient(url string) (*elastic.Client, error) {
+	return elastic.NewClient(elastic.SetURL(url), elastic.SetSniff(false))
 }
 
 func (r *elasticRepository) Close() {
@@ -43,23 +250,161 @@ func (r *elasticRepository) Close() {
 }
 
 func (r *elasticRepository) PutProduct(ctx context.Context, product *Product) error {
-	doc := ProductDocument{
-		Name:        product.Name,
-		Description: product.Description,
-		Price:       product.Price,
+	product.Version = 1
+	res, err := r.client.Index().
+		Index(productAlias).
+		Type("product").
+		Id(product.ID).
+		BodyJson(productDocument(product)).
+		Do(ctx)
+	if err != nil {
+		return err
 	}
-	_, err := r.client.Index().
-		Index("catalog").
+	product.docVersion = res.Version
+	return nil
+}
+
+// BulkPutProducts creates products in one bulk request and returns, in order,
+// the error for each product, or nil for those created. A product whose ID is
+// taken is not overwritten but fails with ErrProductExists.
+func (r *elasticRepository) BulkPutProducts(ctx context.Context, products []*Product) ([]error, error) {
+	bulk := r.client.Bulk()
+	for _, p := range products {
+		p.Version = 1
+		bulk = bulk.Add(elastic.NewBulkIndexRequest().
+			Index(productAlias).
+			Type("product").
+			Id(p.ID).
+			OpType("create").
+			Doc(productDocument(p)))
+	}
+	res, err := bulk.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Items) != len(products) {
+		return nil, fmt.Errorf("bulk request returned %d results for %d products", len(res.Items), len(products))
+	}
+	errs := make([]error, len(products))
+	for i, item := range res.Items {
+		for _, result := range item {
+			switch {
+			case result.Status == 409:
+				errs[i] = ErrProductExists
+			case result.Error != nil:
+				errs[i] = fmt.Errorf("%s: %s", result.Error.Type, result.Error.Reason)
+			default:
+				products[i].docVersion = result.Version
+			}
+		}
+	}
+	return errs, nil
+}
+
+// scanBatchSize is how many products each page of a scan reads.
+const scanBatchSize = 500
+
+// ScanProducts calls handle with every product until handle fails, reading
+// them a page at a time through a scroll.
+func (r *elasticRepository) ScanProducts(ctx context.Context, includeInactive bool, handle func(*Product) error) error {
+	scroll := r.client.Scroll(productAlias).
+		Type("product").
+		Query(visible(elastic.NewMatchAllQuery(), includeInactive)).
+		Version(true).
+		Size(scanBatchSize)
+	defer scroll.Clear(context.Background())
+	for {
+		res, err := scroll.Do(ctx)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, p := range r.convertSearchResults(res) {
+			if err := handle(p); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// UpdateProduct writes the fields of product named by paths as a partial
+// document and moves the product to its next version, which it returns. The
+// write fails with ErrVersionConflict unless the document is still as product
+// was read, stock included.
+func (r *elasticRepository) UpdateProduct(ctx context.Context, product *Product, paths []string) (int64, error) {
+	doc := productDocument(product)
+	fields := map[string]interface{}{}
+	for _, path := range paths {
+		switch path {
+		case PathName:
+			fields["name"] = doc.Name
+			fields["name_suggest"] = doc.NameSuggest
+		case PathDescription:
+			fields["description"] = doc.Description
+		case PathPrice:
+			fields["price_units"] = doc.PriceUnits
+			fields["currency"] = doc.Currency
+			fields["price_amount"] = doc.PriceAmount
+		case PathStock:
+			fields["stock"] = doc.Stock
+		case PathStatus:
+			fields["status"] = doc.Status
+			fields["name_suggest"] = doc.NameSuggest
+		case PathTaxClass:
+			fields["tax_class"] = doc.TaxClass
+		case PathCategories:
+			// An empty list rather than nil, which a partial update would ignore
+			fields["category_ids"] = append([]string{}, doc.CategoryIDs...)
+		case PathAttributes:
+			fields["attributes"] = append([]attributeDocument{}, doc.Attributes...)
+			fields["attribute_values"] = append([]string{}, doc.AttributeValues...)
+		case PathVariants:
+			fields["variants"] = append([]variantDocument{}, doc.Variants...)
+		}
+	}
+	fields["edit_version"] = product.Version + 1
+	_, err := r.client.Update().
+		Index(productAlias).
 		Type("product").
 		Id(product.ID).
-		BodyJson(doc).
+		Version(product.docVersion).
+		Doc(fields).
+		Do(ctx)
+	if err != nil {
+		return 0, productWriteError(err)
+	}
+	return product.Version + 1, nil
+}
+
+// DeleteProduct deletes a product, provided its document is still at
+// docVersion, the document version it was read at.
+func (r *elasticRepository) DeleteProduct(ctx context.Context, id string, docVersion int64) error {
+	_, err := r.client.Delete().
+		Index(productAlias).
+		Type("product").
+		Id(id).
+		Version(docVersion).
 		Do(ctx)
+	return productWriteError(err)
+}
+
+func productWriteError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case elastic.IsNotFound(err):
+		return ErrNotFound
+	case elastic.IsConflict(err):
+		return ErrVersionConflict
+	}
 	return err
 }
 
 func (r *elasticRepository) GetProductById(ctx context.Context, id string) (*Product, error) {
 	res, err := r.client.Get().
-		Index("catalog").
+		Index(productAlias).
 		Type("product").
 		Id(id).
 		Do(ctx)
@@ -77,20 +422,45 @@ func (r *elasticRepository) GetProductById(ctx context.Context, id string) (*Pro
 	if err != nil {
 		return nil, err
 	}
-	return &Product{
-		ID:          res.Id,
-		Name:        doc.Name,
-		Description: doc.Description,
-		Price:       doc.Price,
-	}, nil
+	return doc.product(res.Id, res.Version), nil
+}
+
+// visible restricts query to active products unless includeInactive is set.
+// Products without a status predate statuses and count as active.
+func visible(query elastic.Query, includeInactive bool) elastic.Query {
+	if includeInactive {
+		return query
+	}
+	return elastic.NewBoolQuery().
+		Must(query).
+		MustNot(elastic.NewTermsQuery("status", string(ProductDraft), string(ProductArchived)))
+}
+
+// inCategories restricts query to products assigned to any of categoryIDs, if
+// there are any.
+func inCategories(query elastic.Query, categoryIDs []string) elastic.Query {
+	if len(categoryIDs) == 0 {
+		return query
+	}
+	return elastic.NewBoolQuery().Must(query).Filter(categoriesQuery(categoryIDs))
+}
+
+func categoriesQuery(categoryIDs []string) elastic.Query {
+	ids := make([]interface{}, 0, len(categoryIDs))
+	for _, id := range categoryIDs {
+		ids = append(ids, id)
+	}
+	// IDs are matched exactly, not through the analyzed text field
+	return elastic.NewTermsQuery("category_ids.keyword", ids...)
 }
 
-func (r *elasticRepository) ListsProducts(ctx context.Context, skip int, take int) ([]*Product, error) {
-	query := elastic.NewMatchAllQuery()
+func (r *elasticRepository) ListsProductsWithIDs(ctx context.Context, ids []string, includeInactive bool) ([]*Product, error) {
+	query := visible(elastic.NewIdsQuery().Ids(ids...), includeInactive)
 	searchResult, err := r.client.Search().
-		Index("catalog").
+		Index(productAlias).
 		Query(query).
-		From(skip).Size(take).
+		Version(true).
+		Size(len(ids)).
 		Do(ctx)
 	if err != nil {
 		return nil, err
@@ -98,31 +468,278 @@ func (r *elasticRepository) ListsProducts(ctx context.Context, skip int, take in
 	return r.convertSearchResults(searchResult), nil
 }
 
-func (r *elasticRepository) ListsProductsWithIDs(ctx context.Context, ids []string) ([]*Product, error) {
-	query := elastic.NewIdsQuery().Ids(ids...)
-	searchResult, err := r.client.Search().
-		Index("catalog").
+// Facet aggregation names, and how many buckets the term facets return.
+const (
+	categoriesFacet = "categories"
+	attributesFacet = "attributes"
+	currenciesFacet = "currencies"
+	pricesFacet     = "prices"
+
+	maxFacetValues = 50
+)
+
+// priceRangeBounds are the bounds of the price facet's ranges, in major units
+// of each currency.
+var priceRangeBounds = []float64{10, 25, 50, 100, 250, 500, 1000}
+
+func (r *elasticRepository) SearchProducts(ctx context.Context, search *ProductSearch, categoryIDs []string) (*SearchResult, error) {
+	var query elastic.Query = elastic.NewMatchAllQuery()
+	if search.Query != "" {
+		query = elastic.NewBoolQuery().Should(
+			elastic.NewMultiMatchQuery(search.Query, "name", "description"),
+			elastic.NewTermQuery("variants.sku", search.Query),
+		)
+	}
+	query = visible(inCategories(query, categoryIDs), search.IncludeInactive)
+	if filters := searchFilters(search); len(filters) > 0 {
+		query = elastic.NewBoolQuery().Must(query).Filter(filters...)
+	}
+
+	service := r.client.Search().
+		Index(productAlias).
+		Type("product").
 		Query(query).
-		Size(len(ids)).
-		Do(ctx)
+		Version(true).
+		From(search.Skip).Size(search.Take)
+	switch search.Sort {
+	case SortPriceAsc:
+		service = service.SortBy(elastic.NewFieldSort("price_amount").Asc().Missing("_last").UnmappedType("double"))
+	case SortPriceDesc:
+		service = service.SortBy(elastic.NewFieldSort("price_amount").Desc().Missing("_last").UnmappedType("double"))
+	case SortNewest:
+		service = service.SortBy(elastic.NewFieldSort("created_at").Desc().Missing("_last").UnmappedType("date"))
+	}
+	if search.WithFacets {
+		service = service.
+			Aggregation(categoriesFacet, elastic.NewTermsAggregation().Field("category_ids.keyword").Size(maxFacetValues)).
+			Aggregation(attributesFacet, elastic.NewTermsAggregation().Field("attribute_values.keyword").Size(maxFacetValues)).
+			Aggregation(currenciesFacet, elastic.NewTermsAggregation().Field("currency.keyword").Size(maxFacetValues).
+				SubAggregation(pricesFacet, priceRangeAggregation()))
+	}
+	searchResult, err := service.Do(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return r.convertSearchResults(searchResult), nil
+	result := &SearchResult{
+		Products: r.convertSearchResults(searchResult),
+		Total:    searchResult.TotalHits(),
+	}
+	if search.WithFacets {
+		result.Facets = facetsFromAggregations(searchResult.Aggregations)
+	}
+	return result, nil
+}
+
+// searchFilters builds the price and attribute filters of a search.
+func searchFilters(search *ProductSearch) []elastic.Query {
+	var filters []elastic.Query
+	if search.MinPrice != nil || search.MaxPrice != nil {
+		price := elastic.NewRangeQuery("price_amount")
+		var currency string
+		if search.MinPrice != nil {
+			price = price.Gte(priceAmount(*search.MinPrice))
+			currency = search.MinPrice.Currency
+		}
+		if search.MaxPrice != nil {
+			price = price.Lte(priceAmount(*search.MaxPrice))
+			currency = search.MaxPrice.Currency
+		}
+		filters = append(filters, price, elastic.NewTermQuery("currency.keyword", currency))
+	}
+	names := make([]string, 0, len(search.Attributes))
+	for name := range search.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		values := make([]interface{}, 0, len(search.Attributes[name]))
+		for _, value := range search.Attributes[name] {
+			values = append(values, name+attributeSeparator+value)
+		}
+		filters = append(filters, elastic.NewTermsQuery("attribute_values.keyword", values...))
+	}
+	return filters
 }
 
-func (r *elasticRepository) SearchProducts(ctx context.Context, query string, skip int, take int) ([]*Product, error) {
-	matchQuery := elastic.NewMultiMatchQuery(query, "name", "description")
+func priceRangeAggregation() *elastic.RangeAggregation {
+	agg := elastic.NewRangeAggregation().Field("price_amount").AddUnboundedFrom(priceRangeBounds[0])
+	for i := 1; i < len(priceRangeBounds); i++ {
+		agg = agg.AddRange(priceRangeBounds[i-1], priceRangeBounds[i])
+	}
+	return agg.AddUnboundedTo(priceRangeBounds[len(priceRangeBounds)-1])
+}
+
+func facetsFromAggregations(aggs elastic.Aggregations) *Facets {
+	facets := &Facets{}
+	if terms, ok := aggs.Terms(categoriesFacet); ok {
+		for _, bucket := range terms.Buckets {
+			facets.Categories = append(facets.Categories, &FacetCount{Value: fmt.Sprint(bucket.Key), Count: bucket.DocCount})
+		}
+	}
+	if terms, ok := aggs.Terms(attributesFacet); ok {
+		byName := map[string]*AttributeFacet{}
+		for _, bucket := range terms.Buckets {
+			name, value, ok := strings.Cut(fmt.Sprint(bucket.Key), attributeSeparator)
+			if !ok {
+				continue
+			}
+			facet, ok := byName[name]
+			if !ok {
+				facet = &AttributeFacet{Name: name}
+				byName[name] = facet
+				facets.Attributes = append(facets.Attributes, facet)
+			}
+			facet.Values = append(facet.Values, &FacetCount{Value: value, Count: bucket.DocCount})
+		}
+	}
+	if terms, ok := aggs.Terms(currenciesFacet); ok {
+		for _, bucket := range terms.Buckets {
+			currency := fmt.Sprint(bucket.Key)
+			ranges, ok := bucket.Range(pricesFacet)
+			if !ok {
+				continue
+			}
+			for _, r := range ranges.Buckets {
+				if r.DocCount == 0 {
+					continue
+				}
+				facets.Prices = append(facets.Prices, &PriceRangeFacet{
+					From:  priceBound(r.From, currency),
+					To:    priceBound(r.To, currency),
+					Count: r.DocCount,
+				})
+			}
+		}
+	}
+	return facets
+}
+
+func priceBound(amount *float64, currency string) *money.Money {
+	if amount == nil {
+		return nil
+	}
+	m := money.New(int64(math.Round(*amount*math.Pow10(money.Exponent(currency)))), currency)
+	return &m
+}
+
+// suggestName names the completion suggestion in suggest requests.
+const suggestName = "products"
+
+// SuggestProducts completes prefix to the names of active products. Matching
+// tolerates a typo or two past the first letter, so "runing" still finds
+// "Running Shoes".
+func (r *elasticRepository) SuggestProducts(ctx context.Context, prefix string, size int) ([]*Product, error) {
+	suggester := elastic.NewCompletionSuggester(suggestName).
+		Field("name_suggest").
+		PrefixWithOptions(prefix, elastic.NewFuzzyCompletionSuggesterOptions().EditDistance("AUTO").PrefixLength(1)).
+		Size(size)
 	searchResult, err := r.client.Search().
-		Index("catalog").
+		Index(productAlias).
 		Type("product").
-		Query(matchQuery).
-		From(skip).Size(take).
+		Suggester(suggester).
+		Size(0).
 		Do(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return r.convertSearchResults(searchResult), nil
+	var products []*Product
+	for _, suggestion := range searchResult.Suggest[suggestName] {
+		for _, option := range suggestion.Options {
+			if option.Source == nil {
+				continue
+			}
+			var doc ProductDocument
+			if err := json.Unmarshal(*option.Source, &doc); err != nil {
+				continue
+			}
+			products = append(products, doc.product(option.Id, nil))
+		}
+	}
+	return products, nil
+}
+
+// CountProductsInCategories counts the products, of any status, assigned to
+// any of categoryIDs.
+func (r *elasticRepository) CountProductsInCategories(ctx context.Context, categoryIDs []string) (int64, error) {
+	return r.client.Count(productAlias).
+		Type("product").
+		Query(categoriesQuery(categoryIDs)).
+		Do(ctx)
+}
+
+// PutCategory stores a category and waits for it to become searchable, since
+// categories are always read back through ListCategories.
+func (r *elasticRepository) PutCategory(ctx context.Context, category *Category) error {
+	_, err := r.client.Index().
+		Index("catalog_categories").
+		Type("category").
+		Id(category.ID).
+		BodyJson(categoryDocument{Name: category.Name, ParentID: category.ParentID}).
+		Refresh("wait_for").
+		Do(ctx)
+	return err
+}
+
+func (r *elasticRepository) GetCategoryById(ctx context.Context, id string) (*Category, error) {
+	res, err := r.client.Get().
+		Index("catalog_categories").
+		Type("category").
+		Id(id).
+		Do(ctx)
+	if err != nil {
+		if elastic.IsNotFound(err) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, err
+	}
+	if !res.Found {
+		return nil, ErrCategoryNotFound
+	}
+	var doc categoryDocument
+	if err := json.Unmarshal(*res.Source, &doc); err != nil {
+		return nil, err
+	}
+	return &Category{ID: res.Id, Name: doc.Name, ParentID: doc.ParentID}, nil
+}
+
+// ListCategories returns every category, ordered by name.
+func (r *elasticRepository) ListCategories(ctx context.Context) ([]*Category, error) {
+	res, err := r.client.Search().
+		Index("catalog_categories").
+		Type("category").
+		Query(elastic.NewMatchAllQuery()).
+		Sort("name.keyword", true).
+		Size(maxCategories).
+		Do(ctx)
+	if err != nil {
+		if elastic.IsNotFound(err) {
+			// Nothing has been categorized yet
+			return nil, nil
+		}
+		return nil, err
+	}
+	var categories []*Category
+	for _, hit := range res.Hits.Hits {
+		var doc categoryDocument
+		if err := json.Unmarshal(*hit.Source, &doc); err != nil {
+			continue
+		}
+		categories = append(categories, &Category{ID: hit.Id, Name: doc.Name, ParentID: doc.ParentID})
+	}
+	return categories, nil
+}
+
+func (r *elasticRepository) DeleteCategory(ctx context.Context, id string) error {
+	_, err := r.client.Delete().
+		Index("catalog_categories").
+		Type("category").
+		Id(id).
+		Refresh("wait_for").
+		Do(ctx)
+	if elastic.IsNotFound(err) {
+		return ErrCategoryNotFound
+	}
+	return err
 }
 
 func (r *elasticRepository) convertSearchResults(searchResult *elastic.SearchResult) []*Product {
@@ -133,14 +750,247 @@ func (r *elasticRepository) convertSearchResults(searchResult *elastic.SearchRes
 		if err != nil {
 			continue
 		}
-		product := &Product{
-			ID:          hit.Id,
-			Name:        doc.Name,
-			Description: doc.Description,
-			Price:       doc.Price,
-		}
-		products = append(products, product)
+		products = append(products, doc.product(hit.Id, hit.Version))
 	}
 	return products
 }
-	
\ No newline at end of file
+
+const (
+	// stockHolderScript finds what holds the stock of an item: the variant
+	// with params.sku or, when the SKU is empty, the product itself, provided
+	// it has no variants. holder is null when there is no such thing.
+	stockHolderScript = `
+		def holder = null;
+		if (params.sku == '') {
+			if (ctx._source.variants == null || ctx._source.variants.isEmpty()) {
+				holder = ctx._source;
+			}
+		} else if (ctx._source.variants != null) {
+			for (def v : ctx._source.variants) {
+				if (v.sku == params.sku) {
+					holder = v;
+				}
+			}
+		}`
+	// holdScript finds whether reservation params.reservation holds the item.
+	// Each hold is recorded in the product's held_by as "reservation/sku", in
+	// the same write that changes the stock, so that every script below can
+	// safely be run more than once. Holds of untracked reservations are not
+	// recorded and count as held.
+	//
+	// A product without an edit version still goes by its document version,
+	// which the write is about to bump, so the version clients hold is kept
+	// as its edit version first.
+	holdScript = stockHolderScript + `
+		if (ctx._source.edit_version == null) {
+			ctx._source.edit_version = ctx._version;
+		}
+		if (ctx._source.held_by == null) {
+			ctx._source.held_by = [];
+		}
+		def hold = params.reservation + '/' + params.sku;
+		def held = !params.tracked || ctx._source.held_by.contains(hold);`
+	// reserveScript holds stock for a reservation, or does nothing if not
+	// enough is available. Stock the reservation already holds is left as it
+	// is.
+	reserveScript = holdScript + `
+		if (!held) {
+			if (holder == null || holder.stock - holder.reserved < params.quantity) {
+				ctx.op = 'none';
+			} else {
+				holder.reserved += params.quantity;
+				ctx._source.held_by.add(hold);
+			}
+		}`
+	// releaseScript returns reserved stock.
+	releaseScript = holdScript + `
+		if (!held) {
+			ctx.op = 'none';
+		} else {
+			if (holder != null) {
+				holder.reserved -= params.quantity;
+			}
+			ctx._source.held_by.removeIf(h -> h == hold);
+		}`
+	// commitScript removes reserved stock from the stock on hand.
+	commitScript = holdScript + `
+		if (!held) {
+			ctx.op = 'none';
+		} else {
+			if (holder != null) {
+				holder.reserved -= params.quantity;
+				holder.stock -= params.quantity;
+			}
+			ctx._source.held_by.removeIf(h -> h == hold);
+		}`
+	// transitionScript moves a reservation between states, or does nothing if
+	// it is not in the expected state.
+	transitionScript = `
+		if (ctx._source.state == params.from) {
+			ctx._source.state = params.to;
+		} else {
+			ctx.op = 'none';
+		}`
+)
+
+// ReserveStock records the reservation and then holds stock for each item. If a
+// product runs short, the stock already held is returned and the reservation
+// is removed. Should returning the stock fail, the reservation is left open,
+// listing only the items held, for ReleaseStock to finish.
+func (r *elasticRepository) ReserveStock(ctx context.Context, reservationID string, items []*StockItem) error {
+	_, err := r.client.Index().
+		Index("catalog_reservations").
+		Type("reservation").
+		Id(reservationID).
+		OpType("create").
+		BodyJson(reservationDocument{State: ReservationReserved, Items: items, Tracked: true}).
+		Do(ctx)
+	if err != nil {
+		if elastic.IsConflict(err) {
+			return ErrReservationExists
+		}
+		return err
+	}
+
+	var held []*StockItem
+	for _, item := range items {
+		if err = r.reserveItem(ctx, reservationID, item); err != nil {
+			break
+		}
+		held = append(held, item)
+	}
+	if err == nil {
+		return nil
+	}
+
+	// The request being cancelled may be why it failed, so roll back on
+	// a context of our own
+	rollbackCtx := context.WithoutCancel(ctx)
+	if rollbackErr := r.updateItems(rollbackCtx, reservationID, true, held, releaseScript); rollbackErr != nil {
+		_, listErr := r.client.Update().
+			Index("catalog_reservations").
+			Type("reservation").
+			Id(reservationID).
+			Doc(map[string]interface{}{"items": held}).
+			Do(rollbackCtx)
+		return fmt.Errorf("%w (returning the stock held failed: %v)", err, errors.Join(rollbackErr, listErr))
+	}
+	_, deleteErr := r.client.Delete().
+		Index("catalog_reservations").
+		Type("reservation").
+		Id(reservationID).
+		Do(rollbackCtx)
+	if deleteErr != nil && !elastic.IsNotFound(deleteErr) {
+		return fmt.Errorf("%w (removing the reservation failed: %v)", err, deleteErr)
+	}
+	return err
+}
+
+func (r *elasticRepository) ReleaseStock(ctx context.Context, reservationID string) error {
+	return r.closeReservation(ctx, reservationID, ReservationReleased, releaseScript)
+}
+
+func (r *elasticRepository) CommitStock(ctx context.Context, reservationID string) error {
+	return r.closeReservation(ctx, reservationID, ReservationCommitted, commitScript)
+}
+
+func (r *elasticRepository) reserveItem(ctx context.Context, reservationID string, item *StockItem) error {
+	res, err := r.updateStock(ctx, reservationID, true, item, reserveScript)
+	if err != nil {
+		return err
+	}
+	if res.Result != "noop" {
+		return nil
+	}
+	product, err := r.GetProductById(ctx, item.ProductID)
+	if err != nil {
+		return err
+	}
+	variant, err := product.Resolve(item.SKU)
+	if err != nil {
+		return err
+	}
+	available := product.Available()
+	if variant != nil {
+		available = variant.Available()
+	}
+	return &InsufficientStockError{
+		ProductID: item.ProductID,
+		SKU:       item.SKU,
+		Requested: item.Quantity,
+		Available: available,
+	}
+}
+
+// updateItems applies script to the stock of each item of a reservation.
+func (r *elasticRepository) updateItems(ctx context.Context, reservationID string, tracked bool, items []*StockItem, script string) error {
+	for _, item := range items {
+		if _, err := r.updateStock(ctx, reservationID, tracked, item, script); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *elasticRepository) updateStock(ctx context.Context, reservationID string, tracked bool, item *StockItem, script string) (*elastic.UpdateResponse, error) {
+	res, err := r.client.Update().
+		Index(productAlias).
+		Type("product").
+		Id(item.ProductID).
+		Script(elastic.NewScript(script).Type("source").Params(map[string]interface{}{
+			"reservation": reservationID,
+			"tracked":     tracked,
+			"sku":         item.SKU,
+			"quantity":    item.Quantity,
+		})).
+		RetryOnConflict(5).
+		Do(ctx)
+	if err != nil {
+		if elastic.IsNotFound(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return res, nil
+}
+
+// closeReservation moves an open reservation to state and applies script to the
+// stock of each of its items. Closing a reservation that is already in state
+// applies script again, which only touches the items it has not yet reached,
+// so a retry finishes a close that failed part way.
+func (r *elasticRepository) closeReservation(ctx context.Context, reservationID string, state ReservationState, script string) error {
+	res, err := r.client.Update().
+		Index("catalog_reservations").
+		Type("reservation").
+		Id(reservationID).
+		Script(elastic.NewScript(transitionScript).Type("source").Params(map[string]interface{}{
+			"from": ReservationReserved,
+			"to":   state,
+		})).
+		RetryOnConflict(5).
+		FetchSource(true).
+		Do(ctx)
+	if err != nil {
+		if elastic.IsNotFound(err) {
+			return ErrReservationNotFound
+		}
+		return err
+	}
+	if res.GetResult == nil || res.GetResult.Source == nil {
+		return errors.New("reservation source missing from update response")
+	}
+	var doc reservationDocument
+	if err := json.Unmarshal(*res.GetResult.Source, &doc); err != nil {
+		return err
+	}
+	if res.Result == "noop" {
+		if doc.State != state {
+			return ErrReservationClosed
+		}
+		if !doc.Tracked {
+			// Nothing tells which items were reached, so leave them be
+			return nil
+		}
+	}
+	return r.updateItems(ctx, reservationID, doc.Tracked, doc.Items, script)
+}
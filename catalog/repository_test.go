@@ -0,0 +1,173 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"microservice/money"
+
+	"github.com/segmentio/ksuid"
+)
+
+// testRepository connects to the Elasticsearch cluster named by
+// CATALOG_TEST_ELASTICSEARCH_URL, skipping the test when it is not set. The
+// stock scripts only run inside Elasticsearch, so they cannot be faked.
+func testRepository(t *testing.T) *elasticRepository {
+	t.Helper()
+	url := os.Getenv("CATALOG_TEST_ELASTICSEARCH_URL")
+	if url == "" {
+		t.Skip("CATALOG_TEST_ELASTICSEARCH_URL is not set")
+	}
+	r, err := NewElasticRepository(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r.(*elasticRepository)
+}
+
+// putTestProduct stores a product with stock and the given variants, and
+// deletes it when the test ends.
+func putTestProduct(t *testing.T, r *elasticRepository, stock int, variants ...*Variant) *Product {
+	t.Helper()
+	product := &Product{
+		ID:        ksuid.New().String(),
+		Name:      "Mug",
+		Price:     money.New(1250, "USD"),
+		Stock:     stock,
+		Status:    ProductActive,
+		Variants:  variants,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := r.PutProduct(context.Background(), product); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		r.client.Delete().Index(productAlias).Type("product").Id(product.ID).Do(context.Background())
+	})
+	return product
+}
+
+func getTestProduct(t *testing.T, r *elasticRepository, id string) *Product {
+	t.Helper()
+	product, err := r.GetProductById(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return product
+}
+
+func TestStockReservation(t *testing.T) {
+	r := testRepository(t)
+	ctx := context.Background()
+	product := putTestProduct(t, r, 5)
+	reserve := func(quantity int) (string, error) {
+		id := ksuid.New().String()
+		return id, r.ReserveStock(ctx, id, []*StockItem{{ProductID: product.ID, Quantity: quantity}})
+	}
+
+	first, err := reserve(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var short *InsufficientStockError
+	if _, err := reserve(3); !errors.As(err, &short) || short.Available != 2 {
+		t.Fatalf("reserving more than is available: error = %v, want 2 available", err)
+	}
+	if got := getTestProduct(t, r, product.ID); got.Stock != 5 || got.Reserved != 3 {
+		t.Fatalf("after reserving = %d in stock, %d reserved; want 5, 3", got.Stock, got.Reserved)
+	}
+
+	// Releasing twice returns the stock once
+	for i := 0; i < 2; i++ {
+		if err := r.ReleaseStock(ctx, first); err != nil {
+			t.Fatalf("release %d: %v", i, err)
+		}
+	}
+	if got := getTestProduct(t, r, product.ID); got.Reserved != 0 {
+		t.Fatalf("after releasing = %d reserved, want 0", got.Reserved)
+	}
+
+	second, err := reserve(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Committing twice takes the stock once
+	for i := 0; i < 2; i++ {
+		if err := r.CommitStock(ctx, second); err != nil {
+			t.Fatalf("commit %d: %v", i, err)
+		}
+	}
+	if got := getTestProduct(t, r, product.ID); got.Stock != 3 || got.Reserved != 0 {
+		t.Errorf("after committing = %d in stock, %d reserved; want 3, 0", got.Stock, got.Reserved)
+	}
+	if err := r.ReleaseStock(ctx, second); !errors.Is(err, ErrReservationClosed) {
+		t.Errorf("releasing a committed reservation: error = %v, want %v", err, ErrReservationClosed)
+	}
+}
+
+func TestStockReservationRollsBack(t *testing.T) {
+	r := testRepository(t)
+	ctx := context.Background()
+	plenty := putTestProduct(t, r, 10)
+	variants := putTestProduct(t, r, 0,
+		&Variant{SKU: ksuid.New().String(), Options: map[string]string{"size": "M"}, Stock: 1},
+	)
+	sku := variants.Variants[0].SKU
+
+	id := ksuid.New().String()
+	err := r.ReserveStock(ctx, id, []*StockItem{
+		{ProductID: plenty.ID, Quantity: 4},
+		{ProductID: variants.ID, SKU: sku, Quantity: 2},
+	})
+	var short *InsufficientStockError
+	if !errors.As(err, &short) || short.SKU != sku || short.Available != 1 {
+		t.Fatalf("ReserveStock error = %v, want 1 of SKU %s available", err, sku)
+	}
+	if got := getTestProduct(t, r, plenty.ID); got.Reserved != 0 {
+		t.Errorf("stock held by the failed reservation = %d, want 0", got.Reserved)
+	}
+	if err := r.ReleaseStock(ctx, id); !errors.Is(err, ErrReservationNotFound) {
+		t.Errorf("releasing the failed reservation: error = %v, want %v", err, ErrReservationNotFound)
+	}
+
+	// The ID is free again, so the order can retry with what is left
+	if err := r.ReserveStock(ctx, id, []*StockItem{{ProductID: variants.ID, SKU: sku, Quantity: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := getTestProduct(t, r, variants.ID).Variant(sku); got.Reserved != 1 {
+		t.Errorf("variant reserved = %d, want 1", got.Reserved)
+	}
+	r.ReleaseStock(ctx, id)
+}
+
+func TestStockChangesKeepEditVersion(t *testing.T) {
+	r := testRepository(t)
+	ctx := context.Background()
+	product := putTestProduct(t, r, 5)
+	read := getTestProduct(t, r, product.ID)
+
+	if err := r.ReserveStock(ctx, ksuid.New().String(), []*StockItem{{ProductID: product.ID, Quantity: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	reserved := getTestProduct(t, r, product.ID)
+	if reserved.Version != read.Version {
+		t.Errorf("version after reserving = %d, want %d", reserved.Version, read.Version)
+	}
+	// The reservation moved the document, so a write of what was read before
+	// it fails, and one of what was read after it succeeds
+	read.Name = "Big mug"
+	if _, err := r.UpdateProduct(ctx, read, []string{PathName}); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("write read before the reservation: error = %v, want %v", err, ErrVersionConflict)
+	}
+	reserved.Name = "Big mug"
+	version, err := r.UpdateProduct(ctx, reserved, []string{PathName})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := getTestProduct(t, r, product.ID); got.Version != version || version != read.Version+1 || got.Reserved != 1 {
+		t.Errorf("after the write = version %d, %d reserved; want version %d, 1 reserved", got.Version, got.Reserved, read.Version+1)
+	}
+}
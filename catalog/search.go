@@ -0,0 +1,186 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"microservice/money"
+)
+
+var (
+	ErrInvalidSearch = errors.New("invalid product search")
+)
+
+// SortOrder is the order of search results.
+type SortOrder string
+
+const (
+	// SortRelevance ranks the best matches for the query first.
+	SortRelevance SortOrder = "relevance"
+	SortPriceAsc  SortOrder = "price_asc"
+	SortPriceDesc SortOrder = "price_desc"
+	// SortNewest lists the most recently created products first.
+	SortNewest SortOrder = "newest"
+)
+
+// Valid reports whether o is a known sort order.
+func (o SortOrder) Valid() bool {
+	switch o {
+	case SortRelevance, SortPriceAsc, SortPriceDesc, SortNewest:
+		return true
+	}
+	return false
+}
+
+// maxTake caps how many products a single search returns.
+const maxTake = 100
+
+// Suggestions returned when the caller does not ask for a number, and at most.
+const (
+	defaultSuggestions = 5
+	maxSuggestions     = 10
+)
+
+// ProductSearch describes a page of products to find. Every filter that is set
+// must match; within an attribute, any of the listed values may match.
+type ProductSearch struct {
+	// Query is matched against name and description, and exactly against
+	// variant SKUs. Empty matches all.
+	Query string
+	// CategoryID also matches the categories below it.
+	CategoryID string
+	// MinPrice and MaxPrice bound the price, inclusively. When both are set
+	// they must be in the same currency, and only prices in that currency
+	// match.
+	MinPrice   *money.Money
+	MaxPrice   *money.Money
+	Attributes map[string][]string
+	Sort       SortOrder
+	Skip       int
+	Take       int
+	// IncludeInactive also finds draft and archived products.
+	IncludeInactive bool
+	// WithFacets computes facets over every product that matches, not just
+	// the page returned.
+	WithFacets bool
+}
+
+// SearchResult is a page of products, the number of products matching in
+// total and, if asked for, facets.
+type SearchResult struct {
+	Products []*Product
+	Total    int64
+	Facets   *Facets
+}
+
+// Facets count the matching products by category, attribute value and price
+// range, so that a storefront can offer further filters.
+type Facets struct {
+	Categories []*FacetCount
+	Attributes []*AttributeFacet
+	Prices     []*PriceRangeFacet
+}
+
+// FacetCount is how many matching products have a value.
+type FacetCount struct {
+	Value string
+	Count int64
+}
+
+// AttributeFacet counts the values of one attribute.
+type AttributeFacet struct {
+	Name   string
+	Values []*FacetCount
+}
+
+// PriceRangeFacet counts the products priced in [From, To). A nil bound is
+// open.
+type PriceRangeFacet struct {
+	From  *money.Money
+	To    *money.Money
+	Count int64
+}
+
+// SearchProducts finds a page of products matching search.
+func (s *CatalogService) SearchProducts(ctx context.Context, search *ProductSearch) (*SearchResult, error) {
+	if err := validateSearch(search); err != nil {
+		return nil, err
+	}
+	categoryIDs, err := s.categoryWithDescendants(ctx, search.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.SearchProducts(ctx, search, categoryIDs)
+}
+
+// SuggestProducts completes a prefix typed by a shopper to up to size active
+// products, best match first.
+func (s *CatalogService) SuggestProducts(ctx context.Context, prefix string, size int) ([]*Product, error) {
+	prefix = strings.TrimSpace(prefix)
+	if prefix == "" {
+		return nil, fmt.Errorf("%w: prefix is required", ErrInvalidSearch)
+	}
+	if size <= 0 {
+		size = defaultSuggestions
+	}
+	if size > maxSuggestions {
+		size = maxSuggestions
+	}
+	return s.repo.SuggestProducts(ctx, prefix, size)
+}
+
+func validateSearch(search *ProductSearch) error {
+	if search.Skip < 0 || search.Take <= 0 {
+		return fmt.Errorf("%w: invalid pagination parameters", ErrInvalidSearch)
+	}
+	if search.Take > maxTake {
+		search.Take = maxTake
+	}
+	if search.Sort == "" {
+		search.Sort = SortRelevance
+	}
+	if !search.Sort.Valid() {
+		return fmt.Errorf("%w: unknown sort order %q", ErrInvalidSearch, search.Sort)
+	}
+	for _, price := range []*money.Money{search.MinPrice, search.MaxPrice} {
+		if price != nil && !money.ValidCurrency(price.Currency) {
+			return fmt.Errorf("%w: %v", ErrInvalidSearch, money.ErrInvalidCurrency)
+		}
+	}
+	if search.MinPrice != nil && search.MaxPrice != nil {
+		if search.MinPrice.Currency != search.MaxPrice.Currency {
+			return fmt.Errorf("%w: price bounds must be in the same currency", ErrInvalidSearch)
+		}
+		if search.MinPrice.Units > search.MaxPrice.Units {
+			return fmt.Errorf("%w: minimum price is above maximum price", ErrInvalidSearch)
+		}
+	}
+	for name, values := range search.Attributes {
+		if strings.TrimSpace(name) == "" || len(values) == 0 {
+			return fmt.Errorf("%w: attribute filters need a name and values", ErrInvalidSearch)
+		}
+	}
+	return nil
+}
+
+// validateAttributes trims attribute names and values and rejects those that
+// cannot be stored.
+func validateAttributes(attributes map[string]string) (map[string]string, error) {
+	if len(attributes) == 0 {
+		return nil, nil
+	}
+	result := make(map[string]string, len(attributes))
+	for name, value := range attributes {
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if name == "" || value == "" {
+			return nil, fmt.Errorf("%w: attributes need a name and a value", ErrInvalidProduct)
+		}
+		if strings.Contains(name, attributeSeparator) {
+			return nil, fmt.Errorf("%w: attribute name %q must not contain %q", ErrInvalidProduct, name, attributeSeparator)
+		}
+		result[name] = value
+	}
+	return result, nil
+}
@@ -2,13 +2,22 @@ package catalog
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net"
+	"strconv"
+	"time"
 
+	"microservice/auth"
 	pb "microservice/catalog/pb"
+	"microservice/money"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 )
 
 type grpcServer struct {
@@ -16,52 +25,441 @@ type grpcServer struct {
 	service Service
 }
 
-// ListenGRPC starts a gRPC server for the Catalog service.
-func ListenGRPC(service Service, port int) error {
+// requiredRoles lists the RPCs that administer the catalog, with the role
+// needed to call them. Reads are public, apart from bulk exports, and stock is
+// only reserved, released and committed by the order service.
+var requiredRoles = map[string]string{
+	pb.CatalogService_PostProduct_FullMethodName:    auth.RoleMerchandiser,
+	pb.CatalogService_UpdateProduct_FullMethodName:  auth.RoleMerchandiser,
+	pb.CatalogService_DeleteProduct_FullMethodName:  auth.RoleMerchandiser,
+	pb.CatalogService_ImportProducts_FullMethodName: auth.RoleMerchandiser,
+	pb.CatalogService_ExportProducts_FullMethodName: auth.RoleMerchandiser,
+	pb.CatalogService_CreateCategory_FullMethodName: auth.RoleMerchandiser,
+	pb.CatalogService_MoveCategory_FullMethodName:   auth.RoleMerchandiser,
+	pb.CatalogService_DeleteCategory_FullMethodName: auth.RoleMerchandiser,
+	pb.CatalogService_ReserveStock_FullMethodName:   auth.RoleService,
+	pb.CatalogService_ReleaseStock_FullMethodName:   auth.RoleService,
+	pb.CatalogService_CommitStock_FullMethodName:    auth.RoleService,
+}
+
+// ListenGRPC starts a gRPC server for the Catalog service. Callers are
+// authenticated by the bearer token in their metadata, checked by verifier.
+func ListenGRPC(service Service, verifier *auth.Verifier, port int) error {
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
 	if err != nil {
 		return err
 	}
-	s := grpc.NewServer()
+	s := grpc.NewServer(
+		grpc.UnaryInterceptor(auth.UnaryServerInterceptor(verifier, requiredRoles)),
+		grpc.StreamInterceptor(auth.StreamServerInterceptor(verifier, requiredRoles)),
+	)
 	pb.RegisterCatalogServiceServer(s, &grpcServer{service: service})
 	reflection.Register(s)
 	return s.Serve(lis)
 }
 
 func (s *grpcServer) PostProduct(ctx context.Context, req *pb.PostProductRequest) (*pb.PostProductResponse, error) {
-	product, err := s.service.PostProduct(ctx, req.Name, req.Description, req.Price)
+	product, err := s.service.PostProduct(ctx, draftFromProto(req))
 	if err != nil {
-		return nil, err
+		return nil, grpcError(err)
 	}
-	return &pb.PostProductResponse{Product: &pb.Product{Id: product.ID, Name: product.Name, Description: product.Description, Price: product.Price}}, nil
+	return &pb.PostProductResponse{Product: productToProto(product)}, nil
 }
 
-func (s *grpcServer) GetProduct(ctx context.Context, req *pb.GetProductRequest) (*pb.GetProductResponse, error) {
-	product, err := s.service.GetProduct(ctx, req.Id)
+func draftFromProto(req *pb.PostProductRequest) *Product {
+	return &Product{
+		Name:        req.Name,
+		Description: req.Description,
+		Price:       money.FromProto(req.Price),
+		Stock:       int(req.Stock),
+		Status:      ProductStatus(req.Status),
+		TaxClass:    req.TaxClass,
+		CategoryIDs: req.CategoryIds,
+		Attributes:  req.Attributes,
+		Variants:    variantsFromProto(req.Variants),
+	}
+}
+
+func (s *grpcServer) UpdateProduct(ctx context.Context, req *pb.UpdateProductRequest) (*pb.UpdateProductResponse, error) {
+	if req.Product == nil || req.UpdateMask == nil {
+		return nil, status.Error(codes.InvalidArgument, "product and update_mask are required")
+	}
+	update := convertProduct(req.Product)
+	product, err := s.service.UpdateProduct(ctx, req.Id, update, req.UpdateMask.Paths)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.UpdateProductResponse{Product: productToProto(product)}, nil
+}
+
+func (s *grpcServer) DeleteProduct(ctx context.Context, req *pb.DeleteProductRequest) (*pb.DeleteProductResponse, error) {
+	product, err := s.service.DeleteProduct(ctx, req.Id)
 	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.DeleteProductResponse{Product: productToProto(product)}, nil
+}
+
+// ImportProducts creates the streamed products a batch at a time and, once the
+// stream ends, reports how many were created and why the others were not.
+func (s *grpcServer) ImportProducts(stream pb.CatalogService_ImportProductsServer) error {
+	resp := &pb.ImportProductsResponse{}
+	var batch []*Product
+	row := 1
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		imported, rowErrors, err := s.service.ImportProducts(stream.Context(), batch, row)
+		if err != nil {
+			return grpcError(err)
+		}
+		resp.Imported += uint64(imported)
+		for _, e := range rowErrors {
+			resp.Errors = append(resp.Errors, &pb.ImportError{
+				Row:       uint64(e.Row),
+				ProductId: e.ProductID,
+				Message:   e.Err.Error(),
+			})
+		}
+		row += len(batch)
+		batch = nil
+		return nil
+	}
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		draft := &Product{}
+		if req.Product != nil {
+			draft = draftFromProto(req.Product)
+		}
+		draft.ID = req.Id
+		if len(req.CreatedAt) > 0 {
+			if err := draft.CreatedAt.UnmarshalBinary(req.CreatedAt); err != nil {
+				return status.Errorf(codes.InvalidArgument, "row %d: invalid created_at", row+len(batch))
+			}
+		}
+		batch = append(batch, draft)
+		if len(batch) == importBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	return stream.SendAndClose(resp)
+}
+
+func (s *grpcServer) ExportProducts(req *pb.ExportProductsRequest, stream pb.CatalogService_ExportProductsServer) error {
+	err := s.service.ExportProducts(stream.Context(), req.IncludeInactive, func(p *Product) error {
+		return stream.Send(&pb.ExportProductsResponse{Product: productToProto(p)})
+	})
+	if err != nil {
+		return grpcError(err)
+	}
+	return nil
+}
+
+// authorizeInactive fails unless the caller may see draft and archived
+// products, which are only for those managing the catalog.
+func authorizeInactive(ctx context.Context, includeInactive bool) error {
+	if !includeInactive {
+		return nil
+	}
+	p := auth.FromContext(ctx)
+	if p == nil {
+		return status.Error(codes.Unauthenticated, "authentication required to see inactive products")
+	}
+	if !p.Can(auth.RoleMerchandiser) {
+		return status.Error(codes.PermissionDenied, "not allowed to see inactive products")
+	}
+	return nil
+}
+
+func (s *grpcServer) GetProduct(ctx context.Context, req *pb.GetProductRequest) (*pb.GetProductResponse, error) {
+	if err := authorizeInactive(ctx, req.IncludeInactive); err != nil {
 		return nil, err
 	}
-	return &pb.GetProductResponse{Product: &pb.Product{Id: product.ID, Name: product.Name, Description: product.Description, Price: product.Price}}, nil
+	product, err := s.service.GetProduct(ctx, req.Id, req.IncludeInactive)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.GetProductResponse{Product: productToProto(product)}, nil
 }
 
 func (s *grpcServer) GetProducts(ctx context.Context, req *pb.GetProductsRequest) (*pb.GetProductsResponse, error) {
-	var products []*Product
+	if err := authorizeInactive(ctx, req.IncludeInactive); err != nil {
+		return nil, err
+	}
+	result := &SearchResult{}
 	var err error
 
-	if req.Query != "" { // search
-		products, err = s.service.SearchProducts(ctx, req.Query, int(req.Skip), int(req.Take))
-	} else if len(req.Ids) > 0 { // by IDs
-		products, err = s.service.GetProductByIDs(ctx, req.Ids)
-	} else { // pagination only
-		products, err = s.service.GetProducts(ctx, int(req.Skip), int(req.Take))
+	if len(req.Ids) > 0 { // by IDs
+		result.Products, err = s.service.GetProductByIDs(ctx, req.Ids, req.IncludeInactive)
+	} else { // search, filters and pagination
+		result, err = s.service.SearchProducts(ctx, searchFromProto(req))
 	}
 	if err != nil {
-		return nil, err
+		return nil, grpcError(err)
 	}
 
+	resp := make([]*pb.Product, 0, len(result.Products))
+	for _, p := range result.Products {
+		resp = append(resp, productToProto(p))
+	}
+	return &pb.GetProductsResponse{Products: resp, Total: uint64(result.Total), Facets: facetsToProto(result.Facets)}, nil
+}
+
+func (s *grpcServer) SuggestProducts(ctx context.Context, req *pb.SuggestProductsRequest) (*pb.SuggestProductsResponse, error) {
+	products, err := s.service.SuggestProducts(ctx, req.Prefix, int(req.Size))
+	if err != nil {
+		return nil, grpcError(err)
+	}
 	resp := make([]*pb.Product, 0, len(products))
 	for _, p := range products {
-		resp = append(resp, &pb.Product{Id: p.ID, Name: p.Name, Description: p.Description, Price: p.Price})
+		resp = append(resp, productToProto(p))
+	}
+	return &pb.SuggestProductsResponse{Products: resp}, nil
+}
+
+func searchFromProto(req *pb.GetProductsRequest) *ProductSearch {
+	search := &ProductSearch{
+		Query:           req.Query,
+		CategoryID:      req.CategoryId,
+		Sort:            SortOrder(req.Sort),
+		Skip:            int(req.Skip),
+		Take:            int(req.Take),
+		IncludeInactive: req.IncludeInactive,
+		WithFacets:      req.IncludeFacets,
+	}
+	if req.MinPrice != nil {
+		price := money.FromProto(req.MinPrice)
+		search.MinPrice = &price
+	}
+	if req.MaxPrice != nil {
+		price := money.FromProto(req.MaxPrice)
+		search.MaxPrice = &price
+	}
+	if len(req.Attributes) > 0 {
+		search.Attributes = map[string][]string{}
+		for _, a := range req.Attributes {
+			search.Attributes[a.Name] = append(search.Attributes[a.Name], a.Values...)
+		}
+	}
+	return search
+}
+
+func facetsToProto(f *Facets) *pb.Facets {
+	if f == nil {
+		return nil
+	}
+	resp := &pb.Facets{Categories: facetCountsToProto(f.Categories)}
+	for _, a := range f.Attributes {
+		resp.Attributes = append(resp.Attributes, &pb.AttributeFacet{Name: a.Name, Values: facetCountsToProto(a.Values)})
+	}
+	for _, p := range f.Prices {
+		price := &pb.PriceRangeFacet{Count: uint64(p.Count)}
+		if p.From != nil {
+			price.From = money.ToProto(*p.From)
+		}
+		if p.To != nil {
+			price.To = money.ToProto(*p.To)
+		}
+		resp.Prices = append(resp.Prices, price)
+	}
+	return resp
+}
+
+func facetCountsToProto(counts []*FacetCount) []*pb.FacetCount {
+	resp := make([]*pb.FacetCount, 0, len(counts))
+	for _, c := range counts {
+		resp = append(resp, &pb.FacetCount{Value: c.Value, Count: uint64(c.Count)})
+	}
+	return resp
+}
+
+func (s *grpcServer) CreateCategory(ctx context.Context, req *pb.CreateCategoryRequest) (*pb.CreateCategoryResponse, error) {
+	category, err := s.service.CreateCategory(ctx, req.Name, req.ParentId)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.CreateCategoryResponse{Category: categoryToProto(category)}, nil
+}
+
+func (s *grpcServer) MoveCategory(ctx context.Context, req *pb.MoveCategoryRequest) (*pb.MoveCategoryResponse, error) {
+	category, err := s.service.MoveCategory(ctx, req.Id, req.ParentId)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.MoveCategoryResponse{Category: categoryToProto(category)}, nil
+}
+
+func (s *grpcServer) DeleteCategory(ctx context.Context, req *pb.DeleteCategoryRequest) (*pb.DeleteCategoryResponse, error) {
+	category, err := s.service.DeleteCategory(ctx, req.Id)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.DeleteCategoryResponse{Category: categoryToProto(category)}, nil
+}
+
+func (s *grpcServer) GetCategories(ctx context.Context, req *pb.GetCategoriesRequest) (*pb.GetCategoriesResponse, error) {
+	categories, err := s.service.GetCategories(ctx, req.Ids)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	resp := make([]*pb.Category, 0, len(categories))
+	for _, c := range categories {
+		resp = append(resp, categoryToProto(c))
+	}
+	return &pb.GetCategoriesResponse{Categories: resp}, nil
+}
+
+func (s *grpcServer) ReserveStock(ctx context.Context, req *pb.ReserveStockRequest) (*pb.ReserveStockResponse, error) {
+	items := make([]*StockItem, 0, len(req.Items))
+	for _, item := range req.Items {
+		items = append(items, &StockItem{ProductID: item.ProductId, SKU: item.Sku, Quantity: int(item.Quantity)})
+	}
+	if err := s.service.ReserveStock(ctx, req.ReservationId, items); err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.ReserveStockResponse{}, nil
+}
+
+func (s *grpcServer) ReleaseStock(ctx context.Context, req *pb.ReleaseStockRequest) (*pb.ReleaseStockResponse, error) {
+	if err := s.service.ReleaseStock(ctx, req.ReservationId); err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.ReleaseStockResponse{}, nil
+}
+
+func (s *grpcServer) CommitStock(ctx context.Context, req *pb.CommitStockRequest) (*pb.CommitStockResponse, error) {
+	if err := s.service.CommitStock(ctx, req.ReservationId); err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.CommitStockResponse{}, nil
+}
+
+func productToProto(p *Product) *pb.Product {
+	return &pb.Product{
+		Id:          p.ID,
+		Name:        p.Name,
+		Description: p.Description,
+		Price:       money.ToProto(p.Price),
+		Stock:       uint32(p.Stock),
+		Reserved:    uint32(p.Reserved),
+		Status:      string(p.Status),
+		TaxClass:    p.TaxClass,
+		Version:     p.Version,
+		CategoryIds: p.CategoryIDs,
+		Attributes:  p.Attributes,
+		CreatedAt:   timeToProto(p.CreatedAt),
+		Variants:    variantsToProto(p.Variants),
+	}
+}
+
+func variantsToProto(variants []*Variant) []*pb.Variant {
+	var result []*pb.Variant
+	for _, v := range variants {
+		pv := &pb.Variant{
+			Sku:      v.SKU,
+			Options:  v.Options,
+			Stock:    uint32(v.Stock),
+			Reserved: uint32(v.Reserved),
+		}
+		if v.Price != nil {
+			pv.Price = money.ToProto(*v.Price)
+		}
+		result = append(result, pv)
+	}
+	return result
+}
+
+func variantsFromProto(variants []*pb.Variant) []*Variant {
+	var result []*Variant
+	for _, pv := range variants {
+		v := &Variant{
+			SKU:      pv.Sku,
+			Options:  pv.Options,
+			Stock:    int(pv.Stock),
+			Reserved: int(pv.Reserved),
+		}
+		if pv.Price != nil {
+			price := money.FromProto(pv.Price)
+			v.Price = &price
+		}
+		result = append(result, v)
+	}
+	return result
+}
+
+// timeToProto encodes a time as the services exchange it. The zero time, which
+// products created before timestamps were kept have, is left unset.
+func timeToProto(t time.Time) []byte {
+	if t.IsZero() {
+		return nil
+	}
+	b, _ := t.MarshalBinary()
+	return b
+}
+
+func categoryToProto(c *Category) *pb.Category {
+	return &pb.Category{Id: c.ID, Name: c.Name, ParentId: c.ParentID}
+}
+
+// grpcError maps catalog domain errors onto gRPC status codes. Insufficient
+// stock and version conflicts carry an ErrorInfo detail so callers can tell
+// them apart from other failed preconditions and aborts.
+func grpcError(err error) error {
+	var stockErr *InsufficientStockError
+	switch {
+	case errors.As(err, &stockErr):
+		metadata := map[string]string{
+			"product_id": stockErr.ProductID,
+			"requested":  strconv.Itoa(stockErr.Requested),
+			"available":  strconv.Itoa(stockErr.Available),
+		}
+		if stockErr.SKU != "" {
+			metadata["sku"] = stockErr.SKU
+		}
+		st, detailErr := status.New(codes.FailedPrecondition, err.Error()).WithDetails(&errdetails.ErrorInfo{
+			Reason:   ReasonInsufficientStock,
+			Domain:   "catalog",
+			Metadata: metadata,
+		})
+		if detailErr != nil {
+			return status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return st.Err()
+	case errors.Is(err, ErrVersionConflict):
+		st, detailErr := status.New(codes.Aborted, err.Error()).WithDetails(&errdetails.ErrorInfo{
+			Reason: ReasonVersionConflict,
+			Domain: "catalog",
+		})
+		if detailErr != nil {
+			return status.Error(codes.Aborted, err.Error())
+		}
+		return st.Err()
+	case errors.Is(err, ErrNotFound), errors.Is(err, ErrReservationNotFound), errors.Is(err, ErrCategoryNotFound),
+		errors.Is(err, ErrVariantNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, ErrInvalidPrice), errors.Is(err, ErrInvalidStock), errors.Is(err, ErrInvalidProduct), errors.Is(err, ErrInvalidCategory),
+		errors.Is(err, ErrInvalidSearch), errors.Is(err, ErrVariantRequired):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, ErrProductInUse), errors.Is(err, ErrCategoryInUse):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, ErrReservationExists), errors.Is(err, ErrProductExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, ErrReservationClosed):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case writeBlocked(err):
+		return status.Error(codes.Unavailable, "catalog is being reindexed, retry shortly")
 	}
-	return &pb.GetProductsResponse{Products: resp}, nil
+	return err
 }
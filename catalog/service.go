@@ -3,25 +3,113 @@ package catalog
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"microservice/money"
 
 	"github.com/segmentio/ksuid"
 )
 
+var (
+	ErrInvalidPrice   = errors.New("invalid product price")
+	ErrInvalidProduct = errors.New("invalid product")
+	ErrProductInUse   = errors.New("product has reserved stock")
+	// ErrVersionConflict means the product changed after it was read.
+	ErrVersionConflict = errors.New("product was changed concurrently")
+)
+
+// ReasonVersionConflict is the gRPC ErrorInfo reason attached to
+// ErrVersionConflict.
+const ReasonVersionConflict = "VERSION_CONFLICT"
+
+// Service defines catalog operations. Reads leave out draft and archived
+// products unless includeInactive is set, and filtering by a category also
+// matches the products of every category below it.
 type Service interface {
-	PostProduct(ctx context.Context, name string, description string, price float64) (*Product, error)
-	GetProduct(ctx context.Context, id string) (*Product, error)
-	GetProductByIDs(ctx context.Context, ids []string) ([]*Product, error)
-	GetProducts(ctx context.Context, skip int, take int) ([]*Product, error)
-	SearchProducts(ctx context.Context, query string, skip int, take int) ([]*Product, error)
+	PostProduct(ctx context.Context, product *Product) (*Product, error)
+	UpdateProduct(ctx context.Context, id string, update *Product, paths []string) (*Product, error)
+	DeleteProduct(ctx context.Context, id string) (*Product, error)
+	ImportProducts(ctx context.Context, drafts []*Product, firstRow int) (int, []*RowError, error)
+	ExportProducts(ctx context.Context, includeInactive bool, handle func(*Product) error) error
+	GetProduct(ctx context.Context, id string, includeInactive bool) (*Product, error)
+	GetProductByIDs(ctx context.Context, ids []string, includeInactive bool) ([]*Product, error)
+	SearchProducts(ctx context.Context, search *ProductSearch) (*SearchResult, error)
+	SuggestProducts(ctx context.Context, prefix string, size int) ([]*Product, error)
+	CreateCategory(ctx context.Context, name string, parentID string) (*Category, error)
+	MoveCategory(ctx context.Context, id string, parentID string) (*Category, error)
+	DeleteCategory(ctx context.Context, id string) (*Category, error)
+	GetCategories(ctx context.Context, ids []string) ([]*Category, error)
+	ReserveStock(ctx context.Context, reservationID string, items []*StockItem) error
+	ReleaseStock(ctx context.Context, reservationID string) error
+	CommitStock(ctx context.Context, reservationID string) error
 }
 
 type Product struct {
-	ID          string	`json:"id"`
-	Name        string	`json:"name"`
-	Description string	`json:"description"`
-	Price       float64	`json:"price"`
+	ID          string        `json:"id"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Price       money.Money   `json:"price"`
+	Stock       int           `json:"stock"`
+	Reserved    int           `json:"reserved"`
+	Status      ProductStatus `json:"status"`
+	// TaxClass picks the tax rate charged on the product, such as "reduced"
+	// for food in some regions. Empty means the standard rate.
+	TaxClass    string   `json:"tax_class,omitempty"`
+	CategoryIDs []string `json:"category_ids"`
+	// Attributes are free-form properties, such as color or size, that
+	// products can be filtered and faceted by.
+	Attributes map[string]string `json:"attributes"`
+	// Variants are the versions of the product that are sold, each under its
+	// own SKU. Products without variants are sold as they are.
+	Variants  []*Variant `json:"variants,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	// Version is the version the product was read at. It moves on every
+	// product write but not when stock is reserved, released or committed.
+	Version int64 `json:"version"`
+	// docVersion is the Elasticsearch document version the product was read
+	// at, which stock changes move too.
+	docVersion int64
 }
 
+// ProductStatus controls whether customers can see a product. Drafts are being
+// prepared, and archived products are no longer sold but kept for reference.
+type ProductStatus string
+
+const (
+	ProductDraft    ProductStatus = "draft"
+	ProductActive   ProductStatus = "active"
+	ProductArchived ProductStatus = "archived"
+)
+
+// Valid reports whether s is a known status.
+func (s ProductStatus) Valid() bool {
+	switch s {
+	case ProductDraft, ProductActive, ProductArchived:
+		return true
+	}
+	return false
+}
+
+// Active reports whether the product is visible to customers.
+func (p *Product) Active() bool {
+	return p.Status == ProductActive
+}
+
+// Paths of the product fields that UpdateProduct can change.
+const (
+	PathName        = "name"
+	PathDescription = "description"
+	PathPrice       = "price"
+	PathStock       = "stock"
+	PathStatus      = "status"
+	PathTaxClass    = "tax_class"
+	PathCategories  = "category_ids"
+	PathAttributes  = "attributes"
+	// PathVariants replaces the whole list of variants.
+	PathVariants = "variants"
+)
 
 type CatalogService struct {
 	repo Repository
@@ -31,43 +119,251 @@ func NewCatalogService(repo Repository) *CatalogService {
 	return &CatalogService{repo: repo}
 }
 
-func (s *CatalogService) PostProduct(ctx context.Context, name string, description string, price float64) (*Product, error) {
-	product := &Product{
-		ID:          ksuid.New().String(),
-		Name:        name,
-		Description: description,
-		Price:       price,
+// PostProduct creates a product from the name, description, price, stock,
+// status, tax class, categories and attributes of draft. Products are active unless
+// another status is given.
+func (s *CatalogService) PostProduct(ctx context.Context, draft *Product) (*Product, error) {
+	categoryIDs, err := s.validateCategories(ctx, draft.CategoryIDs)
+	if err != nil {
+		return nil, err
 	}
-	err := s.repo.PutProduct(ctx, product)
+	product, err := newProduct(draft, categoryIDs)
+	if err != nil {
+		return nil, err
+	}
+	err = s.repo.PutProduct(ctx, product)
 	if err != nil {
 		return nil, err
 	}
 	return product, nil
 }
 
-func (s *CatalogService) GetProduct(ctx context.Context, id string) (*Product, error) {
-	return s.repo.GetProductById(ctx, id)
+// conflictRetries is how many times a product write is retried after its
+// document was changed by stock alone while it was being written.
+const conflictRetries = 5
+
+// UpdateProduct copies the fields named by paths from update onto the stored
+// product and saves them, leaving every other field as it was. If
+// update.Version is set, the product must still be at that version. Either way
+// the write fails with ErrVersionConflict if the product is written while it
+// is being updated. Stock reserved meanwhile is no conflict: the update is
+// applied again to the product as it now is.
+func (s *CatalogService) UpdateProduct(ctx context.Context, id string, update *Product, paths []string) (*Product, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("%w: no fields to update", ErrInvalidProduct)
+	}
+	version := update.Version
+	for attempt := 0; ; attempt++ {
+		product, err := s.updatedProduct(ctx, id, version, update, paths)
+		if err != nil {
+			return nil, err
+		}
+		version = product.Version
+		product.Version, err = s.repo.UpdateProduct(ctx, product, paths)
+		if errors.Is(err, ErrVersionConflict) && attempt < conflictRetries {
+			// Reading it again tells a product write, which moved the
+			// version, from a stock change, which did not
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return product, nil
+	}
 }
 
-func (s *CatalogService) GetProductByIDs(ctx context.Context, ids []string) ([]*Product, error) {
-	return s.repo.ListsProductsWithIDs(ctx, ids)
+// updatedProduct reads a product, which must be at version unless that is 0,
+// and copies the fields named by paths from update onto it.
+func (s *CatalogService) updatedProduct(ctx context.Context, id string, version int64, update *Product, paths []string) (*Product, error) {
+	product, err := s.repo.GetProductById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if version != 0 && version != product.Version {
+		return nil, fmt.Errorf("%w: read at version %d, now at %d", ErrVersionConflict, version, product.Version)
+	}
+	for _, path := range paths {
+		switch path {
+		case PathName:
+			product.Name = update.Name
+		case PathDescription:
+			product.Description = update.Description
+		case PathPrice:
+			product.Price = update.Price
+		case PathStock:
+			product.Stock = update.Stock
+		case PathStatus:
+			product.Status = update.Status
+		case PathTaxClass:
+			product.TaxClass = update.TaxClass
+		case PathCategories:
+			if product.CategoryIDs, err = s.validateCategories(ctx, update.CategoryIDs); err != nil {
+				return nil, err
+			}
+		case PathAttributes:
+			if product.Attributes, err = validateAttributes(update.Attributes); err != nil {
+				return nil, err
+			}
+		case PathVariants:
+			if err := replaceVariants(product, update.Variants); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("%w: cannot update field %q", ErrInvalidProduct, path)
+		}
+	}
+	if err := validateProduct(product); err != nil {
+		return nil, err
+	}
+	if product.Stock < product.Reserved {
+		return nil, fmt.Errorf("%w: %d units are reserved by open orders", ErrInvalidStock, product.Reserved)
+	}
+	return product, nil
 }
 
-func (s *CatalogService) GetProducts(ctx context.Context, skip int, take int) ([]*Product, error) {
+// DeleteProduct removes a product for good. Products with stock reserved by
+// open orders cannot be deleted; archive them instead.
+func (s *CatalogService) DeleteProduct(ctx context.Context, id string) (*Product, error) {
+	for attempt := 0; ; attempt++ {
+		product, err := s.repo.GetProductById(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if product.HasReservations() {
+			return nil, fmt.Errorf("%w: archive it instead", ErrProductInUse)
+		}
+		// Deleting at the document version read keeps a reservation from
+		// sneaking in; if one did, the product is checked again
+		err = s.repo.DeleteProduct(ctx, id, product.docVersion)
+		if errors.Is(err, ErrVersionConflict) && attempt < conflictRetries {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return product, nil
+	}
+}
 
-	if skip < 0 || take <= 0 {
-		return nil, errors.New("invalid pagination parameters")
+// newProduct builds and validates a new product from draft, assigned to
+// categoryIDs, which have already been checked. Nothing is reserved yet.
+func newProduct(draft *Product, categoryIDs []string) (*Product, error) {
+	status := draft.Status
+	if status == "" {
+		status = ProductActive
 	}
-	// Adjust the take parameter to a maximum value
-	if take > 100 {
-		take = 100
+	attributes, err := validateAttributes(draft.Attributes)
+	if err != nil {
+		return nil, err
+	}
+	variants := make([]*Variant, 0, len(draft.Variants))
+	for _, v := range draft.Variants {
+		v := *v
+		v.Reserved = 0
+		variants = append(variants, &v)
 	}
-	return s.repo.ListsProducts(ctx, skip, take)
+	product := &Product{
+		ID:          ksuid.New().String(),
+		Name:        draft.Name,
+		Description: draft.Description,
+		Price:       draft.Price,
+		Stock:       draft.Stock,
+		Status:      status,
+		TaxClass:    draft.TaxClass,
+		CategoryIDs: categoryIDs,
+		Attributes:  attributes,
+		Variants:    variants,
+		CreatedAt:   time.Now().UTC(),
+	}
+	if err := validateProduct(product); err != nil {
+		return nil, err
+	}
+	return product, nil
 }
 
-func (s *CatalogService) SearchProducts(ctx context.Context, query string, skip int, take int) ([]*Product, error) {
+func validateProduct(p *Product) error {
+	if strings.TrimSpace(p.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidProduct)
+	}
+	if !p.Status.Valid() {
+		return fmt.Errorf("%w: unknown status %q", ErrInvalidProduct, p.Status)
+	}
+	if !validTaxClass(p.TaxClass) {
+		return fmt.Errorf("%w: tax class must be up to 32 lower-case letters, digits and underscores", ErrInvalidProduct)
+	}
+	if !money.ValidCurrency(p.Price.Currency) {
+		return fmt.Errorf("%w: %v", ErrInvalidPrice, money.ErrInvalidCurrency)
+	}
+	if p.Price.IsNegative() {
+		return fmt.Errorf("%w: must not be negative", ErrInvalidPrice)
+	}
+	if p.Stock < 0 {
+		return fmt.Errorf("%w: must not be negative", ErrInvalidStock)
+	}
+	return validateVariants(p)
+}
 
-	return s.repo.SearchProducts(ctx, query, skip, take)
+func (s *CatalogService) GetProduct(ctx context.Context, id string, includeInactive bool) (*Product, error) {
+	product, err := s.repo.GetProductById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !product.Active() && !includeInactive {
+		return nil, ErrNotFound
+	}
+	return product, nil
+}
+
+func (s *CatalogService) GetProductByIDs(ctx context.Context, ids []string, includeInactive bool) ([]*Product, error) {
+	return s.repo.ListsProductsWithIDs(ctx, ids, includeInactive)
+}
+
+// ReserveStock holds stock for every item or, if any product or variant is
+// short, for none of them. Items of a product with variants must name one.
+func (s *CatalogService) ReserveStock(ctx context.Context, reservationID string, items []*StockItem) error {
+	if reservationID == "" || len(items) == 0 {
+		return fmt.Errorf("%w: reservation needs an ID and at least one item", ErrInvalidStock)
+	}
+	type stockKey struct{ productID, sku string }
+	merged := map[stockKey]*StockItem{}
+	var reservation []*StockItem
+	for _, item := range items {
+		if item.ProductID == "" || item.Quantity <= 0 {
+			return fmt.Errorf("%w: quantity must be positive", ErrInvalidStock)
+		}
+		key := stockKey{item.ProductID, item.SKU}
+		if m, ok := merged[key]; ok {
+			m.Quantity += item.Quantity
+			continue
+		}
+		m := &StockItem{ProductID: item.ProductID, SKU: item.SKU, Quantity: item.Quantity}
+		merged[key] = m
+		reservation = append(reservation, m)
+	}
+	return s.repo.ReserveStock(ctx, reservationID, reservation)
+}
+
+// ReleaseStock returns the stock held by a reservation.
+func (s *CatalogService) ReleaseStock(ctx context.Context, reservationID string) error {
+	return s.repo.ReleaseStock(ctx, reservationID)
 }
 
+// CommitStock permanently removes the stock held by a reservation from the
+// products' on-hand stock.
+func (s *CatalogService) CommitStock(ctx context.Context, reservationID string) error {
+	return s.repo.CommitStock(ctx, reservationID)
+}
 
+// validTaxClass reports whether class is empty or a name such as "reduced"
+// or "zero_rated".
+func validTaxClass(class string) bool {
+	if len(class) > 32 {
+		return false
+	}
+	for _, c := range class {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '_' {
+			return false
+		}
+	}
+	return true
+}
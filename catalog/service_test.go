@@ -0,0 +1,97 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"microservice/money"
+)
+
+// memoryRepository keeps products in memory, checking writes against their
+// document version the way Elasticsearch does. Methods the tests do not reach
+// are left to the embedded nil Repository.
+type memoryRepository struct {
+	Repository
+	products map[string]*Product
+	// beforeUpdate, if set, runs once before the next UpdateProduct, to change
+	// the product while it is being written.
+	beforeUpdate func(stored *Product)
+}
+
+func (r *memoryRepository) GetProductById(ctx context.Context, id string) (*Product, error) {
+	stored, ok := r.products[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	product := *stored
+	return &product, nil
+}
+
+func (r *memoryRepository) UpdateProduct(ctx context.Context, product *Product, paths []string) (int64, error) {
+	stored := r.products[product.ID]
+	if hook := r.beforeUpdate; hook != nil {
+		r.beforeUpdate = nil
+		hook(stored)
+	}
+	if stored.docVersion != product.docVersion {
+		return 0, ErrVersionConflict
+	}
+	written := *product
+	written.Version, written.docVersion = product.Version+1, product.docVersion+1
+	r.products[product.ID] = &written
+	return written.Version, nil
+}
+
+func newTestService() (*CatalogService, *memoryRepository) {
+	repo := &memoryRepository{products: map[string]*Product{
+		"p1": {ID: "p1", Name: "Mug", Price: money.New(1250, "USD"), Stock: 10, Status: ProductActive, Version: 3, docVersion: 7},
+	}}
+	return NewCatalogService(repo), repo
+}
+
+func TestUpdateProductRetriesStockChanges(t *testing.T) {
+	service, repo := newTestService()
+	repo.beforeUpdate = func(stored *Product) {
+		// A reservation moves the document but not the product's version
+		stored.Reserved += 2
+		stored.docVersion++
+	}
+
+	product, err := service.UpdateProduct(context.Background(), "p1", &Product{Name: "Big mug", Version: 3}, []string{PathName})
+	if err != nil {
+		t.Fatalf("UpdateProduct: %v", err)
+	}
+	if product.Version != 4 || product.Name != "Big mug" || product.Reserved != 2 {
+		t.Errorf("product = version %d, %q, %d reserved; want version 4, \"Big mug\", 2 reserved", product.Version, product.Name, product.Reserved)
+	}
+}
+
+func TestUpdateProductConflicts(t *testing.T) {
+	tests := []struct {
+		name         string
+		version      int64
+		beforeUpdate func(stored *Product)
+	}{
+		{name: "read at an older version", version: 2},
+		{name: "edited while being written", version: 3, beforeUpdate: func(stored *Product) {
+			stored.Price = money.New(1500, "USD")
+			stored.Version++
+			stored.docVersion++
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo := newTestService()
+			repo.beforeUpdate = tt.beforeUpdate
+
+			_, err := service.UpdateProduct(context.Background(), "p1", &Product{Name: "Big mug", Version: tt.version}, []string{PathName})
+			if !errors.Is(err, ErrVersionConflict) {
+				t.Fatalf("UpdateProduct error = %v, want %v", err, ErrVersionConflict)
+			}
+			if name := repo.products["p1"].Name; name != "Mug" {
+				t.Errorf("name = %q, want it unchanged", name)
+			}
+		})
+	}
+}
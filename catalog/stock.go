@@ -0,0 +1,63 @@
+package catalog
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrInvalidStock        = errors.New("invalid stock quantity")
+	ErrReservationNotFound = errors.New("stock reservation not found")
+	ErrReservationExists   = errors.New("stock reservation already exists")
+	ErrReservationClosed   = errors.New("stock reservation is no longer open")
+)
+
+// ReasonInsufficientStock is the gRPC ErrorInfo reason attached to errors
+// caused by a product running out of stock.
+const ReasonInsufficientStock = "INSUFFICIENT_STOCK"
+
+// ReservationState is the lifecycle state of a stock reservation.
+type ReservationState string
+
+const (
+	ReservationReserved  ReservationState = "reserved"
+	ReservationReleased  ReservationState = "released"
+	ReservationCommitted ReservationState = "committed"
+)
+
+// StockItem is a quantity of a single product, or of one of its variants when
+// SKU is set, held by a reservation.
+type StockItem struct {
+	ProductID string `json:"product_id"`
+	SKU       string `json:"sku,omitempty"`
+	Quantity  int    `json:"quantity"`
+}
+
+// Reservation holds stock for an order until it is committed (the goods leave
+// the warehouse) or released (the order fails or is cancelled).
+type Reservation struct {
+	ID    string           `json:"id"`
+	State ReservationState `json:"state"`
+	Items []*StockItem     `json:"items"`
+}
+
+// InsufficientStockError reports that a product, or the variant with SKU, does
+// not have enough stock available to satisfy a reservation.
+type InsufficientStockError struct {
+	ProductID string
+	SKU       string
+	Requested int
+	Available int
+}
+
+func (e *InsufficientStockError) Error() string {
+	if e.SKU != "" {
+		return fmt.Sprintf("insufficient stock for product %s SKU %s: requested %d, available %d", e.ProductID, e.SKU, e.Requested, e.Available)
+	}
+	return fmt.Sprintf("insufficient stock for product %s: requested %d, available %d", e.ProductID, e.Requested, e.Available)
+}
+
+// Available returns the stock that can still be reserved.
+func (p *Product) Available() int {
+	return p.Stock - p.Reserved
+}
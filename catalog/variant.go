@@ -0,0 +1,195 @@
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"microservice/money"
+)
+
+var (
+	ErrVariantNotFound = errors.New("variant not found")
+	// ErrVariantRequired means a product with variants was asked for without
+	// naming one of them.
+	ErrVariantRequired = errors.New("product must be ordered by variant SKU")
+)
+
+// maxSKULength is the longest SKU orders can record.
+const maxSKULength = 64
+
+// Variant is one purchasable version of a product, such as a size and colour
+// of a shirt. A product with variants is stocked and sold by variant; its own
+// stock is not used.
+type Variant struct {
+	SKU string `json:"sku"`
+	// Options tell the variants of a product apart, e.g. size=M and
+	// color=red. Every variant of a product has the same option names.
+	Options map[string]string `json:"options"`
+	// Price overrides the product's price when set. It is in the product's
+	// currency.
+	Price    *money.Money `json:"price,omitempty"`
+	Stock    int          `json:"stock"`
+	Reserved int          `json:"reserved"`
+}
+
+// Available returns the stock of the variant that can still be reserved.
+func (v *Variant) Available() int {
+	return v.Stock - v.Reserved
+}
+
+// Variant returns the product's variant with the given SKU.
+func (p *Product) Variant(sku string) (*Variant, bool) {
+	for _, v := range p.Variants {
+		if v.SKU == sku {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// Resolve returns the variant a purchase of sku is for, or nil when sku is
+// empty and the product has no variants. The SKU must name a variant, and a
+// product with variants must be bought by SKU.
+func (p *Product) Resolve(sku string) (*Variant, error) {
+	if sku == "" {
+		if len(p.Variants) > 0 {
+			return nil, fmt.Errorf("%w: product %s", ErrVariantRequired, p.ID)
+		}
+		return nil, nil
+	}
+	v, ok := p.Variant(sku)
+	if !ok {
+		return nil, fmt.Errorf("%w: product %s has no SKU %q", ErrVariantNotFound, p.ID, sku)
+	}
+	return v, nil
+}
+
+// PriceOf returns what one unit of a variant costs: its own price, or the
+// product's if it has none. A nil variant is the product itself.
+func (p *Product) PriceOf(v *Variant) money.Money {
+	if v != nil && v.Price != nil {
+		return *v.Price
+	}
+	return p.Price
+}
+
+// HasReservations reports whether stock of the product or of any of its
+// variants is held by open orders.
+func (p *Product) HasReservations() bool {
+	if p.Reserved > 0 {
+		return true
+	}
+	for _, v := range p.Variants {
+		if v.Reserved > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// validateVariants trims SKUs and options and checks that the variants can be
+// told apart and priced in the product's currency. Reserved counts are left
+// alone.
+func validateVariants(p *Product) error {
+	if len(p.Variants) == 0 {
+		p.Variants = nil
+		return nil
+	}
+	skus := map[string]bool{}
+	combinations := map[string]bool{}
+	var optionNames []string
+	for i, v := range p.Variants {
+		v.SKU = strings.TrimSpace(v.SKU)
+		if v.SKU == "" {
+			return fmt.Errorf("%w: variants need a SKU", ErrInvalidProduct)
+		}
+		if len(v.SKU) > maxSKULength {
+			return fmt.Errorf("%w: SKU %q is longer than %d bytes", ErrInvalidProduct, v.SKU, maxSKULength)
+		}
+		if skus[v.SKU] {
+			return fmt.Errorf("%w: SKU %q is listed more than once", ErrInvalidProduct, v.SKU)
+		}
+		skus[v.SKU] = true
+
+		options, err := validateAttributes(v.Options)
+		if err != nil {
+			return err
+		}
+		if len(options) == 0 {
+			return fmt.Errorf("%w: variant %s needs at least one option", ErrInvalidProduct, v.SKU)
+		}
+		v.Options = options
+		names := make([]string, 0, len(options))
+		for name := range options {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		if i == 0 {
+			optionNames = names
+		} else if strings.Join(names, ",") != strings.Join(optionNames, ",") {
+			return fmt.Errorf("%w: variant %s must have the options %s", ErrInvalidProduct, v.SKU, strings.Join(optionNames, ", "))
+		}
+		pairs := make([]string, 0, len(names))
+		for _, name := range names {
+			pairs = append(pairs, name+attributeSeparator+options[name])
+		}
+		combination := strings.Join(pairs, ",")
+		if combinations[combination] {
+			return fmt.Errorf("%w: more than one variant has the options %s", ErrInvalidProduct, combination)
+		}
+		combinations[combination] = true
+
+		if v.Price != nil {
+			if v.Price.Currency != p.Price.Currency {
+				return fmt.Errorf("%w: variant %s must be priced in %s", ErrInvalidPrice, v.SKU, p.Price.Currency)
+			}
+			if v.Price.IsNegative() {
+				return fmt.Errorf("%w: variant %s must not be negative", ErrInvalidPrice, v.SKU)
+			}
+		}
+		if v.Stock < 0 {
+			return fmt.Errorf("%w: variant %s must not be negative", ErrInvalidStock, v.SKU)
+		}
+	}
+	return nil
+}
+
+// replaceVariants gives a product a new list of variants. Reservations carry
+// over to the variant with the same SKU, so a variant whose stock is reserved
+// cannot be removed or have less stock than is reserved.
+func replaceVariants(p *Product, variants []*Variant) error {
+	replaced := make([]*Variant, 0, len(variants))
+	for _, v := range variants {
+		v := *v
+		v.SKU = strings.TrimSpace(v.SKU)
+		v.Reserved = 0
+		if old, ok := p.Variant(v.SKU); ok {
+			v.Reserved = old.Reserved
+		}
+		replaced = append(replaced, &v)
+	}
+	for _, old := range p.Variants {
+		if old.Reserved == 0 {
+			continue
+		}
+		kept := false
+		for _, v := range replaced {
+			if v.SKU == old.SKU {
+				kept = true
+				break
+			}
+		}
+		if !kept {
+			return fmt.Errorf("%w: variant %s has %d units reserved by open orders", ErrProductInUse, old.SKU, old.Reserved)
+		}
+	}
+	for _, v := range replaced {
+		if v.Stock < v.Reserved {
+			return fmt.Errorf("%w: variant %s has %d units reserved by open orders", ErrInvalidStock, v.SKU, v.Reserved)
+		}
+	}
+	p.Variants = replaced
+	return nil
+}
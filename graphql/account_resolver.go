@@ -13,6 +13,10 @@ func (r *accountResolver) Orders(ctx context.Context, obj *Account) ([]*Order, e
 	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
+	if err := authorizeAccount(ctx, obj.ID); err != nil {
+		return nil, err
+	}
+
 	orderList, err := r.server.orderClient.GetOrderForAccount(ctx, obj.ID)
 	if err != nil {
 		return nil, err
@@ -20,22 +24,28 @@ func (r *accountResolver) Orders(ctx context.Context, obj *Account) ([]*Order, e
 
 	var orders []*Order
 	for _, o := range orderList {
-		var products []*OrderedProduct
-		for _, p := range o.Products {
-			products = append(products, &OrderedProduct{
-				ID:       p.ProductID,
-				Quantity: p.Quantity,
-				// Note: order.OrderedProduct doesn't have Name, Description, Price
-				// These would need to be fetched separately from catalog service
-			})
-		}
-		orders = append(orders, &Order{
-			ID:          o.ID,
-			TotalAmount: o.Total,
-			CreatedAt:   o.CreatedAt,
-			Products:    products,
-		})
+		orders = append(orders, toGraphQLOrder(o))
 	}
 
 	return orders, nil
 }
+
+func (r *accountResolver) Addresses(ctx context.Context, obj *Account) ([]*Address, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	if err := authorizeAccount(ctx, obj.ID); err != nil {
+		return nil, err
+	}
+
+	entries, err := r.server.accountClient.GetAddresses(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make([]*Address, 0, len(entries))
+	for _, a := range entries {
+		addresses = append(addresses, toGraphQLAddress(a))
+	}
+	return addresses, nil
+}
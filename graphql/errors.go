@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// presentError exposes the machine-readable reason of errors as the "code"
+// extension, so clients can tell e.g. an out-of-stock product or a missing
+// token apart from other failures. Errors returned by the microservices carry
+// their reason in a gRPC ErrorInfo, whose metadata is added alongside, keyed
+// in camel case; failed authentication and authorization get a code too.
+func presentError(ctx context.Context, err error) *gqlerror.Error {
+	gqlErr := graphql.DefaultErrorPresenter(ctx, err)
+
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	switch {
+	case errors.Is(err, ErrUnauthenticated):
+		setErrorCode(gqlErr, "UNAUTHENTICATED")
+	case errors.Is(err, ErrForbidden):
+		setErrorCode(gqlErr, "FORBIDDEN")
+	case errors.As(err, &grpcErr):
+		st := grpcErr.GRPCStatus()
+		gqlErr.Message = st.Message()
+		switch st.Code() {
+		case codes.Unauthenticated:
+			setErrorCode(gqlErr, "UNAUTHENTICATED")
+		case codes.PermissionDenied:
+			setErrorCode(gqlErr, "FORBIDDEN")
+		}
+		for _, detail := range st.Details() {
+			info, ok := detail.(*errdetails.ErrorInfo)
+			if !ok {
+				continue
+			}
+			setErrorCode(gqlErr, info.Reason)
+			for k, v := range info.Metadata {
+				gqlErr.Extensions[camelCase(k)] = v
+			}
+		}
+	}
+	return gqlErr
+}
+
+func setErrorCode(gqlErr *gqlerror.Error, code string) {
+	if gqlErr.Extensions == nil {
+		gqlErr.Extensions = map[string]interface{}{}
+	}
+	gqlErr.Extensions["code"] = code
+}
+
+// camelCase turns a snake_case metadata key such as "product_id" into
+// "productId".
+func camelCase(s string) string {
+	parts := strings.Split(s, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
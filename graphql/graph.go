@@ -2,6 +2,7 @@ package main
 
 import (
 	"microservice/account"
+	"microservice/cart"
 	"microservice/catalog"
 	"microservice/order"
 
@@ -12,9 +13,10 @@ type Server struct {
 	accountClient *account.Client
 	catalogClient *catalog.Client
 	orderClient   *order.Client
+	cartClient    *cart.Client
 }
 
-func NewGraphQLServer(accountUrl, catalogUrl, orderUrl string) (*Server, error) {
+func NewGraphQLServer(accountUrl, catalogUrl, orderUrl, cartUrl string) (*Server, error) {
 	accountClient, err := account.NewClient(accountUrl)
 	if err != nil {
 		return nil, err
@@ -30,10 +32,18 @@ func NewGraphQLServer(accountUrl, catalogUrl, orderUrl string) (*Server, error)
 		catalogClient.Close()
 		return nil, err
 	}
+	cartClient, err := cart.NewClient(cartUrl)
+	if err != nil {
+		accountClient.Close()
+		catalogClient.Close()
+		orderClient.Close()
+		return nil, err
+	}
 	return &Server{
 		accountClient: accountClient,
 		catalogClient: catalogClient,
 		orderClient:   orderClient,
+		cartClient:    cartClient,
 	}, nil
 }
 
@@ -49,12 +59,20 @@ func (s *Server) Account() AccountResolver {
 	return &accountResolver{server: s}
 }
 
+func (s *Server) Product() ProductResolver {
+	return &productResolver{server: s}
+}
+
 func (s *Server) Close() {
 	s.accountClient.Close()
 	s.catalogClient.Close()
 	s.orderClient.Close()
+	s.cartClient.Close()
 }
 
 func (s *Server) ToExecutableSchema() graphql.ExecutableSchema {
-	return NewExecutableSchema(Config{Resolvers: s})
+	return NewExecutableSchema(Config{
+		Resolvers:  s,
+		Directives: DirectiveRoot{HasRole: hasRole},
+	})
 }
@@ -4,6 +4,8 @@ import (
 	"log"
 	"net/http"
 
+	"microservice/auth"
+
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/kelseyhightower/envconfig"
@@ -16,6 +18,11 @@ type AppConfig struct {
 	AccountURL string `envconfig:"ACCOUNT_URL" default:"http://localhost:4001"`
 	CatalogURL string `envconfig:"CATALOG_URL" default:"http://localhost:4002"`
 	OrderURL   string `envconfig:"ORDER_URL" default:"http://localhost:4003"`
+	CartURL    string `envconfig:"CART_URL" default:"http://localhost:4004"`
+	// Access tokens are verified with the account service's JWKS document at
+	// AuthJWKSURL if set, else with the shared AuthSecret.
+	AuthSecret  string `envconfig:"AUTH_SECRET"`
+	AuthJWKSURL string `envconfig:"AUTH_JWKS_URL"`
 }
 
 func main() {
@@ -25,7 +32,7 @@ func main() {
 	}
 
 	// Build GraphQL server (currently in-memory data store)
-	srv, err := NewGraphQLServer(config.AccountURL, config.CatalogURL, config.OrderURL)
+	srv, err := NewGraphQLServer(config.AccountURL, config.CatalogURL, config.OrderURL, config.CartURL)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -33,8 +40,14 @@ func main() {
 
 	// Create the gqlgen HTTP handler
 	gqlHandler := handler.NewDefaultServer(srv.ToExecutableSchema())
+	gqlHandler.SetErrorPresenter(presentError)
+
+	verifier, err := auth.LoadVerifier(config.AuthSecret, config.AuthJWKSURL)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	http.Handle("/graphql", gqlHandler)
+	http.Handle("/graphql", authMiddleware(verifier, gqlHandler))
 	// Enable GraphQL Playground at /playground for interactive queries
 	http.Handle("/playground", playground.Handler("GraphQL Playground", "/graphql"))
 
@@ -1,7 +1,487 @@
 package main
 
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"microservice/account"
+	"microservice/cart"
+	"microservice/catalog"
+	"microservice/money"
+	"microservice/order"
+)
+
 type Account struct {
-	ID       string   `json:"id"`
-	Username string   `json:"username"`
-	Orders   []Order `json:"orders"`
+	ID        string     `json:"id"`
+	Username  string     `json:"username"`
+	Email     *string    `json:"email"`
+	Roles     []Role     `json:"roles"`
+	Orders    []Order    `json:"orders"`
+	DeletedAt *time.Time `json:"deletedAt"`
+}
+
+// toGraphQLAccount converts an account returned by the account service into
+// its GraphQL representation.
+func toGraphQLAccount(a *account.Account) *Account {
+	result := &Account{ID: a.ID, Username: a.Name, Roles: toGraphQLRoles(a.Roles), DeletedAt: a.DeletedAt}
+	if a.Email != "" {
+		email := a.Email
+		result.Email = &email
+	}
+	return result
+}
+
+func toGraphQLRoles(roles []string) []Role {
+	result := make([]Role, 0, len(roles))
+	for _, r := range roles {
+		result = append(result, Role(strings.ToUpper(r)))
+	}
+	return result
+}
+
+func fromGraphQLRole(r Role) string {
+	return strings.ToLower(string(r))
+}
+
+func toGraphQLAuthPayload(a *account.Account, t *account.Tokens) *AuthPayload {
+	return &AuthPayload{
+		AccessToken:          t.AccessToken,
+		RefreshToken:         t.RefreshToken,
+		AccessTokenExpiresAt: t.AccessExpiresAt,
+		Account:              toGraphQLAccount(a),
+	}
+}
+
+type Product struct {
+	ID          string        `json:"id"`
+	Name        string        `json:"name"`
+	Description *string       `json:"description"`
+	Price       *money.Money  `json:"price"`
+	Stock       int           `json:"stock"`
+	Status      ProductStatus `json:"status"`
+	Version     string        `json:"version"`
+	Attributes  []*Attribute  `json:"attributes"`
+	Variants    []*Variant    `json:"variants"`
+	TaxClass    *string       `json:"taxClass"`
+	CreatedAt   *time.Time    `json:"createdAt"`
+	CategoryIDs []string      `json:"-"`
+}
+
+// toGraphQLProduct converts a catalog product into its GraphQL representation.
+func toGraphQLProduct(p *catalog.Product) *Product {
+	description, price := p.Description, p.Price
+	result := &Product{
+		ID:          p.ID,
+		Name:        p.Name,
+		Description: &description,
+		Price:       &price,
+		Stock:       p.Available(),
+		Status:      ProductStatus(strings.ToUpper(string(p.Status))),
+		Version:     strconv.FormatInt(p.Version, 10),
+		Attributes:  toGraphQLAttributes(p.Attributes),
+		Variants:    []*Variant{},
+		CategoryIDs: p.CategoryIDs,
+	}
+	for _, v := range p.Variants {
+		variantPrice := p.PriceOf(v)
+		result.Variants = append(result.Variants, &Variant{
+			Sku:     v.SKU,
+			Options: toGraphQLAttributes(v.Options),
+			Price:   &variantPrice,
+			Stock:   v.Available(),
+		})
+	}
+	if p.TaxClass != "" {
+		taxClass := p.TaxClass
+		result.TaxClass = &taxClass
+	}
+	if !p.CreatedAt.IsZero() {
+		createdAt := p.CreatedAt
+		result.CreatedAt = &createdAt
+	}
+	return result
+}
+
+func toGraphQLAttributes(attributes map[string]string) []*Attribute {
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	result := make([]*Attribute, 0, len(names))
+	for _, name := range names {
+		result = append(result, &Attribute{Name: name, Value: attributes[name]})
+	}
+	return result
+}
+
+func fromGraphQLAttributes(attributes []*AttributeInput) (map[string]string, error) {
+	result := make(map[string]string, len(attributes))
+	for _, a := range attributes {
+		if a.Name == "" || a.Value == "" {
+			return nil, ErrValidParameters
+		}
+		result[a.Name] = a.Value
+	}
+	return result, nil
+}
+
+// fromGraphQLVariants converts variant inputs. Their prices must be in
+// currency, the currency of their product, unless it is empty.
+func fromGraphQLVariants(variants []*VariantInput, currency string) ([]*catalog.Variant, error) {
+	result := make([]*catalog.Variant, 0, len(variants))
+	for _, v := range variants {
+		if v.Sku == "" {
+			return nil, ErrValidParameters
+		}
+		options, err := fromGraphQLAttributes(v.Options)
+		if err != nil {
+			return nil, err
+		}
+		variant := &catalog.Variant{SKU: v.Sku, Options: options}
+		if v.Price != nil {
+			if currency != "" && v.Price.Currency != currency {
+				return nil, ErrValidParameters
+			}
+			price, err := money.Parse(v.Price.Amount, v.Price.Currency)
+			if err != nil || price.IsNegative() {
+				return nil, ErrValidParameters
+			}
+			variant.Price = &price
+		}
+		if v.Stock != nil {
+			if *v.Stock < 0 {
+				return nil, ErrValidParameters
+			}
+			variant.Stock = *v.Stock
+		}
+		result = append(result, variant)
+	}
+	return result, nil
+}
+
+func toGraphQLFacets(f *catalog.Facets) *ProductFacets {
+	result := &ProductFacets{
+		Categories: []*CategoryFacet{},
+		Attributes: []*AttributeFacet{},
+		Prices:     []*PriceRangeFacet{},
+	}
+	if f == nil {
+		return result
+	}
+	for _, c := range f.Categories {
+		result.Categories = append(result.Categories, &CategoryFacet{CategoryID: c.Value, Count: int(c.Count)})
+	}
+	for _, a := range f.Attributes {
+		values := make([]*FacetValue, 0, len(a.Values))
+		for _, v := range a.Values {
+			values = append(values, &FacetValue{Value: v.Value, Count: int(v.Count)})
+		}
+		result.Attributes = append(result.Attributes, &AttributeFacet{Name: a.Name, Values: values})
+	}
+	for _, p := range f.Prices {
+		result.Prices = append(result.Prices, &PriceRangeFacet{From: p.From, To: p.To, Count: int(p.Count)})
+	}
+	return result
+}
+
+func toGraphQLCategory(c *catalog.Category) *Category {
+	result := &Category{ID: c.ID, Name: c.Name}
+	if c.ParentID != "" {
+		parentID := c.ParentID
+		result.ParentID = &parentID
+	}
+	return result
+}
+
+func fromGraphQLProductStatus(s ProductStatus) catalog.ProductStatus {
+	return catalog.ProductStatus(strings.ToLower(string(s)))
+}
+
+// toGraphQLOrder converts an order returned by the order service into its
+// GraphQL representation.
+func toGraphQLOrder(o *order.Order) *Order {
+	var products []*OrderedProduct
+	for _, p := range o.Products {
+		name, description, price, tax := p.Name, p.Description, p.Price, p.Tax
+		product := &OrderedProduct{
+			ID:          p.ProductID,
+			Options:     toGraphQLAttributes(p.Options),
+			Name:        &name,
+			Price:       &price,
+			Description: &description,
+			Quantity:    p.Quantity,
+			Discounts:   []*Discount{},
+			TaxAmount:   &tax,
+		}
+		if p.SKU != "" {
+			sku := p.SKU
+			product.Sku = &sku
+		}
+		for _, d := range p.Discounts {
+			amount := d.Amount
+			product.Discounts = append(product.Discounts, &Discount{CouponCode: d.CouponCode, Amount: &amount})
+		}
+		products = append(products, product)
+	}
+
+	statusHistory := []*OrderStatusChange{}
+	for _, c := range o.StatusHistory {
+		statusHistory = append(statusHistory, &OrderStatusChange{
+			Status:    toGraphQLOrderStatus(c.Status),
+			ChangedAt: c.ChangedAt,
+		})
+	}
+
+	refunds := []*Refund{}
+	for _, r := range o.Refunds {
+		amount := r.Amount()
+		refund := &Refund{
+			ID:        r.ID,
+			CreatedAt: r.CreatedAt,
+			Amount:    &amount,
+			Lines:     []*RefundLine{},
+			Pending:   r.Pending,
+		}
+		if r.Reason != "" {
+			reason := r.Reason
+			refund.Reason = &reason
+		}
+		for _, l := range r.Lines {
+			lineAmount := l.Amount
+			line := &RefundLine{
+				ProductID: l.ProductID,
+				Quantity:  l.Quantity,
+				Amount:    &lineAmount,
+			}
+			if l.SKU != "" {
+				sku := l.SKU
+				line.Sku = &sku
+			}
+			refund.Lines = append(refund.Lines, line)
+		}
+		refunds = append(refunds, refund)
+	}
+
+	subtotal, discountAmount, tax, total := o.Subtotal, o.Discount(), o.Tax, o.Total
+	refundedAmount := o.RefundedAmount()
+	couponCodes := o.CouponCodes
+	if couponCodes == nil {
+		couponCodes = []string{}
+	}
+	result := &Order{
+		ID:             o.ID,
+		CreatedAt:      o.CreatedAt,
+		SubtotalAmount: &subtotal,
+		DiscountAmount: &discountAmount,
+		TaxAmount:      &tax,
+		TotalAmount:    &total,
+		CouponCodes:    couponCodes,
+		Products:       products,
+		Status:         toGraphQLOrderStatus(o.Status),
+		StatusHistory:  statusHistory,
+		Refunds:        refunds,
+		RefundedAmount: &refundedAmount,
+	}
+	if o.TaxRegion != "" {
+		taxRegion := o.TaxRegion
+		result.TaxRegion = &taxRegion
+	}
+	if o.ShippingAddress != nil {
+		result.ShippingAddress = toGraphQLShippingAddress(o.ShippingAddress)
+	}
+	result.PaymentID = optionalString(o.PaymentID)
+	return result
+}
+
+// toGraphQLCart converts a cart returned by the cart service into its GraphQL
+// representation.
+func toGraphQLCart(c *cart.Cart) *Cart {
+	result := &Cart{AccountID: c.AccountID, Lines: []*CartLine{}, Subtotal: c.Subtotal}
+	if !c.UpdatedAt.IsZero() {
+		updatedAt := c.UpdatedAt
+		result.UpdatedAt = &updatedAt
+	}
+	for _, l := range c.Lines {
+		line := &CartLine{
+			ProductID: l.ProductID,
+			Quantity:  l.Quantity,
+			AddedAt:   l.AddedAt,
+			Available: l.Available,
+			Options:   toGraphQLAttributes(l.Options),
+			UnitPrice: l.UnitPrice,
+			Total:     l.Total,
+		}
+		if l.SKU != "" {
+			sku := l.SKU
+			line.Sku = &sku
+		}
+		if l.Name != "" {
+			name := l.Name
+			line.Name = &name
+		}
+		result.Lines = append(result.Lines, line)
+	}
+	return result
+}
+
+func toGraphQLCoupon(c *order.Coupon) *Coupon {
+	result := &Coupon{
+		Code:        c.Code,
+		Kind:        CouponKind(strings.ToUpper(string(c.Kind))),
+		AmountOff:   c.AmountOff,
+		CategoryIds: c.CategoryIDs,
+		UsageCount:  c.UsageCount,
+		ExpiresAt:   c.ExpiresAt,
+		CreatedAt:   c.CreatedAt,
+	}
+	if result.CategoryIds == nil {
+		result.CategoryIds = []string{}
+	}
+	if c.Description != "" {
+		description := c.Description
+		result.Description = &description
+	}
+	switch c.Kind {
+	case order.CouponPercentage:
+		percentOff := c.PercentOff
+		result.PercentOff = &percentOff
+	case order.CouponBuyXGetY:
+		buyQuantity, getQuantity := c.BuyQuantity, c.GetQuantity
+		result.BuyQuantity, result.GetQuantity = &buyQuantity, &getQuantity
+	}
+	if c.UsageLimit > 0 {
+		usageLimit := c.UsageLimit
+		result.UsageLimit = &usageLimit
+	}
+	return result
+}
+
+func fromGraphQLCoupon(c CouponInput) (*order.Coupon, error) {
+	if c.Code == "" || !c.Kind.IsValid() {
+		return nil, ErrValidParameters
+	}
+	coupon := &order.Coupon{
+		Code:        c.Code,
+		Kind:        order.CouponKind(strings.ToLower(string(c.Kind))),
+		CategoryIDs: c.CategoryIds,
+		ExpiresAt:   c.ExpiresAt,
+	}
+	if c.Description != nil {
+		coupon.Description = *c.Description
+	}
+	if c.AmountOff != nil {
+		amountOff, err := money.Parse(c.AmountOff.Amount, c.AmountOff.Currency)
+		if err != nil {
+			return nil, ErrValidParameters
+		}
+		coupon.AmountOff = &amountOff
+	}
+	if c.PercentOff != nil {
+		coupon.PercentOff = *c.PercentOff
+	}
+	if c.BuyQuantity != nil {
+		coupon.BuyQuantity = *c.BuyQuantity
+	}
+	if c.GetQuantity != nil {
+		coupon.GetQuantity = *c.GetQuantity
+	}
+	if c.UsageLimit != nil {
+		coupon.UsageLimit = *c.UsageLimit
+	}
+	return coupon, nil
+}
+
+func toGraphQLAddress(a *account.Address) *Address {
+	return &Address{
+		ID:              a.ID,
+		Name:            a.Name,
+		Line1:           a.Line1,
+		Line2:           optionalString(a.Line2),
+		City:            a.City,
+		Region:          optionalString(a.Region),
+		PostalCode:      optionalString(a.PostalCode),
+		Country:         a.Country,
+		Phone:           optionalString(a.Phone),
+		DefaultShipping: a.DefaultShipping,
+		DefaultBilling:  a.DefaultBilling,
+		CreatedAt:       a.CreatedAt,
+		UpdatedAt:       a.UpdatedAt,
+	}
+}
+
+func fromGraphQLAddress(a AddressInput) *account.Address {
+	address := &account.Address{
+		Name:       a.Name,
+		Line1:      a.Line1,
+		Line2:      stringValue(a.Line2),
+		City:       a.City,
+		Region:     stringValue(a.Region),
+		PostalCode: stringValue(a.PostalCode),
+		Country:    a.Country,
+		Phone:      stringValue(a.Phone),
+	}
+	if a.DefaultShipping != nil {
+		address.DefaultShipping = *a.DefaultShipping
+	}
+	if a.DefaultBilling != nil {
+		address.DefaultBilling = *a.DefaultBilling
+	}
+	return address
+}
+
+func toGraphQLShippingAddress(a *order.Address) *ShippingAddress {
+	return &ShippingAddress{
+		AddressID:  optionalString(a.AddressID),
+		Name:       a.Name,
+		Line1:      a.Line1,
+		Line2:      optionalString(a.Line2),
+		City:       a.City,
+		Region:     optionalString(a.Region),
+		PostalCode: optionalString(a.PostalCode),
+		Country:    a.Country,
+		Phone:      optionalString(a.Phone),
+	}
+}
+
+// fromGraphQLShippingAddress converts an address given in full with an order.
+// Its default flags only mean something in the address book and are ignored.
+func fromGraphQLShippingAddress(a *AddressInput) *order.Address {
+	return &order.Address{
+		Name:       a.Name,
+		Line1:      a.Line1,
+		Line2:      stringValue(a.Line2),
+		City:       a.City,
+		Region:     stringValue(a.Region),
+		PostalCode: stringValue(a.PostalCode),
+		Country:    a.Country,
+		Phone:      stringValue(a.Phone),
+	}
+}
+
+// optionalString returns nil for an empty string, for optional fields.
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// stringValue returns the value of an optional input, or "" if it was not
+// given.
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func toGraphQLOrderStatus(s order.OrderStatus) OrderStatus {
+	return OrderStatus(strings.ToUpper(string(s)))
+}
+
+func fromGraphQLOrderStatus(s OrderStatus) order.OrderStatus {
+	return order.OrderStatus(strings.ToLower(string(s)))
 }
@@ -3,6 +3,11 @@
 package main
 
 import (
+	"bytes"
+	"fmt"
+	"io"
+	"microservice/money"
+	"strconv"
 	"time"
 )
 
@@ -10,32 +15,193 @@ type AccountInput struct {
 	Username string `json:"username"`
 }
 
+type Address struct {
+	ID              string    `json:"id"`
+	Name            string    `json:"name"`
+	Line1           string    `json:"line1"`
+	Line2           *string   `json:"line2,omitempty"`
+	City            string    `json:"city"`
+	Region          *string   `json:"region,omitempty"`
+	PostalCode      *string   `json:"postalCode,omitempty"`
+	Country         string    `json:"country"`
+	Phone           *string   `json:"phone,omitempty"`
+	DefaultShipping bool      `json:"defaultShipping"`
+	DefaultBilling  bool      `json:"defaultBilling"`
+	CreatedAt       time.Time `json:"createdAt"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+}
+
+type AddressInput struct {
+	Name            string  `json:"name"`
+	Line1           string  `json:"line1"`
+	Line2           *string `json:"line2,omitempty"`
+	City            string  `json:"city"`
+	Region          *string `json:"region,omitempty"`
+	PostalCode      *string `json:"postalCode,omitempty"`
+	Country         string  `json:"country"`
+	Phone           *string `json:"phone,omitempty"`
+	DefaultShipping *bool   `json:"defaultShipping,omitempty"`
+	DefaultBilling  *bool   `json:"defaultBilling,omitempty"`
+}
+
+type Attribute struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type AttributeFacet struct {
+	Name   string        `json:"name"`
+	Values []*FacetValue `json:"values"`
+}
+
+type AttributeFilterInput struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+type AttributeInput struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type AuthPayload struct {
+	AccessToken          string    `json:"accessToken"`
+	RefreshToken         string    `json:"refreshToken"`
+	AccessTokenExpiresAt time.Time `json:"accessTokenExpiresAt"`
+	Account              *Account  `json:"account"`
+}
+
+type Cart struct {
+	AccountID string       `json:"accountId"`
+	Lines     []*CartLine  `json:"lines"`
+	Subtotal  *money.Money `json:"subtotal,omitempty"`
+	UpdatedAt *time.Time   `json:"updatedAt,omitempty"`
+}
+
+type CartLine struct {
+	ProductID string       `json:"productId"`
+	Sku       *string      `json:"sku,omitempty"`
+	Quantity  int          `json:"quantity"`
+	AddedAt   time.Time    `json:"addedAt"`
+	Available bool         `json:"available"`
+	Name      *string      `json:"name,omitempty"`
+	Options   []*Attribute `json:"options"`
+	UnitPrice *money.Money `json:"unitPrice,omitempty"`
+	Total     *money.Money `json:"total,omitempty"`
+}
+
+type Category struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name"`
+	ParentID *string `json:"parentId,omitempty"`
+}
+
+type CategoryFacet struct {
+	CategoryID string `json:"categoryId"`
+	Count      int    `json:"count"`
+}
+
+type CheckoutPayload struct {
+	Order *Order `json:"order"`
+	Cart  *Cart  `json:"cart"`
+}
+
+type Coupon struct {
+	Code        string       `json:"code"`
+	Description *string      `json:"description,omitempty"`
+	Kind        CouponKind   `json:"kind"`
+	PercentOff  *int         `json:"percentOff,omitempty"`
+	AmountOff   *money.Money `json:"amountOff,omitempty"`
+	BuyQuantity *int         `json:"buyQuantity,omitempty"`
+	GetQuantity *int         `json:"getQuantity,omitempty"`
+	CategoryIds []string     `json:"categoryIds"`
+	UsageLimit  *int         `json:"usageLimit,omitempty"`
+	UsageCount  int          `json:"usageCount"`
+	ExpiresAt   *time.Time   `json:"expiresAt,omitempty"`
+	CreatedAt   time.Time    `json:"createdAt"`
+}
+
+type CouponInput struct {
+	Code        string      `json:"code"`
+	Description *string     `json:"description,omitempty"`
+	Kind        CouponKind  `json:"kind"`
+	PercentOff  *int        `json:"percentOff,omitempty"`
+	AmountOff   *MoneyInput `json:"amountOff,omitempty"`
+	BuyQuantity *int        `json:"buyQuantity,omitempty"`
+	GetQuantity *int        `json:"getQuantity,omitempty"`
+	CategoryIds []string    `json:"categoryIds,omitempty"`
+	UsageLimit  *int        `json:"usageLimit,omitempty"`
+	ExpiresAt   *time.Time  `json:"expiresAt,omitempty"`
+}
+
+type Discount struct {
+	CouponCode string       `json:"couponCode"`
+	Amount     *money.Money `json:"amount"`
+}
+
+type FacetValue struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+type MoneyInput struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
 type Mutation struct {
 }
 
 type Order struct {
-	ID          string            `json:"id"`
-	CreatedAt   time.Time         `json:"createdAt"`
-	TotalAmount float64           `json:"totalAmount"`
-	Products    []*OrderedProduct `json:"products"`
+	ID              string               `json:"id"`
+	CreatedAt       time.Time            `json:"createdAt"`
+	SubtotalAmount  *money.Money         `json:"subtotalAmount"`
+	DiscountAmount  *money.Money         `json:"discountAmount"`
+	TaxAmount       *money.Money         `json:"taxAmount"`
+	TotalAmount     *money.Money         `json:"totalAmount"`
+	TaxRegion       *string              `json:"taxRegion,omitempty"`
+	ShippingAddress *ShippingAddress     `json:"shippingAddress,omitempty"`
+	PaymentID       *string              `json:"paymentId,omitempty"`
+	CouponCodes     []string             `json:"couponCodes"`
+	Products        []*OrderedProduct    `json:"products"`
+	Status          OrderStatus          `json:"status"`
+	StatusHistory   []*OrderStatusChange `json:"statusHistory"`
+	Refunds         []*Refund            `json:"refunds"`
+	RefundedAmount  *money.Money         `json:"refundedAmount"`
 }
 
 type OrderInput struct {
-	AccountID string                 `json:"accountId"`
-	Products  []*OrderedProductInput `json:"products"`
+	AccountID         string                 `json:"accountId"`
+	Products          []*OrderedProductInput `json:"products"`
+	IdempotencyKey    *string                `json:"idempotencyKey,omitempty"`
+	CouponCodes       []string               `json:"couponCodes,omitempty"`
+	TaxRegion         *string                `json:"taxRegion,omitempty"`
+	ShippingAddressID *string                `json:"shippingAddressId,omitempty"`
+	ShippingAddress   *AddressInput          `json:"shippingAddress,omitempty"`
+	PaymentMethod     *string                `json:"paymentMethod,omitempty"`
+}
+
+type OrderStatusChange struct {
+	Status    OrderStatus `json:"status"`
+	ChangedAt time.Time   `json:"changedAt"`
 }
 
 type OrderedProduct struct {
-	ID          string  `json:"id"`
-	Name        *string `json:"name,omitempty"`
-	Price       float64 `json:"price"`
-	Description *string `json:"description,omitempty"`
-	Quantity    int     `json:"quantity"`
+	ID          string       `json:"id"`
+	Sku         *string      `json:"sku,omitempty"`
+	Options     []*Attribute `json:"options"`
+	Name        *string      `json:"name,omitempty"`
+	Price       *money.Money `json:"price"`
+	Description *string      `json:"description,omitempty"`
+	Quantity    int          `json:"quantity"`
+	Discounts   []*Discount  `json:"discounts"`
+	TaxAmount   *money.Money `json:"taxAmount"`
 }
 
 type OrderedProductInput struct {
-	ID       string `json:"id"`
-	Quantity int    `json:"quantity"`
+	ID       string  `json:"id"`
+	Sku      *string `json:"sku,omitempty"`
+	Quantity int     `json:"quantity"`
 }
 
 type PaginationInput struct {
@@ -43,18 +209,406 @@ type PaginationInput struct {
 	Take *int `json:"take,omitempty"`
 }
 
-type Product struct {
-	ID          string  `json:"id"`
-	Name        string  `json:"name"`
-	Description *string `json:"description,omitempty"`
-	Price       float64 `json:"price"`
+type PriceRangeFacet struct {
+	From  *money.Money `json:"from,omitempty"`
+	To    *money.Money `json:"to,omitempty"`
+	Count int          `json:"count"`
+}
+
+type ProductFacets struct {
+	Categories []*CategoryFacet   `json:"categories"`
+	Attributes []*AttributeFacet  `json:"attributes"`
+	Prices     []*PriceRangeFacet `json:"prices"`
 }
 
 type ProductInput struct {
-	Name        string  `json:"name"`
-	Description *string `json:"description,omitempty"`
-	Price       float64 `json:"price"`
+	Name        string            `json:"name"`
+	Description *string           `json:"description,omitempty"`
+	Price       *MoneyInput       `json:"price"`
+	Stock       *int              `json:"stock,omitempty"`
+	Status      *ProductStatus    `json:"status,omitempty"`
+	CategoryIds []string          `json:"categoryIds,omitempty"`
+	Attributes  []*AttributeInput `json:"attributes,omitempty"`
+	Variants    []*VariantInput   `json:"variants,omitempty"`
+	TaxClass    *string           `json:"taxClass,omitempty"`
+}
+
+type ProductSearchInput struct {
+	Query           *string                 `json:"query,omitempty"`
+	CategoryID      *string                 `json:"categoryId,omitempty"`
+	MinPrice        *MoneyInput             `json:"minPrice,omitempty"`
+	MaxPrice        *MoneyInput             `json:"maxPrice,omitempty"`
+	Attributes      []*AttributeFilterInput `json:"attributes,omitempty"`
+	Sort            *ProductSort            `json:"sort,omitempty"`
+	Pagination      *PaginationInput        `json:"pagination,omitempty"`
+	IncludeInactive *bool                   `json:"includeInactive,omitempty"`
+}
+
+type ProductSearchResult struct {
+	Hits   []*Product     `json:"hits"`
+	Total  int            `json:"total"`
+	Facets *ProductFacets `json:"facets"`
+}
+
+type ProductUpdateInput struct {
+	Version     *string           `json:"version,omitempty"`
+	Name        *string           `json:"name,omitempty"`
+	Description *string           `json:"description,omitempty"`
+	Price       *MoneyInput       `json:"price,omitempty"`
+	Stock       *int              `json:"stock,omitempty"`
+	Status      *ProductStatus    `json:"status,omitempty"`
+	CategoryIds []string          `json:"categoryIds,omitempty"`
+	Attributes  []*AttributeInput `json:"attributes,omitempty"`
+	Variants    []*VariantInput   `json:"variants,omitempty"`
+	TaxClass    *string           `json:"taxClass,omitempty"`
 }
 
 type Query struct {
 }
+
+type Refund struct {
+	ID        string        `json:"id"`
+	Reason    *string       `json:"reason,omitempty"`
+	CreatedAt time.Time     `json:"createdAt"`
+	Amount    *money.Money  `json:"amount"`
+	Lines     []*RefundLine `json:"lines"`
+	Pending   bool          `json:"pending"`
+}
+
+type RefundLine struct {
+	ProductID string       `json:"productId"`
+	Sku       *string      `json:"sku,omitempty"`
+	Quantity  int          `json:"quantity"`
+	Amount    *money.Money `json:"amount"`
+}
+
+type RefundLineInput struct {
+	ProductID string  `json:"productId"`
+	Sku       *string `json:"sku,omitempty"`
+	Quantity  int     `json:"quantity"`
+}
+
+type RegisterInput struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type ShippingAddress struct {
+	AddressID  *string `json:"addressId,omitempty"`
+	Name       string  `json:"name"`
+	Line1      string  `json:"line1"`
+	Line2      *string `json:"line2,omitempty"`
+	City       string  `json:"city"`
+	Region     *string `json:"region,omitempty"`
+	PostalCode *string `json:"postalCode,omitempty"`
+	Country    string  `json:"country"`
+	Phone      *string `json:"phone,omitempty"`
+}
+
+type Variant struct {
+	Sku     string       `json:"sku"`
+	Options []*Attribute `json:"options"`
+	Price   *money.Money `json:"price"`
+	Stock   int          `json:"stock"`
+}
+
+type VariantInput struct {
+	Sku     string            `json:"sku"`
+	Options []*AttributeInput `json:"options"`
+	Price   *MoneyInput       `json:"price,omitempty"`
+	Stock   *int              `json:"stock,omitempty"`
+}
+
+type CouponKind string
+
+const (
+	CouponKindPercentage  CouponKind = "PERCENTAGE"
+	CouponKindFixedAmount CouponKind = "FIXED_AMOUNT"
+	CouponKindBuyXGetY    CouponKind = "BUY_X_GET_Y"
+)
+
+var AllCouponKind = []CouponKind{
+	CouponKindPercentage,
+	CouponKindFixedAmount,
+	CouponKindBuyXGetY,
+}
+
+func (e CouponKind) IsValid() bool {
+	switch e {
+	case CouponKindPercentage, CouponKindFixedAmount, CouponKindBuyXGetY:
+		return true
+	}
+	return false
+}
+
+func (e CouponKind) String() string {
+	return string(e)
+}
+
+func (e *CouponKind) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = CouponKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid CouponKind", str)
+	}
+	return nil
+}
+
+func (e CouponKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *CouponKind) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e CouponKind) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type OrderStatus string
+
+const (
+	OrderStatusPending   OrderStatus = "PENDING"
+	OrderStatusPaid      OrderStatus = "PAID"
+	OrderStatusShipping  OrderStatus = "SHIPPING"
+	OrderStatusShipped   OrderStatus = "SHIPPED"
+	OrderStatusDelivered OrderStatus = "DELIVERED"
+	OrderStatusCancelled OrderStatus = "CANCELLED"
+)
+
+var AllOrderStatus = []OrderStatus{
+	OrderStatusPending,
+	OrderStatusPaid,
+	OrderStatusShipping,
+	OrderStatusShipped,
+	OrderStatusDelivered,
+	OrderStatusCancelled,
+}
+
+func (e OrderStatus) IsValid() bool {
+	switch e {
+	case OrderStatusPending, OrderStatusPaid, OrderStatusShipping, OrderStatusShipped, OrderStatusDelivered, OrderStatusCancelled:
+		return true
+	}
+	return false
+}
+
+func (e OrderStatus) String() string {
+	return string(e)
+}
+
+func (e *OrderStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = OrderStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid OrderStatus", str)
+	}
+	return nil
+}
+
+func (e OrderStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *OrderStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e OrderStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ProductSort string
+
+const (
+	ProductSortRelevance ProductSort = "RELEVANCE"
+	ProductSortPriceAsc  ProductSort = "PRICE_ASC"
+	ProductSortPriceDesc ProductSort = "PRICE_DESC"
+	ProductSortNewest    ProductSort = "NEWEST"
+)
+
+var AllProductSort = []ProductSort{
+	ProductSortRelevance,
+	ProductSortPriceAsc,
+	ProductSortPriceDesc,
+	ProductSortNewest,
+}
+
+func (e ProductSort) IsValid() bool {
+	switch e {
+	case ProductSortRelevance, ProductSortPriceAsc, ProductSortPriceDesc, ProductSortNewest:
+		return true
+	}
+	return false
+}
+
+func (e ProductSort) String() string {
+	return string(e)
+}
+
+func (e *ProductSort) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ProductSort(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ProductSort", str)
+	}
+	return nil
+}
+
+func (e ProductSort) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ProductSort) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ProductSort) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ProductStatus string
+
+const (
+	ProductStatusDraft    ProductStatus = "DRAFT"
+	ProductStatusActive   ProductStatus = "ACTIVE"
+	ProductStatusArchived ProductStatus = "ARCHIVED"
+)
+
+var AllProductStatus = []ProductStatus{
+	ProductStatusDraft,
+	ProductStatusActive,
+	ProductStatusArchived,
+}
+
+func (e ProductStatus) IsValid() bool {
+	switch e {
+	case ProductStatusDraft, ProductStatusActive, ProductStatusArchived:
+		return true
+	}
+	return false
+}
+
+func (e ProductStatus) String() string {
+	return string(e)
+}
+
+func (e *ProductStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ProductStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ProductStatus", str)
+	}
+	return nil
+}
+
+func (e ProductStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ProductStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ProductStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type Role string
+
+const (
+	RoleCustomer     Role = "CUSTOMER"
+	RoleMerchandiser Role = "MERCHANDISER"
+	RoleAdmin        Role = "ADMIN"
+)
+
+var AllRole = []Role{
+	RoleCustomer,
+	RoleMerchandiser,
+	RoleAdmin,
+}
+
+func (e Role) IsValid() bool {
+	switch e {
+	case RoleCustomer, RoleMerchandiser, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+func (e Role) String() string {
+	return string(e)
+}
+
+func (e *Role) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Role(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Role", str)
+	}
+	return nil
+}
+
+func (e Role) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *Role) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e Role) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
@@ -3,7 +3,10 @@ package main
 import (
 	"context"
 	"errors"
+	"microservice/catalog"
+	"microservice/money"
 	"microservice/order"
+	"strconv"
 	"time"
 )
 
@@ -25,7 +28,148 @@ func (r *mutationResolver) CreateAccount(ctx context.Context, input AccountInput
 	if err != nil {
 		return nil, err
 	}
-	return &Account{ID: account.ID, Username: account.Name}, nil
+	return toGraphQLAccount(account), nil
+}
+
+func (r *mutationResolver) UpdateAccount(ctx context.Context, id string, input AccountInput) (*Account, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	if err := authorizeAccount(ctx, id); err != nil {
+		return nil, err
+	}
+	if id == "" || input.Username == "" {
+		return nil, ErrValidParameters
+	}
+	account, err := r.server.accountClient.UpdateAccount(ctx, id, input.Username)
+	if err != nil {
+		return nil, err
+	}
+	return toGraphQLAccount(account), nil
+}
+
+func (r *mutationResolver) DeleteAccount(ctx context.Context, id string) (*Account, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	if err := authorizeAccount(ctx, id); err != nil {
+		return nil, err
+	}
+	if id == "" {
+		return nil, ErrValidParameters
+	}
+	account, err := r.server.accountClient.DeleteAccount(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return toGraphQLAccount(account), nil
+}
+
+func (r *mutationResolver) SetAccountRoles(ctx context.Context, id string, roles []Role) (*Account, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	if id == "" {
+		return nil, ErrValidParameters
+	}
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		if !role.IsValid() {
+			return nil, ErrValidParameters
+		}
+		names = append(names, fromGraphQLRole(role))
+	}
+	account, err := r.server.accountClient.SetAccountRoles(ctx, id, names)
+	if err != nil {
+		return nil, err
+	}
+	return toGraphQLAccount(account), nil
+}
+
+func (r *mutationResolver) Register(ctx context.Context, input RegisterInput) (*AuthPayload, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	if input.Username == "" || input.Email == "" || input.Password == "" {
+		return nil, ErrValidParameters
+	}
+	account, tokens, err := r.server.accountClient.Register(ctx, input.Username, input.Email, input.Password)
+	if err != nil {
+		return nil, err
+	}
+	return toGraphQLAuthPayload(account, tokens), nil
+}
+
+func (r *mutationResolver) Login(ctx context.Context, email string, password string) (*AuthPayload, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	if email == "" || password == "" {
+		return nil, ErrValidParameters
+	}
+	account, tokens, err := r.server.accountClient.Login(ctx, email, password)
+	if err != nil {
+		return nil, err
+	}
+	return toGraphQLAuthPayload(account, tokens), nil
+}
+
+func (r *mutationResolver) RefreshToken(ctx context.Context, refreshToken string) (*AuthPayload, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	if refreshToken == "" {
+		return nil, ErrValidParameters
+	}
+	account, tokens, err := r.server.accountClient.RefreshToken(ctx, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	return toGraphQLAuthPayload(account, tokens), nil
+}
+
+func (r *mutationResolver) CreateAddress(ctx context.Context, accountID string, input AddressInput) (*Address, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	if err := authorizeAccount(ctx, accountID); err != nil {
+		return nil, err
+	}
+	if accountID == "" {
+		return nil, ErrValidParameters
+	}
+	address, err := r.server.accountClient.CreateAddress(ctx, accountID, fromGraphQLAddress(input))
+	if err != nil {
+		return nil, err
+	}
+	return toGraphQLAddress(address), nil
+}
+
+func (r *mutationResolver) UpdateAddress(ctx context.Context, accountID string, id string, input AddressInput) (*Address, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	if err := authorizeAccount(ctx, accountID); err != nil {
+		return nil, err
+	}
+	if accountID == "" || id == "" {
+		return nil, ErrValidParameters
+	}
+	update := fromGraphQLAddress(input)
+	update.ID = id
+	address, err := r.server.accountClient.UpdateAddress(ctx, accountID, update)
+	if err != nil {
+		return nil, err
+	}
+	return toGraphQLAddress(address), nil
+}
+
+func (r *mutationResolver) DeleteAddress(ctx context.Context, accountID string, id string) (*Address, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	if err := authorizeAccount(ctx, accountID); err != nil {
+		return nil, err
+	}
+	if accountID == "" || id == "" {
+		return nil, ErrValidParameters
+	}
+	address, err := r.server.accountClient.DeleteAddress(ctx, accountID, id)
+	if err != nil {
+		return nil, err
+	}
+	return toGraphQLAddress(address), nil
 }
 
 func (r *mutationResolver) CreateProduct(ctx context.Context, input ProductInput) (*Product, error) {
@@ -35,24 +179,206 @@ func (r *mutationResolver) CreateProduct(ctx context.Context, input ProductInput
 	if input.Description != nil {
 		description = *input.Description
 	}
-	if input.Name == "" || input.Price <= 0 {
+	if input.Name == "" || input.Price == nil {
+		return nil, ErrValidParameters
+	}
+	price, err := money.Parse(input.Price.Amount, input.Price.Currency)
+	if err != nil || price.IsNegative() || price.IsZero() {
+		return nil, ErrValidParameters
+	}
+	var stock int
+	if input.Stock != nil {
+		stock = *input.Stock
+	}
+	if stock < 0 {
+		return nil, ErrValidParameters
+	}
+	var status catalog.ProductStatus
+	if input.Status != nil {
+		if !input.Status.IsValid() {
+			return nil, ErrValidParameters
+		}
+		status = fromGraphQLProductStatus(*input.Status)
+	}
+	attributes, err := fromGraphQLAttributes(input.Attributes)
+	if err != nil {
+		return nil, err
+	}
+	variants, err := fromGraphQLVariants(input.Variants, price.Currency)
+	if err != nil {
+		return nil, err
+	}
+	var taxClass string
+	if input.TaxClass != nil {
+		taxClass = *input.TaxClass
+	}
+	product, err := r.server.catalogClient.PostProduct(ctx, &catalog.Product{
+		Name:        input.Name,
+		Description: description,
+		Price:       price,
+		Stock:       stock,
+		Status:      status,
+		CategoryIDs: input.CategoryIds,
+		Attributes:  attributes,
+		Variants:    variants,
+		TaxClass:    taxClass,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toGraphQLProduct(product), nil
+}
+
+func (r *mutationResolver) UpdateProduct(ctx context.Context, id string, input ProductUpdateInput) (*Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	if id == "" {
+		return nil, ErrValidParameters
+	}
+	update := &catalog.Product{}
+	if input.Version != nil {
+		version, err := strconv.ParseInt(*input.Version, 10, 64)
+		if err != nil || version <= 0 {
+			return nil, ErrValidParameters
+		}
+		update.Version = version
+	}
+	var paths []string
+	if input.Name != nil {
+		if *input.Name == "" {
+			return nil, ErrValidParameters
+		}
+		update.Name = *input.Name
+		paths = append(paths, catalog.PathName)
+	}
+	if input.Description != nil {
+		update.Description = *input.Description
+		paths = append(paths, catalog.PathDescription)
+	}
+	if input.Price != nil {
+		price, err := money.Parse(input.Price.Amount, input.Price.Currency)
+		if err != nil || price.IsNegative() || price.IsZero() {
+			return nil, ErrValidParameters
+		}
+		update.Price = price
+		paths = append(paths, catalog.PathPrice)
+	}
+	if input.Stock != nil {
+		if *input.Stock < 0 {
+			return nil, ErrValidParameters
+		}
+		update.Stock = *input.Stock
+		paths = append(paths, catalog.PathStock)
+	}
+	if input.Status != nil {
+		if !input.Status.IsValid() {
+			return nil, ErrValidParameters
+		}
+		update.Status = fromGraphQLProductStatus(*input.Status)
+		paths = append(paths, catalog.PathStatus)
+	}
+	if input.CategoryIds != nil {
+		update.CategoryIDs = input.CategoryIds
+		paths = append(paths, catalog.PathCategories)
+	}
+	if input.Attributes != nil {
+		attributes, err := fromGraphQLAttributes(input.Attributes)
+		if err != nil {
+			return nil, err
+		}
+		update.Attributes = attributes
+		paths = append(paths, catalog.PathAttributes)
+	}
+	if input.Variants != nil {
+		// The currency is checked against the product's by the catalog
+		variants, err := fromGraphQLVariants(input.Variants, "")
+		if err != nil {
+			return nil, err
+		}
+		update.Variants = variants
+		paths = append(paths, catalog.PathVariants)
+	}
+	if input.TaxClass != nil {
+		update.TaxClass = *input.TaxClass
+		paths = append(paths, catalog.PathTaxClass)
+	}
+	if len(paths) == 0 {
 		return nil, ErrValidParameters
 	}
-	product, err := r.server.catalogClient.PostProduct(ctx, input.Name, description, input.Price)
+	product, err := r.server.catalogClient.UpdateProduct(ctx, id, update, paths)
 	if err != nil {
 		return nil, err
 	}
-	return &Product{
-		ID:          product.ID,
-		Name:        product.Name,
-		Description: &product.Description,
-		Price:       product.Price,
-	}, nil
+	return toGraphQLProduct(product), nil
+}
+
+func (r *mutationResolver) DeleteProduct(ctx context.Context, id string) (*Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	if id == "" {
+		return nil, ErrValidParameters
+	}
+	product, err := r.server.catalogClient.DeleteProduct(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return toGraphQLProduct(product), nil
+}
+
+func (r *mutationResolver) CreateCategory(ctx context.Context, name string, parentID *string) (*Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	if name == "" {
+		return nil, ErrValidParameters
+	}
+	var parent string
+	if parentID != nil {
+		parent = *parentID
+	}
+	category, err := r.server.catalogClient.CreateCategory(ctx, name, parent)
+	if err != nil {
+		return nil, err
+	}
+	return toGraphQLCategory(category), nil
+}
+
+func (r *mutationResolver) MoveCategory(ctx context.Context, id string, parentID *string) (*Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	if id == "" {
+		return nil, ErrValidParameters
+	}
+	var parent string
+	if parentID != nil {
+		parent = *parentID
+	}
+	category, err := r.server.catalogClient.MoveCategory(ctx, id, parent)
+	if err != nil {
+		return nil, err
+	}
+	return toGraphQLCategory(category), nil
+}
+
+func (r *mutationResolver) DeleteCategory(ctx context.Context, id string) (*Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	if id == "" {
+		return nil, ErrValidParameters
+	}
+	category, err := r.server.catalogClient.DeleteCategory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return toGraphQLCategory(category), nil
 }
 
 func (r *mutationResolver) CreateOrder(ctx context.Context, input OrderInput) (*Order, error) {
 	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
+	// Customers can only order for themselves
+	if err := authorizeAccount(ctx, input.AccountID); err != nil {
+		return nil, err
+	}
 	if input.AccountID == "" || len(input.Products) == 0 {
 		return nil, ErrValidParameters
 	}
@@ -61,30 +387,235 @@ func (r *mutationResolver) CreateOrder(ctx context.Context, input OrderInput) (*
 		if p.ID == "" || p.Quantity <= 0 {
 			return nil, ErrValidParameters
 		}
-		products = append(products, &order.OrderedProduct{
+		product := &order.OrderedProduct{
 			ProductID: p.ID,
 			Quantity:  p.Quantity,
-		})
+		}
+		if p.Sku != nil {
+			product.SKU = *p.Sku
+		}
+		products = append(products, product)
+	}
+
+	var idempotencyKey string
+	if input.IdempotencyKey != nil {
+		idempotencyKey = *input.IdempotencyKey
+	}
+
+	var taxRegion string
+	if input.TaxRegion != nil {
+		taxRegion = *input.TaxRegion
+	}
+
+	if input.ShippingAddressID != nil && input.ShippingAddress != nil {
+		return nil, ErrValidParameters
+	}
+	var shipping *order.Address
+	if input.ShippingAddressID != nil {
+		shipping = &order.Address{AddressID: *input.ShippingAddressID}
+	} else if input.ShippingAddress != nil {
+		shipping = fromGraphQLShippingAddress(input.ShippingAddress)
+	}
+
+	orderResult, err := r.server.orderClient.PostOrder(ctx, input.AccountID, products, input.CouponCodes, taxRegion, shipping, stringValue(input.PaymentMethod), idempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return toGraphQLOrder(orderResult), nil
+}
+
+func (r *mutationResolver) UpdateOrderStatus(ctx context.Context, orderID string, status OrderStatus) (*Order, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	if orderID == "" || !status.IsValid() {
+		return nil, ErrValidParameters
+	}
+	orderResult, err := r.server.orderClient.UpdateOrderStatus(ctx, orderID, fromGraphQLOrderStatus(status))
+	if err != nil {
+		return nil, err
+	}
+	return toGraphQLOrder(orderResult), nil
+}
+
+func (r *mutationResolver) CancelOrder(ctx context.Context, orderID string, reason *string) (*Order, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	if err := r.authorizeOrder(ctx, orderID); err != nil {
+		return nil, err
+	}
+	if orderID == "" {
+		return nil, ErrValidParameters
+	}
+	var cancelReason string
+	if reason != nil {
+		cancelReason = *reason
+	}
+	orderResult, err := r.server.orderClient.CancelOrder(ctx, orderID, cancelReason)
+	if err != nil {
+		return nil, err
+	}
+	return toGraphQLOrder(orderResult), nil
+}
+
+func (r *mutationResolver) RefundOrder(ctx context.Context, orderID string, lines []*RefundLineInput, reason *string) (*Order, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	if orderID == "" {
+		return nil, ErrValidParameters
+	}
+	var refundLines []*order.RefundLine
+	for _, l := range lines {
+		if l.ProductID == "" || l.Quantity <= 0 {
+			return nil, ErrValidParameters
+		}
+		line := &order.RefundLine{
+			ProductID: l.ProductID,
+			Quantity:  l.Quantity,
+		}
+		if l.Sku != nil {
+			line.SKU = *l.Sku
+		}
+		refundLines = append(refundLines, line)
+	}
+	var refundReason string
+	if reason != nil {
+		refundReason = *reason
+	}
+	orderResult, err := r.server.orderClient.RefundOrder(ctx, orderID, refundLines, refundReason)
+	if err != nil {
+		return nil, err
+	}
+	return toGraphQLOrder(orderResult), nil
+}
+
+func (r *mutationResolver) AddCartItem(ctx context.Context, accountID string, productID string, sku *string, quantity int) (*Cart, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	if err := authorizeAccount(ctx, accountID); err != nil {
+		return nil, err
+	}
+	if accountID == "" || productID == "" || quantity <= 0 {
+		return nil, ErrValidParameters
+	}
+	var variantSKU string
+	if sku != nil {
+		variantSKU = *sku
+	}
+	c, err := r.server.cartClient.AddItem(ctx, accountID, productID, variantSKU, quantity)
+	if err != nil {
+		return nil, err
+	}
+	return toGraphQLCart(c), nil
+}
+
+func (r *mutationResolver) UpdateCartItem(ctx context.Context, accountID string, productID string, sku *string, quantity int) (*Cart, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	if err := authorizeAccount(ctx, accountID); err != nil {
+		return nil, err
+	}
+	if accountID == "" || productID == "" || quantity < 0 {
+		return nil, ErrValidParameters
+	}
+	var variantSKU string
+	if sku != nil {
+		variantSKU = *sku
+	}
+	c, err := r.server.cartClient.UpdateItem(ctx, accountID, productID, variantSKU, quantity)
+	if err != nil {
+		return nil, err
+	}
+	return toGraphQLCart(c), nil
+}
+
+func (r *mutationResolver) RemoveCartItem(ctx context.Context, accountID string, productID string, sku *string) (*Cart, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	if err := authorizeAccount(ctx, accountID); err != nil {
+		return nil, err
+	}
+	if accountID == "" || productID == "" {
+		return nil, ErrValidParameters
+	}
+	var variantSKU string
+	if sku != nil {
+		variantSKU = *sku
 	}
+	c, err := r.server.cartClient.RemoveItem(ctx, accountID, productID, variantSKU)
+	if err != nil {
+		return nil, err
+	}
+	return toGraphQLCart(c), nil
+}
 
-	orderResult, err := r.server.orderClient.PostOrder(ctx, input.AccountID, products)
+func (r *mutationResolver) ClearCart(ctx context.Context, accountID string) (*Cart, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	if err := authorizeAccount(ctx, accountID); err != nil {
+		return nil, err
+	}
+	if accountID == "" {
+		return nil, ErrValidParameters
+	}
+	c, err := r.server.cartClient.ClearCart(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	return toGraphQLCart(c), nil
+}
+
+func (r *mutationResolver) CheckoutCart(ctx context.Context, accountID string, idempotencyKey *string, couponCodes []string, paymentMethod *string) (*CheckoutPayload, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	if err := authorizeAccount(ctx, accountID); err != nil {
+		return nil, err
+	}
+	if accountID == "" {
+		return nil, ErrValidParameters
+	}
+	var key string
+	if idempotencyKey != nil {
+		key = *idempotencyKey
+	}
+	placed, c, err := r.server.cartClient.CheckoutCart(ctx, accountID, couponCodes, stringValue(paymentMethod), key)
 	if err != nil {
 		return nil, err
 	}
+	return &CheckoutPayload{Order: toGraphQLOrder(placed), Cart: toGraphQLCart(c)}, nil
+}
 
-	// Convert order.Order to GraphQL Order type
-	var orderedProducts []*OrderedProduct
-	for _, p := range orderResult.Products {
-		orderedProducts = append(orderedProducts, &OrderedProduct{
-			ID:       p.ProductID,
-			Quantity: p.Quantity,
-		})
+func (r *mutationResolver) CreateCoupon(ctx context.Context, coupon CouponInput) (*Coupon, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	newCoupon, err := fromGraphQLCoupon(coupon)
+	if err != nil {
+		return nil, err
 	}
+	created, err := r.server.orderClient.CreateCoupon(ctx, newCoupon)
+	if err != nil {
+		return nil, err
+	}
+	return toGraphQLCoupon(created), nil
+}
 
-	return &Order{
-		ID:          orderResult.ID,
-		CreatedAt:   orderResult.CreatedAt,
-		TotalAmount: orderResult.Total,
-		Products:    orderedProducts,
-	}, nil
+// authorizeOrder fails unless the caller placed the order or is an admin.
+func (r *mutationResolver) authorizeOrder(ctx context.Context, orderID string) error {
+	caller, err := principal(ctx)
+	if err != nil {
+		return err
+	}
+	if caller.IsAdmin() {
+		return nil
+	}
+	orders, err := r.server.orderClient.GetOrderForAccount(ctx, caller.AccountID)
+	if err != nil {
+		return err
+	}
+	for _, o := range orders {
+		if o.ID == orderID {
+			return nil
+		}
+	}
+	return ErrForbidden
 }
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+type productResolver struct {
+	server *Server
+}
+
+func (r *productResolver) Categories(ctx context.Context, obj *Product) ([]*Category, error) {
+	if len(obj.CategoryIDs) == 0 {
+		return []*Category{}, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	categories, err := r.server.catalogClient.GetCategories(ctx, obj.CategoryIDs)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*Category, 0, len(categories))
+	for _, c := range categories {
+		result = append(result, toGraphQLCategory(c))
+	}
+	return result, nil
+}
@@ -2,23 +2,44 @@ package main
 
 import (
 	"context"
+	"strings"
 	"time"
+
+	"microservice/catalog"
+	"microservice/money"
 )
 
 type queryResolver struct {
 	server *Server
 }
 
-func (r *queryResolver) Accounts(ctx context.Context, pagination *PaginationInput, id *string) ([]*Account, error) {
+func (r *queryResolver) Accounts(ctx context.Context, pagination *PaginationInput, id *string, includeDeleted *bool) ([]*Account, error) {
 	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
+	caller, err := principal(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	if id != nil && *id != "" {
+		if !caller.CanAccessAccount(*id) {
+			return nil, ErrForbidden
+		}
 		account, err := r.server.accountClient.GetAccount(ctx, *id)
 		if err != nil {
 			return nil, err
 		}
-		return []*Account{&Account{ID: account.ID, Username: account.Name}}, nil
+		return []*Account{toGraphQLAccount(account)}, nil
+	}
+
+	// Only admins can list accounts; anyone else just sees their own
+	if !caller.IsAdmin() {
+		account, err := r.server.accountClient.GetAccount(ctx, caller.AccountID)
+		if err != nil {
+			return nil, err
+		}
+		return []*Account{toGraphQLAccount(account)}, nil
 	}
 
 	skip, take := 0, 10
@@ -31,33 +52,29 @@ func (r *queryResolver) Accounts(ctx context.Context, pagination *PaginationInpu
 		}
 	}
 
-	accounts, err := r.server.accountClient.GetAccounts(ctx, skip, take)
+	accounts, err := r.server.accountClient.GetAccounts(ctx, skip, take, includeDeleted != nil && *includeDeleted)
 	if err != nil {
 		return nil, err
 	}
 
 	var result []*Account
 	for _, a := range accounts {
-		result = append(result, &Account{ID: a.ID, Username: a.Name})
+		result = append(result, toGraphQLAccount(a))
 	}
 	return result, nil
 }
 
-func (r *queryResolver) Products(ctx context.Context, pagination *PaginationInput, query *string, id *string) ([]*Product, error) {
+func (r *queryResolver) Products(ctx context.Context, pagination *PaginationInput, query *string, id *string, categoryID *string, includeInactive *bool) ([]*Product, error) {
 	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
+	inactive := includeInactive != nil && *includeInactive
 	if id != nil && *id != "" {
-		product, err := r.server.catalogClient.GetProduct(ctx, *id)
+		product, err := r.server.catalogClient.GetProduct(ctx, *id, inactive)
 		if err != nil {
 			return nil, err
 		}
-		return []*Product{&Product{
-			ID:          product.ID,
-			Name:        product.Name,
-			Description: &product.Description,
-			Price:       product.Price,
-		}}, nil
+		return []*Product{toGraphQLProduct(product)}, nil
 	}
 
 	skip, take := 0, 10
@@ -75,19 +92,155 @@ func (r *queryResolver) Products(ctx context.Context, pagination *PaginationInpu
 		searchQuery = *query
 	}
 
-	products, err := r.server.catalogClient.GetProducts(ctx, []string{}, searchQuery, skip, take)
+	var category string
+	if categoryID != nil {
+		category = *categoryID
+	}
+
+	products, err := r.server.catalogClient.GetProducts(ctx, []string{}, searchQuery, skip, take, category, inactive)
 	if err != nil {
 		return nil, err
 	}
 
 	var result []*Product
 	for _, p := range products {
-		result = append(result, &Product{
-			ID:          p.ID,
-			Name:        p.Name,
-			Description: &p.Description,
-			Price:       p.Price,
-		})
+		result = append(result, toGraphQLProduct(p))
+	}
+	return result, nil
+}
+
+func (r *queryResolver) ProductSearch(ctx context.Context, input ProductSearchInput) (*ProductSearchResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	search := &catalog.ProductSearch{
+		Take:            10,
+		IncludeInactive: input.IncludeInactive != nil && *input.IncludeInactive,
+		WithFacets:      true,
+	}
+	if input.Query != nil {
+		search.Query = *input.Query
+	}
+	if input.CategoryID != nil {
+		search.CategoryID = *input.CategoryID
+	}
+	if input.Pagination != nil {
+		if input.Pagination.Skip != nil {
+			search.Skip = *input.Pagination.Skip
+		}
+		if input.Pagination.Take != nil {
+			search.Take = *input.Pagination.Take
+		}
+	}
+	if input.Sort != nil {
+		if !input.Sort.IsValid() {
+			return nil, ErrValidParameters
+		}
+		search.Sort = catalog.SortOrder(strings.ToLower(input.Sort.String()))
+	}
+	for _, bound := range []struct {
+		input *MoneyInput
+		price **money.Money
+	}{{input.MinPrice, &search.MinPrice}, {input.MaxPrice, &search.MaxPrice}} {
+		if bound.input == nil {
+			continue
+		}
+		price, err := money.Parse(bound.input.Amount, bound.input.Currency)
+		if err != nil || price.IsNegative() {
+			return nil, ErrValidParameters
+		}
+		*bound.price = &price
+	}
+	if len(input.Attributes) > 0 {
+		search.Attributes = make(map[string][]string, len(input.Attributes))
+		for _, a := range input.Attributes {
+			if a.Name == "" || len(a.Values) == 0 {
+				return nil, ErrValidParameters
+			}
+			search.Attributes[a.Name] = append(search.Attributes[a.Name], a.Values...)
+		}
+	}
+
+	found, err := r.server.catalogClient.SearchProducts(ctx, search)
+	if err != nil {
+		return nil, err
+	}
+	hits := make([]*Product, 0, len(found.Products))
+	for _, p := range found.Products {
+		hits = append(hits, toGraphQLProduct(p))
+	}
+	return &ProductSearchResult{Hits: hits, Total: int(found.Total), Facets: toGraphQLFacets(found.Facets)}, nil
+}
+
+func (r *queryResolver) ProductSuggestions(ctx context.Context, prefix string, size *int) ([]*Product, error) {
+	// Suggestions are shown while the shopper types, so a slow answer is as
+	// good as none
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	if prefix == "" {
+		return nil, ErrValidParameters
+	}
+	var n int
+	if size != nil {
+		if *size < 0 {
+			return nil, ErrValidParameters
+		}
+		n = *size
+	}
+	products, err := r.server.catalogClient.SuggestProducts(ctx, prefix, n)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*Product, 0, len(products))
+	for _, p := range products {
+		result = append(result, toGraphQLProduct(p))
+	}
+	return result, nil
+}
+
+func (r *queryResolver) Categories(ctx context.Context) ([]*Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	categories, err := r.server.catalogClient.GetCategories(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*Category, 0, len(categories))
+	for _, c := range categories {
+		result = append(result, toGraphQLCategory(c))
+	}
+	return result, nil
+}
+
+func (r *queryResolver) Cart(ctx context.Context, accountID string) (*Cart, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	if err := authorizeAccount(ctx, accountID); err != nil {
+		return nil, err
+	}
+	if accountID == "" {
+		return nil, ErrValidParameters
+	}
+	c, err := r.server.cartClient.GetCart(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	return toGraphQLCart(c), nil
+}
+
+func (r *queryResolver) Coupons(ctx context.Context) ([]*Coupon, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	coupons, err := r.server.orderClient.GetCoupons(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*Coupon, 0, len(coupons))
+	for _, c := range coupons {
+		result = append(result, toGraphQLCoupon(c))
 	}
 	return result, nil
 }
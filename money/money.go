@@ -0,0 +1,157 @@
+// Package money represents monetary amounts exactly, as an integer number of a
+// currency's minor units, so prices and totals never suffer float rounding.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	moneypb "microservice/money/pb" // generated via: protoc -I money --go_out=paths=source_relative:money/pb money/money.proto
+)
+
+var (
+	ErrInvalidCurrency  = errors.New("invalid currency code")
+	ErrInvalidAmount    = errors.New("invalid amount")
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrOverflow         = errors.New("amount overflows")
+)
+
+// Money is an amount in a single currency, held in the currency's minor units
+// (e.g. cents for USD).
+type Money struct {
+	Currency string `json:"currency"`
+	Units    int64  `json:"units"`
+}
+
+// exponents lists currencies whose minor unit is not 1/100 of the major unit.
+var exponents = map[string]int{
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0,
+	"KRW": 0, "PYG": 0, "RWF": 0, "UGX": 0, "VND": 0, "VUV": 0, "XAF": 0,
+	"XOF": 0, "XPF": 0,
+}
+
+// New returns an amount of units minor units of currency.
+func New(units int64, currency string) Money {
+	return Money{Currency: currency, Units: units}
+}
+
+// Exponent returns the number of decimal places of the currency's minor unit.
+func Exponent(currency string) int {
+	if e, ok := exponents[currency]; ok {
+		return e
+	}
+	return 2
+}
+
+// ValidCurrency reports whether code looks like an ISO 4217 currency code.
+func ValidCurrency(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// Parse converts a decimal string such as "12.34" into an amount of currency.
+// It rejects amounts with more decimal places than the currency allows rather
+// than rounding them.
+func Parse(amount, currency string) (Money, error) {
+	if !ValidCurrency(currency) {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidCurrency, currency)
+	}
+	s := strings.TrimSpace(amount)
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	whole, frac, _ := strings.Cut(s, ".")
+	exp := Exponent(currency)
+	if whole == "" || len(frac) > exp || strings.ContainsAny(whole+frac, "+-") {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, amount)
+	}
+	frac += strings.Repeat("0", exp-len(frac))
+
+	units, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, amount)
+	}
+	if negative {
+		units = -units
+	}
+	return New(units, currency), nil
+}
+
+// Add returns m + o. Both amounts must be in the same currency.
+func (m Money) Add(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+	sum := m.Units + o.Units
+	if (o.Units > 0 && sum < m.Units) || (o.Units < 0 && sum > m.Units) {
+		return Money{}, ErrOverflow
+	}
+	return New(sum, m.Currency), nil
+}
+
+// Mul returns m multiplied by a quantity.
+func (m Money) Mul(quantity int64) (Money, error) {
+	if quantity != 0 && (m.Units > math.MaxInt64/quantity || m.Units < math.MinInt64/quantity) {
+		return Money{}, ErrOverflow
+	}
+	return New(m.Units*quantity, m.Currency), nil
+}
+
+// IsZero reports whether the amount is zero.
+func (m Money) IsZero() bool {
+	return m.Units == 0
+}
+
+// IsNegative reports whether the amount is below zero.
+func (m Money) IsNegative() bool {
+	return m.Units < 0
+}
+
+// Decimal formats the amount as a decimal string in major units, e.g. "12.34".
+func (m Money) Decimal() string {
+	units := m.Units
+	sign := ""
+	if units < 0 {
+		sign = "-"
+		units = -units
+	}
+	s := strconv.FormatUint(uint64(units), 10)
+	exp := Exponent(m.Currency)
+	if exp == 0 {
+		return sign + s
+	}
+	if len(s) <= exp {
+		s = strings.Repeat("0", exp-len(s)+1) + s
+	}
+	return sign + s[:len(s)-exp] + "." + s[len(s)-exp:]
+}
+
+// String formats the amount with its currency, e.g. "12.34 USD".
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+// ToProto converts m to its protobuf form.
+func ToProto(m Money) *moneypb.Money {
+	return &moneypb.Money{CurrencyCode: m.Currency, Units: m.Units}
+}
+
+// FromProto converts a protobuf amount to Money. A nil message is a zero amount
+// with no currency.
+func FromProto(p *moneypb.Money) Money {
+	if p == nil {
+		return Money{}
+	}
+	return New(p.Units, p.CurrencyCode)
+}
@@ -0,0 +1,115 @@
+package money
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		amount   string
+		currency string
+		want     Money
+		wantErr  error
+	}{
+		{"12.34", "USD", New(1234, "USD"), nil},
+		{"12.3", "USD", New(1230, "USD"), nil},
+		{"12", "USD", New(1200, "USD"), nil},
+		{" 0.05 ", "EUR", New(5, "EUR"), nil},
+		{"-1.50", "USD", New(-150, "USD"), nil},
+		{"1500", "JPY", New(1500, "JPY"), nil},
+		{"1.234", "KWD", New(1234, "KWD"), nil},
+		{"1.234", "USD", Money{}, ErrInvalidAmount},
+		{"1.5", "JPY", Money{}, ErrInvalidAmount},
+		{".50", "USD", Money{}, ErrInvalidAmount},
+		{"1.-5", "USD", Money{}, ErrInvalidAmount},
+		{"+1", "USD", Money{}, ErrInvalidAmount},
+		{"abc", "USD", Money{}, ErrInvalidAmount},
+		{"92233720368547758.08", "USD", Money{}, ErrInvalidAmount},
+		{"1", "usd", Money{}, ErrInvalidCurrency},
+		{"1", "US", Money{}, ErrInvalidCurrency},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.amount, tt.currency)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("Parse(%q, %q) error = %v, want %v", tt.amount, tt.currency, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q, %q) = %v, want %v", tt.amount, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestAdd(t *testing.T) {
+	tests := []struct {
+		name    string
+		m, o    Money
+		want    Money
+		wantErr error
+	}{
+		{"sum", New(150, "USD"), New(250, "USD"), New(400, "USD"), nil},
+		{"negative", New(150, "USD"), New(-250, "USD"), New(-100, "USD"), nil},
+		{"currency mismatch", New(150, "USD"), New(150, "EUR"), Money{}, ErrCurrencyMismatch},
+		{"overflow", New(math.MaxInt64, "USD"), New(1, "USD"), Money{}, ErrOverflow},
+		{"underflow", New(math.MinInt64, "USD"), New(-1, "USD"), Money{}, ErrOverflow},
+	}
+	for _, tt := range tests {
+		got, err := tt.m.Add(tt.o)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMul(t *testing.T) {
+	tests := []struct {
+		name     string
+		m        Money
+		quantity int64
+		want     Money
+		wantErr  error
+	}{
+		{"quantity", New(1999, "USD"), 3, New(5997, "USD"), nil},
+		{"zero", New(1999, "USD"), 0, New(0, "USD"), nil},
+		{"negative amount", New(-5, "USD"), 4, New(-20, "USD"), nil},
+		{"largest", New(math.MaxInt64/2, "USD"), 2, New(math.MaxInt64/2*2, "USD"), nil},
+		{"overflow", New(math.MaxInt64/2+1, "USD"), 2, Money{}, ErrOverflow},
+		{"underflow", New(math.MinInt64/2-1, "USD"), 2, Money{}, ErrOverflow},
+	}
+	for _, tt := range tests {
+		got, err := tt.m.Mul(tt.quantity)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	tests := []struct {
+		m    Money
+		want string
+	}{
+		{New(1234, "USD"), "12.34"},
+		{New(5, "USD"), "0.05"},
+		{New(0, "USD"), "0.00"},
+		{New(-150, "USD"), "-1.50"},
+		{New(1500, "JPY"), "1500"},
+		{New(1234, "KWD"), "1.234"},
+		{New(math.MinInt64, "USD"), "-92233720368547758.08"},
+	}
+	for _, tt := range tests {
+		if got := tt.m.Decimal(); got != tt.want {
+			t.Errorf("%d %s: Decimal() = %q, want %q", tt.m.Units, tt.m.Currency, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package order
+
+import (
+	"fmt"
+	"strings"
+
+	"microservice/account"
+)
+
+// Address is where an order ships to. It is copied onto the order when the
+// order is placed, so later changes to the account's address book do not
+// affect it.
+type Address struct {
+	// AddressID is the address book entry the address was copied from, if any.
+	AddressID  string `json:"address_id,omitempty"`
+	Name       string `json:"name"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2,omitempty"`
+	City       string `json:"city"`
+	Region     string `json:"region,omitempty"`
+	PostalCode string `json:"postal_code,omitempty"`
+	Country    string `json:"country"`
+	Phone      string `json:"phone,omitempty"`
+
+	// defaulted is set on the account's default shipping address when the
+	// order named none, so that it is left out of the request hash. Not
+	// persisted.
+	defaulted bool
+}
+
+// addressFromAccount snapshots an entry of an account's address book.
+func addressFromAccount(a *account.Address) *Address {
+	return &Address{
+		AddressID:  a.ID,
+		Name:       a.Name,
+		Line1:      a.Line1,
+		Line2:      a.Line2,
+		City:       a.City,
+		Region:     a.Region,
+		PostalCode: a.PostalCode,
+		Country:    a.Country,
+		Phone:      a.Phone,
+	}
+}
+
+// normalizeShippingAddress returns a trimmed copy of an address given with an
+// order, or nil without one. Addresses given in full must be complete; ones
+// referring to the address book only need their ID until they are resolved.
+func normalizeShippingAddress(a *Address) (*Address, error) {
+	if a == nil {
+		return nil, nil
+	}
+	n := *a
+	for _, field := range []*string{&n.AddressID, &n.Name, &n.Line1, &n.Line2, &n.City, &n.Region, &n.PostalCode, &n.Country, &n.Phone} {
+		*field = strings.TrimSpace(*field)
+	}
+	n.Country = strings.ToUpper(n.Country)
+	n.PostalCode = strings.ToUpper(n.PostalCode)
+	if n.AddressID != "" && n.Name == "" {
+		return &n, nil
+	}
+	if n.Name == "" || n.Line1 == "" || n.City == "" {
+		return nil, fmt.Errorf("%w: shipping address needs a name, first line and city", ErrInvalidOrder)
+	}
+	if len(n.Country) != 2 || strings.Trim(n.Country, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return nil, fmt.Errorf("%w: shipping country must be a two-letter ISO 3166-1 code", ErrInvalidOrder)
+	}
+	return &n, nil
+}
+
+// hashKey is what identifies the address in a request hash: the address book
+// entry it refers to, or else every field.
+func (a *Address) hashKey() string {
+	if a.AddressID != "" {
+		return a.AddressID
+	}
+	return strings.Join([]string{a.Name, a.Line1, a.Line2, a.City, a.Region, a.PostalCode, a.Country, a.Phone}, "\x1f")
+}
+
+// taxRegion is the region orders shipped to the address are taxed in: its
+// country, narrowed to a subdivision such as "US-CA" when the region is given
+// as a code.
+func (a *Address) taxRegion() string {
+	region := strings.ToUpper(a.Region)
+	if region != "" && len(region) <= 3 && strings.Trim(region, "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789") == "" {
+		return a.Country + "-" + region
+	}
+	return a.Country
+}
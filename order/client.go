@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"microservice/money"
 	pb "microservice/order/pb"
 
 	"google.golang.org/grpc"
@@ -28,43 +29,41 @@ func (c *Client) Close() error {
 	return c.conn.Close()
 }
 
-func (c *Client) PostOrder(ctx context.Context, accountID string, products []*OrderedProduct) (*Order, error) {
+// PostOrder places an order, applying the coupons in the order given and
+// taxing it in taxRegion, or else where it ships to. The order ships to
+// shipping, which may only give the AddressID of an address book entry, or
+// without one to the account's default shipping address. The total is
+// authorized on paymentMethod before the order is placed. A non-empty
+// idempotencyKey makes retries of the same request return the original order.
+func (c *Client) PostOrder(ctx context.Context, accountID string, products []*OrderedProduct, couponCodes []string, taxRegion string, shipping *Address, paymentMethod, idempotencyKey string) (*Order, error) {
 	protoProducts := []*pb.PostOrderRequest_OrderProduct{}
 	for _, p := range products {
 		protoProducts = append(protoProducts, &pb.PostOrderRequest_OrderProduct{
 			ProductId: p.ProductID,
+			Sku:       p.SKU,
 			Quantity:  uint32(p.Quantity),
 		})
 	}
 
-	resp, err := c.service.PostOrder(ctx, &pb.PostOrderRequest{
-		AccountId: accountID,
-		Products:  protoProducts,
-	})
+	req := &pb.PostOrderRequest{
+		AccountId:      accountID,
+		Products:       protoProducts,
+		IdempotencyKey: idempotencyKey,
+		CouponCodes:    couponCodes,
+		TaxRegion:      taxRegion,
+		PaymentMethod:  paymentMethod,
+	}
+	if shipping != nil && shipping.AddressID != "" {
+		req.ShippingAddressId = shipping.AddressID
+	} else if shipping != nil {
+		req.ShippingAddress = addressToProto(shipping)
+	}
+	resp, err := c.service.PostOrder(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	newOrder := resp.Order
-	newOrderCreatedAt := time.Time{}
-	newOrderCreatedAt.UnmarshalBinary(resp.Order.CreatedAt)
-
-	// Convert protobuf products back to OrderedProducts
-	var orderProducts []*OrderedProduct
-	for _, p := range newOrder.Products {
-		orderProducts = append(orderProducts, &OrderedProduct{
-			ProductID: p.Id,
-			Quantity:  int(p.Quantity),
-		})
-	}
-
-	return &Order{
-		ID:        resp.Order.Id,
-		AccountID: newOrder.AccountId,
-		Products:  orderProducts,
-		Total:     newOrder.Total,
-		CreatedAt: newOrderCreatedAt,
-	}, nil
+	return FromProto(resp.Order), nil
 }
 
 func (c *Client) GetOrderForAccount(ctx context.Context, accountID string) ([]*Order, error) {
@@ -77,25 +76,222 @@ func (c *Client) GetOrderForAccount(ctx context.Context, accountID string) ([]*O
 
 	orders := []*Order{}
 	for _, o := range resp.Orders {
-		newOrder := &Order{
-			ID:        o.Id,
-			AccountID: o.AccountId,
-			Total:     o.Total,
+		orders = append(orders, FromProto(o))
+	}
+
+	return orders, nil
+}
+
+func (c *Client) UpdateOrderStatus(ctx context.Context, orderID string, status OrderStatus) (*Order, error) {
+	resp, err := c.service.UpdateOrderStatus(ctx, &pb.UpdateOrderStatusRequest{
+		OrderId: orderID,
+		Status:  string(status),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return FromProto(resp.Order), nil
+}
+
+func (c *Client) CancelOrder(ctx context.Context, orderID, reason string) (*Order, error) {
+	resp, err := c.service.CancelOrder(ctx, &pb.CancelOrderRequest{
+		OrderId: orderID,
+		Reason:  reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return FromProto(resp.Order), nil
+}
+
+// RefundOrder refunds the given lines of an order. Passing no lines refunds
+// everything not yet refunded.
+func (c *Client) RefundOrder(ctx context.Context, orderID string, lines []*RefundLine, reason string) (*Order, error) {
+	protoLines := []*pb.RefundOrderRequest_Line{}
+	for _, l := range lines {
+		protoLines = append(protoLines, &pb.RefundOrderRequest_Line{
+			ProductId: l.ProductID,
+			Sku:       l.SKU,
+			Quantity:  uint32(l.Quantity),
+		})
+	}
+
+	resp, err := c.service.RefundOrder(ctx, &pb.RefundOrderRequest{
+		OrderId: orderID,
+		Lines:   protoLines,
+		Reason:  reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return FromProto(resp.Order), nil
+}
+
+// CreateCoupon stores a new coupon.
+func (c *Client) CreateCoupon(ctx context.Context, coupon *Coupon) (*Coupon, error) {
+	resp, err := c.service.CreateCoupon(ctx, &pb.CreateCouponRequest{Coupon: couponToProto(coupon)})
+	if err != nil {
+		return nil, err
+	}
+	return couponFromProto(resp.Coupon), nil
+}
+
+// GetCoupons returns every coupon, newest first.
+func (c *Client) GetCoupons(ctx context.Context) ([]*Coupon, error) {
+	resp, err := c.service.GetCoupons(ctx, &pb.GetCouponsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	coupons := make([]*Coupon, 0, len(resp.Coupons))
+	for _, coupon := range resp.Coupons {
+		coupons = append(coupons, couponFromProto(coupon))
+	}
+	return coupons, nil
+}
+
+// FromProto converts an order from its protobuf form.
+func FromProto(o *pb.Order) *Order {
+	newOrder := &Order{
+		ID:          o.Id,
+		AccountID:   o.AccountId,
+		Subtotal:    money.FromProto(o.Subtotal),
+		Tax:         money.FromProto(o.Tax),
+		Total:       money.FromProto(o.Total),
+		TaxRegion:   o.TaxRegion,
+		PaymentID:   o.PaymentId,
+		Status:      OrderStatus(o.Status),
+		CouponCodes: o.CouponCodes,
+	}
+	newOrderCreatedAt := time.Time{}
+	newOrderCreatedAt.UnmarshalBinary(o.CreatedAt)
+	newOrder.CreatedAt = newOrderCreatedAt
+	if o.ShippingAddress != nil {
+		newOrder.ShippingAddress = addressFromProto(o.ShippingAddress)
+	}
+
+	var products []*OrderedProduct
+	for _, p := range o.Products {
+		product := &OrderedProduct{
+			ProductID:   p.Id,
+			SKU:         p.Sku,
+			Name:        p.Name,
+			Description: p.Description,
+			Options:     p.Options,
+			Price:       money.FromProto(p.Price),
+			Quantity:    int(p.Quantity),
+			Tax:         money.FromProto(p.Tax),
 		}
-		newOrderCreatedAt := time.Time{}
-		newOrderCreatedAt.UnmarshalBinary(o.CreatedAt)
-		newOrder.CreatedAt = newOrderCreatedAt
-
-		var products []*OrderedProduct
-		for _, p := range o.Products {
-			products = append(products, &OrderedProduct{
-				ProductID: p.Id,
-				Quantity:  int(p.Quantity),
+		for _, d := range p.Discounts {
+			product.Discounts = append(product.Discounts, &Discount{
+				CouponCode: d.CouponCode,
+				Amount:     money.FromProto(d.Amount),
 			})
 		}
-		newOrder.Products = products
-		orders = append(orders, newOrder)
+		products = append(products, product)
 	}
+	newOrder.Products = products
 
-	return orders, nil
+	for _, c := range o.StatusHistory {
+		newOrder.StatusHistory = append(newOrder.StatusHistory, statusChangeFromProto(c))
+	}
+
+	for _, r := range o.Refunds {
+		newOrder.Refunds = append(newOrder.Refunds, refundFromProto(o.Id, r))
+	}
+	return newOrder
+}
+
+func couponFromProto(c *pb.Coupon) *Coupon {
+	coupon := &Coupon{
+		Code:        c.Code,
+		Description: c.Description,
+		Kind:        CouponKind(c.Kind),
+		PercentOff:  int(c.PercentOff),
+		BuyQuantity: int(c.BuyQuantity),
+		GetQuantity: int(c.GetQuantity),
+		CategoryIDs: c.CategoryIds,
+		UsageLimit:  int(c.UsageLimit),
+		UsageCount:  int(c.UsageCount),
+	}
+	if c.AmountOff != nil {
+		amountOff := money.FromProto(c.AmountOff)
+		coupon.AmountOff = &amountOff
+	}
+	if len(c.ExpiresAt) > 0 {
+		var expiresAt time.Time
+		if expiresAt.UnmarshalBinary(c.ExpiresAt) == nil {
+			coupon.ExpiresAt = &expiresAt
+		}
+	}
+	coupon.CreatedAt.UnmarshalBinary(c.CreatedAt)
+	return coupon
+}
+
+func statusChangeFromProto(c *pb.OrderStatusChange) *StatusChange {
+	change := &StatusChange{Status: OrderStatus(c.Status)}
+	change.ChangedAt.UnmarshalBinary(c.ChangedAt)
+	return change
+}
+
+func refundFromProto(orderID string, r *pb.Refund) *Refund {
+	refund := &Refund{ID: r.Id, OrderID: orderID, Reason: r.Reason, Pending: r.Pending}
+	refund.CreatedAt.UnmarshalBinary(r.CreatedAt)
+	for _, l := range r.Lines {
+		refund.Lines = append(refund.Lines, &RefundLine{
+			ProductID: l.ProductId,
+			SKU:       l.Sku,
+			Quantity:  int(l.Quantity),
+			Amount:    money.FromProto(l.Amount),
+		})
+	}
+	return refund
+}
+
+// SubscribeOrderEvents calls handle with every order event after afterOffset
+// and keeps doing so as new events are published, until ctx is done, the
+// stream breaks or handle fails. Events may be redelivered, so to resume
+// without gaps callers pass the offset of the last event they handled.
+func (c *Client) SubscribeOrderEvents(ctx context.Context, afterOffset uint64, handle func(*Event) error) error {
+	stream, err := c.service.SubscribeOrderEvents(ctx, &pb.SubscribeOrderEventsRequest{AfterOffset: afterOffset})
+	if err != nil {
+		return err
+	}
+	for {
+		e, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		event := &Event{
+			Offset:  e.Offset,
+			Type:    EventType(e.Type),
+			OrderID: e.OrderId,
+		}
+		event.OccurredAt.UnmarshalBinary(e.OccurredAt)
+		if e.Order != nil {
+			event.Order = FromProto(e.Order)
+		}
+		if e.StatusChange != nil {
+			event.StatusChange = statusChangeFromProto(e.StatusChange)
+		}
+		if e.Refund != nil {
+			event.Refund = refundFromProto(e.OrderId, e.Refund)
+		}
+		if err := handle(event); err != nil {
+			return err
+		}
+	}
+}
+
+func addressFromProto(a *pb.Address) *Address {
+	return &Address{
+		AddressID:  a.AddressId,
+		Name:       a.Name,
+		Line1:      a.Line1,
+		Line2:      a.Line2,
+		City:       a.City,
+		Region:     a.Region,
+		PostalCode: a.PostalCode,
+		Country:    a.Country,
+		Phone:      a.Phone,
+	}
 }
@@ -2,7 +2,10 @@ package main
 
 import (
 	"log"
+	"microservice/auth"
 	"microservice/order"
+	"microservice/payment"
+	"os"
 	"time"
 
 	githubenv "github.com/kelseyhightower/envconfig"
@@ -13,6 +16,14 @@ type Config struct {
 	DatabaseURL string `envconfig:"DATABASE_URL"`
 	AccountURL  string `envconfig:"ACCOUNT_SERVICE_URL"`
 	CatalogURL  string `envconfig:"CATALOG_SERVICE_URL"`
+	PaymentURL  string `envconfig:"PAYMENT_SERVICE_URL"`
+	// ServiceSecret signs the tokens the order service moves stock and payments
+	// with.
+	ServiceSecret string `envconfig:"SERVICE_SECRET" required:"true"`
+	// TaxRulesFile is a JSON array of tax rules; the built-in rules are used
+	// without one.
+	TaxRulesFile     string `envconfig:"TAX_RULES_FILE"`
+	TaxDefaultRegion string `envconfig:"TAX_DEFAULT_REGION"`
 }
 
 func main() {
@@ -25,6 +36,30 @@ func main() {
 	// 	panic("DATABASE_URL is required")
 	// }
 
+	rules := order.DefaultTaxRules
+	if cfg.TaxRulesFile != "" {
+		f, err := os.Open(cfg.TaxRulesFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		rules, err = order.LoadTaxRules(f)
+		f.Close()
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	tax, err := order.NewTableTaxCalculator(rules)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	signer := auth.NewServiceSigner([]byte(cfg.ServiceSecret))
+	payments, err := payment.NewServiceClient(cfg.PaymentURL, signer, "order")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer payments.Close()
+
 	var r order.Repository
 	retry.ForeverSleep(2*time.Second, func(_ int) (err error) {
 		r, err = order.NewPostgresRepository(cfg.DatabaseURL)
@@ -35,6 +70,6 @@ func main() {
 	})
 	defer r.Close()
 	log.Println("Listening on port 8080...")
-	s := order.NewOrderService(r)
-	log.Fatal(order.ListenGRPC(s, cfg.AccountURL, cfg.CatalogURL, 8080))
+	s := order.NewOrderService(r, tax, cfg.TaxDefaultRegion, payments)
+	log.Fatal(order.ListenGRPC(s, cfg.AccountURL, cfg.CatalogURL, signer, 8080))
 }
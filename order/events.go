@@ -0,0 +1,138 @@
+package order
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// EventType names a domain event emitted when an order changes.
+type EventType string
+
+const (
+	EventOrderPlaced        EventType = "OrderPlaced"
+	EventOrderStatusChanged EventType = "OrderStatusChanged"
+	EventOrderCancelled     EventType = "OrderCancelled"
+	EventOrderRefunded      EventType = "OrderRefunded"
+)
+
+const (
+	// relayInterval is how often the outbox is checked for new events.
+	relayInterval = 500 * time.Millisecond
+	// relayBatchSize caps the events moved out of the outbox at once.
+	relayBatchSize = 100
+	// subscriptionPollInterval is how often a caught-up subscriber checks for
+	// events published by other instances of the service.
+	subscriptionPollInterval = 2 * time.Second
+)
+
+// Event is a change to an order. It is written to the outbox in the same
+// transaction as the change itself, then published by the relay, which gives
+// it an Offset. Offsets increase in publication order, so a subscriber can
+// resume after the last offset it has processed.
+type Event struct {
+	Offset       uint64        `json:"-"`
+	Type         EventType     `json:"type"`
+	OrderID      string        `json:"order_id"`
+	OccurredAt   time.Time     `json:"occurred_at"`
+	Order        *Order        `json:"order,omitempty"`
+	StatusChange *StatusChange `json:"status_change,omitempty"`
+	Refund       *Refund       `json:"refund,omitempty"`
+}
+
+// eventRelay moves events from the outbox to the published event log and
+// wakes up subscribers waiting for new events.
+type eventRelay struct {
+	service Service
+
+	mu        sync.Mutex
+	published chan struct{}
+}
+
+func newEventRelay(service Service) *eventRelay {
+	return &eventRelay{service: service, published: make(chan struct{})}
+}
+
+// Run publishes outbox events at every interval, forever.
+func (r *eventRelay) Run(interval time.Duration) {
+	for {
+		// Keep draining while the outbox has a backlog
+		if r.relay() < relayBatchSize {
+			time.Sleep(interval)
+		}
+	}
+}
+
+// relay publishes a batch of outbox events, wakes up subscribers if there
+// were any, and returns how many were published.
+func (r *eventRelay) relay() int {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	n, err := r.service.RelayEvents(ctx, relayBatchSize)
+	if err != nil {
+		log.Printf("failed to relay order events: %v", err)
+	}
+	if n > 0 {
+		r.notify()
+	}
+	return n
+}
+
+func (r *eventRelay) notify() {
+	r.mu.Lock()
+	close(r.published)
+	r.published = make(chan struct{})
+	r.mu.Unlock()
+}
+
+// Published returns a channel that is closed the next time events are
+// published by this relay.
+func (r *eventRelay) Published() <-chan struct{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.published
+}
+
+// Subscribe calls send with every published event after the given offset, in
+// offset order, until ctx is done or send fails. Delivery is at least once:
+// a subscriber that resumes from the last offset it processed gets every
+// event after it.
+func (r *eventRelay) Subscribe(ctx context.Context, afterOffset uint64, send func(*Event) error) error {
+	ticker := time.NewTicker(subscriptionPollInterval)
+	defer ticker.Stop()
+	for {
+		// Grab the channel first so a publication during the query is not missed
+		published := r.Published()
+		events, err := r.service.ListEvents(ctx, afterOffset, relayBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			if err := send(event); err != nil {
+				return err
+			}
+			afterOffset = event.Offset
+		}
+		if len(events) == relayBatchSize {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-published:
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayEvents publishes up to limit events from the outbox and returns how many
+// were published.
+func (s *orderService) RelayEvents(ctx context.Context, limit int) (int, error) {
+	return s.repo.RelayEvents(ctx, limit)
+}
+
+// ListEvents returns up to limit published events after the given offset.
+func (s *orderService) ListEvents(ctx context.Context, afterOffset uint64, limit int) ([]*Event, error) {
+	return s.repo.ListEvents(ctx, afterOffset, limit)
+}
@@ -0,0 +1,114 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// outbox writes an event for each order ID to the outbox, as a change to the
+// order would.
+func (r *memoryRepository) outbox(orderIDs ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, id := range orderIDs {
+		r.pending = append(r.pending, &Event{Type: EventOrderPlaced, OrderID: id, OccurredAt: time.Now().UTC()})
+	}
+}
+
+func (r *memoryRepository) RelayEvents(ctx context.Context, limit int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := min(limit, len(r.pending))
+	for _, event := range r.pending[:n] {
+		event.Offset = uint64(len(r.events) + 1)
+		r.events = append(r.events, event)
+	}
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+func (r *memoryRepository) ListEvents(ctx context.Context, afterOffset uint64, limit int) ([]*Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if afterOffset >= uint64(len(r.events)) {
+		return nil, nil
+	}
+	events := r.events[afterOffset:]
+	return events[:min(limit, len(events))], nil
+}
+
+// subscribe streams events after afterOffset into a channel until the test
+// ends.
+func subscribe(t *testing.T, relay *eventRelay, afterOffset uint64) <-chan *Event {
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan *Event, 2*relayBatchSize)
+	done := make(chan error)
+	go func() {
+		done <- relay.Subscribe(ctx, afterOffset, func(event *Event) error {
+			events <- event
+			return nil
+		})
+	}()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; !errors.Is(err, context.Canceled) {
+			t.Errorf("Subscribe error = %v, want %v", err, context.Canceled)
+		}
+	})
+	return events
+}
+
+// receive expects events for the given orders, in order. It waits well below
+// subscriptionPollInterval, so only the relay can have woken the subscriber.
+func receive(t *testing.T, events <-chan *Event, wantOrderIDs ...string) {
+	t.Helper()
+	for _, want := range wantOrderIDs {
+		select {
+		case event := <-events:
+			if event.OrderID != want {
+				t.Fatalf("received event for %s, want %s", event.OrderID, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no event for %s", want)
+		}
+	}
+}
+
+func TestEventRelay(t *testing.T) {
+	repo := &memoryRepository{}
+	relay := newEventRelay(NewOrderService(repo, nil, "", nil))
+	events := subscribe(t, relay, 0)
+
+	repo.outbox("o1", "o2")
+	if n := relay.relay(); n != 2 {
+		t.Fatalf("relayed %d events, want 2", n)
+	}
+	receive(t, events, "o1", "o2")
+
+	repo.outbox("o3")
+	relay.relay()
+	receive(t, events, "o3")
+
+	// A subscriber resuming after the second event gets the third again
+	receive(t, subscribe(t, relay, 2), "o3")
+}
+
+func TestEventRelayDrainsBacklog(t *testing.T) {
+	repo := &memoryRepository{}
+	relay := newEventRelay(NewOrderService(repo, nil, "", nil))
+	ids := make([]string, relayBatchSize+1)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("o%d", i)
+	}
+	repo.outbox(ids...)
+
+	if n := relay.relay(); n != relayBatchSize {
+		t.Fatalf("relayed %d events, want a batch of %d", n, relayBatchSize)
+	}
+	relay.relay()
+	// Events published before subscribing are read in batches
+	receive(t, subscribe(t, relay, 0), ids...)
+}
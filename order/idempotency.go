@@ -0,0 +1,47 @@
+package order
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrIdempotencyKeyReused    = errors.New("idempotency key was already used for a different order")
+	ErrDuplicateIdempotencyKey = errors.New("duplicate idempotency key")
+)
+
+// requestHash fingerprints the payload of an order placement so that a replay
+// under the same idempotency key can be told apart from a different request.
+func requestHash(accountID string, products []*OrderedProduct, couponCodes []string, taxRegion string, shipping *Address) string {
+	lines := make([]string, 0, len(products))
+	for _, p := range products {
+		line := p.ProductID
+		if p.SKU != "" {
+			// Only lines with a SKU carry one, so orders placed before
+			// variants existed keep their hashes
+			line += "/" + p.SKU
+		}
+		lines = append(lines, fmt.Sprintf("%s:%d", line, p.Quantity))
+	}
+	sort.Strings(lines)
+
+	payload := accountID + "|" + strings.Join(lines, ",")
+	if len(couponCodes) > 0 {
+		// Coupons apply in order, so unlike lines they are not sorted. Orders
+		// without any keep the hashes they had before coupons existed.
+		payload += "|" + strings.Join(couponCodes, ",")
+	}
+	if taxRegion != "" {
+		// Only a region asked for is hashed, not the default one
+		payload += "|tax:" + taxRegion
+	}
+	if shipping != nil && !shipping.defaulted {
+		payload += "|ship:" + shipping.hashKey()
+	}
+	sum := sha256.Sum256([]byte(payload))
+	return hex.EncodeToString(sum[:])
+}
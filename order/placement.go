@@ -0,0 +1,370 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"microservice/account"
+	"microservice/catalog"
+
+	"github.com/segmentio/ksuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Steps of the order placement saga, in the order they run.
+const (
+	stepVerifyAccount = "verify_account"
+	stepPriceOrder    = "price_order"
+	stepReserveStock  = "reserve_stock"
+	stepAuthorize     = "authorize_payment"
+	stepPlaceOrder    = "place_order"
+)
+
+const (
+	// sagaTimeout is how long a saga may go without progress before it is
+	// considered abandoned by the process that was running it.
+	sagaTimeout = time.Minute
+	// recoveryInterval is how often abandoned sagas are looked for.
+	recoveryInterval = 30 * time.Second
+	// compensationTimeout bounds undoing a saga, which runs detached from the
+	// request that started it.
+	compensationTimeout = 10 * time.Second
+)
+
+var (
+	errSagaInterrupted = errors.New("order placement was interrupted")
+	// errOrderReplayed aborts a saga that lost a race with a concurrent
+	// request carrying the same idempotency key.
+	errOrderReplayed = errors.New("order was already placed under the idempotency key")
+)
+
+type sagaStep struct {
+	name string
+	run  func(ctx context.Context, saga *Saga) error
+	// compensate undoes run. It must be safe to call when run never took
+	// effect, since a crash can happen between recording a step and running it.
+	compensate func(ctx context.Context, saga *Saga) error
+}
+
+// accountDirectory is what placing an order needs of the account service, and
+// productCatalog what it needs of the catalog. Their clients satisfy them.
+type accountDirectory interface {
+	GetAccount(ctx context.Context, id string) (*account.Account, error)
+	GetAddresses(ctx context.Context, accountID string) ([]*account.Address, error)
+	GetAddress(ctx context.Context, accountID, id string) (*account.Address, error)
+}
+
+type productCatalog interface {
+	GetProduct(ctx context.Context, id string, includeInactive bool) (*catalog.Product, error)
+	GetCategories(ctx context.Context, ids []string) ([]*catalog.Category, error)
+	ReserveStock(ctx context.Context, reservationID string, items []*catalog.StockItem) error
+	ReleaseStock(ctx context.Context, reservationID string) error
+}
+
+// placementSaga places orders as a saga across the account, catalog and
+// payment services and the order database.
+type placementSaga struct {
+	service       Service
+	accountClient accountDirectory
+	catalogClient productCatalog
+}
+
+func (p *placementSaga) steps() []sagaStep {
+	return []sagaStep{
+		{name: stepVerifyAccount, run: p.verifyAccount},
+		{name: stepPriceOrder, run: p.priceOrder},
+		{name: stepReserveStock, run: p.reserveStock, compensate: p.releaseStock},
+		{name: stepAuthorize, run: p.authorizePayment, compensate: p.voidPayment},
+		{name: stepPlaceOrder, run: p.placeOrder},
+	}
+}
+
+// Place runs a new saga placing an order paid for with paymentMethod. If any
+// step fails, the steps taken so far are undone and the step's error is
+// returned.
+func (p *placementSaga) Place(ctx context.Context, accountID string, items []*SagaItem, couponCodes []string, taxRegion string, shipping *Address, paymentMethod, idempotencyKey string) (*Order, error) {
+	shipping, err := normalizeShippingAddress(shipping)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	saga := &Saga{
+		ID:              ksuid.New().String(),
+		AccountID:       accountID,
+		IdempotencyKey:  idempotencyKey,
+		Items:           items,
+		CouponCodes:     couponCodes,
+		TaxRegion:       taxRegion,
+		ShippingAddress: shipping,
+		State:           SagaRunning,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+		paymentMethod:   paymentMethod,
+	}
+	if err := p.service.StartSaga(ctx, saga); err != nil {
+		return nil, err
+	}
+
+	for _, step := range p.steps() {
+		err := p.service.RecordSagaStep(ctx, saga, step.name, StepStarted)
+		if err == nil {
+			err = step.run(ctx, saga)
+		}
+		if err == nil {
+			err = p.service.RecordSagaStep(ctx, saga, step.name, StepCompleted)
+		}
+		if err != nil {
+			// The request's context may be what failed, so settle on our own
+			abortCtx, cancel := context.WithTimeout(context.Background(), compensationTimeout)
+			order := p.abort(abortCtx, saga, err)
+			cancel()
+			if order != nil {
+				return order, nil
+			}
+			if errors.Is(err, errOrderReplayed) {
+				return saga.placed, nil
+			}
+			return nil, err
+		}
+	}
+
+	if err := p.service.SetSagaState(ctx, saga, SagaCompleted, ""); err != nil {
+		// The order is in place; recovery will find it and complete the saga
+		log.Printf("saga %s: failed to mark completed: %v", saga.ID, err)
+	}
+	return saga.placed, nil
+}
+
+// Recover settles sagas abandoned by a process that stopped while running them.
+func (p *placementSaga) Recover(ctx context.Context) {
+	sagas, err := p.service.StaleSagas(ctx, sagaTimeout)
+	if err != nil {
+		log.Printf("failed to list abandoned sagas: %v", err)
+		return
+	}
+	for _, saga := range sagas {
+		log.Printf("saga %s: recovering from state %s", saga.ID, saga.State)
+		if saga.State == SagaCompensating {
+			p.compensate(ctx, saga, saga.Error)
+			continue
+		}
+		p.abort(ctx, saga, errSagaInterrupted)
+	}
+}
+
+// RecoverEvery runs Recover now and then at every interval, forever.
+func (p *placementSaga) RecoverEvery(interval time.Duration) {
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		p.Recover(ctx)
+		cancel()
+		time.Sleep(interval)
+	}
+}
+
+// abort settles a saga that cannot go on. A saga whose order made it into the
+// database has in fact succeeded, so it is completed and the order returned;
+// any other saga is rolled back.
+func (p *placementSaga) abort(ctx context.Context, saga *Saga, cause error) *Order {
+	if _, started := saga.stepStatus(stepPlaceOrder); started {
+		order, err := p.service.GetOrder(ctx, saga.ID)
+		if err == nil {
+			if err := p.service.SetSagaState(ctx, saga, SagaCompleted, ""); err != nil {
+				log.Printf("saga %s: failed to mark completed: %v", saga.ID, err)
+			}
+			return order
+		}
+		if !errors.Is(err, ErrNotFound) {
+			// Can't tell whether the order exists; try again on recovery
+			log.Printf("saga %s: failed to look up order: %v", saga.ID, err)
+			return nil
+		}
+	}
+	p.compensate(ctx, saga, cause.Error())
+	return nil
+}
+
+// compensate undoes the started steps of a saga in reverse order. Steps that
+// were already compensated are skipped, so an interrupted rollback can simply
+// be run again.
+func (p *placementSaga) compensate(ctx context.Context, saga *Saga, reason string) {
+	if err := p.service.SetSagaState(ctx, saga, SagaCompensating, reason); err != nil {
+		log.Printf("saga %s: failed to start compensating: %v", saga.ID, err)
+		return
+	}
+	steps := p.steps()
+	for i := len(steps) - 1; i >= 0; i-- {
+		step := steps[i]
+		status, started := saga.stepStatus(step.name)
+		if step.compensate == nil || !started || status == StepCompensated {
+			continue
+		}
+		if err := step.compensate(ctx, saga); err != nil {
+			log.Printf("saga %s: failed to compensate %s: %v", saga.ID, step.name, err)
+			return
+		}
+		if err := p.service.RecordSagaStep(ctx, saga, step.name, StepCompensated); err != nil {
+			log.Printf("saga %s: failed to record compensation of %s: %v", saga.ID, step.name, err)
+			return
+		}
+	}
+	if err := p.service.SetSagaState(ctx, saga, SagaCompensated, reason); err != nil {
+		log.Printf("saga %s: failed to mark compensated: %v", saga.ID, err)
+	}
+}
+
+func (p *placementSaga) verifyAccount(ctx context.Context, saga *Saga) error {
+	acc, err := p.accountClient.GetAccount(ctx, saga.AccountID)
+	if err != nil {
+		return err
+	}
+	if acc.Deleted() {
+		return ErrAccountDeleted
+	}
+	return p.resolveShippingAddress(ctx, saga)
+}
+
+// resolveShippingAddress copies the address book entry the order refers to
+// onto the saga or, if the order gave no address, the account's default
+// shipping address if it has one.
+func (p *placementSaga) resolveShippingAddress(ctx context.Context, saga *Saga) error {
+	if saga.ShippingAddress != nil && saga.ShippingAddress.Name != "" {
+		return nil
+	}
+	if saga.ShippingAddress != nil {
+		entry, err := p.accountClient.GetAddress(ctx, saga.AccountID, saga.ShippingAddress.AddressID)
+		if status.Code(err) == codes.NotFound {
+			return fmt.Errorf("%w: shipping address %s not found", ErrInvalidOrder, saga.ShippingAddress.AddressID)
+		}
+		if err != nil {
+			return err
+		}
+		saga.ShippingAddress = addressFromAccount(entry)
+		return nil
+	}
+
+	entries, err := p.accountClient.GetAddresses(ctx, saga.AccountID)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.DefaultShipping {
+			saga.ShippingAddress = addressFromAccount(entry)
+			saga.ShippingAddress.defaulted = true
+			break
+		}
+	}
+	return nil
+}
+
+// priceOrder snapshots the product details as they are at the time of
+// purchase, at the price of the variant bought if there is one, and applies
+// the coupons and tax.
+func (p *placementSaga) priceOrder(ctx context.Context, saga *Saga) error {
+	// Coupons scoped to a category also cover the categories below it
+	var parents map[string]string
+	if len(saga.CouponCodes) > 0 {
+		categories, err := p.catalogClient.GetCategories(ctx, nil)
+		if err != nil {
+			return err
+		}
+		parents = make(map[string]string, len(categories))
+		for _, c := range categories {
+			parents[c.ID] = c.ParentID
+		}
+	}
+
+	var products []*OrderedProduct
+	seen := map[lineKey]bool{}
+	for _, item := range saga.Items {
+		key := lineKey{item.ProductID, item.SKU}
+		if seen[key] {
+			return fmt.Errorf("%w: %s is listed more than once", ErrInvalidOrder, key)
+		}
+		seen[key] = true
+		product, err := p.catalogClient.GetProduct(ctx, item.ProductID, false)
+		if err != nil {
+			return err
+		}
+		variant, err := product.Resolve(item.SKU)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidOrder, err)
+		}
+		ordered := &OrderedProduct{
+			ProductID:   product.ID,
+			SKU:         item.SKU,
+			Name:        product.Name,
+			Description: product.Description,
+			Price:       product.PriceOf(variant),
+			Quantity:    item.Quantity,
+			categoryIDs: withAncestors(product.CategoryIDs, parents),
+			taxClass:    product.TaxClass,
+		}
+		if variant != nil {
+			ordered.Options = variant.Options
+		}
+		products = append(products, ordered)
+	}
+	order, err := p.service.NewOrder(ctx, saga.ID, saga.AccountID, products, saga.CouponCodes, saga.TaxRegion, saga.ShippingAddress, saga.IdempotencyKey)
+	if err != nil {
+		return err
+	}
+	saga.order = order
+	return nil
+}
+
+// withAncestors returns the given categories followed by every category above
+// them, walking up parents.
+func withAncestors(ids []string, parents map[string]string) []string {
+	var all []string
+	seen := map[string]bool{}
+	for _, id := range ids {
+		for id != "" && !seen[id] {
+			seen[id] = true
+			all = append(all, id)
+			id = parents[id]
+		}
+	}
+	return all
+}
+
+func (p *placementSaga) reserveStock(ctx context.Context, saga *Saga) error {
+	items := make([]*catalog.StockItem, 0, len(saga.Items))
+	for _, item := range saga.Items {
+		items = append(items, &catalog.StockItem{ProductID: item.ProductID, SKU: item.SKU, Quantity: item.Quantity})
+	}
+	return p.catalogClient.ReserveStock(ctx, saga.ID, items)
+}
+
+func (p *placementSaga) releaseStock(ctx context.Context, saga *Saga) error {
+	err := p.catalogClient.ReleaseStock(ctx, saga.ID)
+	if status.Code(err) == codes.NotFound {
+		// The reservation was never made
+		return nil
+	}
+	return err
+}
+
+func (p *placementSaga) authorizePayment(ctx context.Context, saga *Saga) error {
+	return p.service.AuthorizePayment(ctx, saga.order, saga.paymentMethod)
+}
+
+func (p *placementSaga) voidPayment(ctx context.Context, saga *Saga) error {
+	return p.service.VoidPayment(ctx, saga.ID)
+}
+
+func (p *placementSaga) placeOrder(ctx context.Context, saga *Saga) error {
+	placed, err := p.service.PostOrder(ctx, saga.order)
+	if err != nil {
+		return err
+	}
+	saga.placed = placed
+	if placed.ID != saga.ID {
+		return errOrderReplayed
+	}
+	return nil
+}
@@ -0,0 +1,367 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"microservice/account"
+	"microservice/catalog"
+	"microservice/money"
+	"microservice/payment"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPlaceCompensatesFailedAuthorization(t *testing.T) {
+	tests := []struct {
+		name        string
+		outcome     payment.FakeOutcome
+		wantCode    codes.Code
+		wantPayment payment.Status
+	}{
+		{"declined", payment.FakeDecline, codes.FailedPrecondition, payment.StatusFailed},
+		{"timed out", payment.FakeTimeout, codes.Unavailable, payment.StatusVoided},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newPlacementEnv()
+			env.provider.Script(payment.OpAuthorize, tt.outcome)
+
+			order, err := env.place()
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("Place error = %v, want code %s", err, tt.wantCode)
+			}
+			if order != nil {
+				t.Fatalf("Place returned order %s", order.ID)
+			}
+			sagaID := env.catalog.onlyReservation(t)
+			if !env.catalog.released[sagaID] {
+				t.Errorf("stock reserved for %s was not released", sagaID)
+			}
+			if state := env.repo.sagas[sagaID].State; state != SagaCompensated {
+				t.Errorf("saga state = %s, want %s", state, SagaCompensated)
+			}
+			p, err := env.payments.GetPaymentForOrder(context.Background(), sagaID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Status != tt.wantPayment {
+				t.Errorf("payment status = %s, want %s", p.Status, tt.wantPayment)
+			}
+		})
+	}
+}
+
+func TestRecoverFinishesCompensationAfterVoidTimeout(t *testing.T) {
+	env := newPlacementEnv()
+	errDatabase := errors.New("database is down")
+	env.repo.putOrderErr = errDatabase
+	env.provider.Script(payment.OpVoid, payment.FakeTimeout)
+
+	if _, err := env.place(); !errors.Is(err, errDatabase) {
+		t.Fatalf("Place error = %v, want %v", err, errDatabase)
+	}
+	sagaID := env.catalog.onlyReservation(t)
+	if state := env.repo.sagas[sagaID].State; state != SagaCompensating {
+		t.Fatalf("saga state = %s, want %s", state, SagaCompensating)
+	}
+	if env.catalog.released[sagaID] {
+		t.Fatal("stock was released before the payment was voided")
+	}
+
+	env.placement.Recover(context.Background())
+	if state := env.repo.sagas[sagaID].State; state != SagaCompensated {
+		t.Errorf("saga state after recovery = %s, want %s", state, SagaCompensated)
+	}
+	if !env.catalog.released[sagaID] {
+		t.Error("stock was not released by recovery")
+	}
+	p, err := env.payments.GetPaymentForOrder(context.Background(), sagaID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Status != payment.StatusVoided {
+		t.Errorf("payment status = %s, want %s", p.Status, payment.StatusVoided)
+	}
+}
+
+// placementEnv is a placement saga wired to in-memory stand-ins for the
+// account and catalog services and the order database, and to a real payment
+// service over the fake provider.
+type placementEnv struct {
+	placement *placementSaga
+	repo      *memoryRepository
+	catalog   *fakeCatalog
+	provider  *payment.FakeProvider
+	payments  PaymentClient
+}
+
+func newPlacementEnv() *placementEnv {
+	provider := payment.NewFakeProvider()
+	payments := localPayments{payment.NewService(newMemoryPaymentRepository(), provider)}
+	repo := &memoryRepository{sagas: map[string]*Saga{}, orders: map[string]*Order{}}
+	cat := &fakeCatalog{
+		products: map[string]*catalog.Product{
+			"p1": {ID: "p1", Name: "Mug", Price: money.New(1250, "USD"), Stock: 10, Status: catalog.ProductActive},
+		},
+		released: map[string]bool{},
+	}
+	return &placementEnv{
+		placement: &placementSaga{
+			service:       NewOrderService(repo, nil, "", payments),
+			accountClient: fakeAccounts{},
+			catalogClient: cat,
+		},
+		repo:     repo,
+		catalog:  cat,
+		provider: provider,
+		payments: payments,
+	}
+}
+
+func (e *placementEnv) place() (*Order, error) {
+	items := []*SagaItem{{ProductID: "p1", Quantity: 2}}
+	return e.placement.Place(context.Background(), "a1", items, nil, "", nil, "card", "")
+}
+
+// memoryRepository keeps sagas and orders in memory. Every unfinished saga
+// counts as stale. Methods the tests do not reach are left to the embedded
+// nil Repository.
+type memoryRepository struct {
+	Repository
+	mu          sync.Mutex
+	sagas       map[string]*Saga
+	orders      map[string]*Order
+	putOrderErr error
+	// pending events are in the outbox and events published.
+	pending []*Event
+	events  []*Event
+}
+
+func (r *memoryRepository) PutSaga(ctx context.Context, saga *Saga) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := *saga
+	r.sagas[saga.ID] = &stored
+	return nil
+}
+
+func (r *memoryRepository) PutSagaStep(ctx context.Context, sagaID string, step *SagaStep) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	saga := r.sagas[sagaID]
+	saga.Steps = append(saga.Steps, step)
+	saga.UpdatedAt = step.RecordedAt
+	return nil
+}
+
+func (r *memoryRepository) UpdateSagaState(ctx context.Context, sagaID string, state SagaState, reason string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	saga := r.sagas[sagaID]
+	saga.State, saga.Error, saga.UpdatedAt = state, reason, at
+	return nil
+}
+
+func (r *memoryRepository) ListStaleSagas(ctx context.Context, before time.Time) ([]*Saga, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var sagas []*Saga
+	for _, saga := range r.sagas {
+		if saga.State == SagaRunning || saga.State == SagaCompensating {
+			stale := *saga
+			stale.Steps = append([]*SagaStep{}, saga.Steps...)
+			sagas = append(sagas, &stale)
+		}
+	}
+	return sagas, nil
+}
+
+func (r *memoryRepository) PutOrder(ctx context.Context, order *Order) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.putOrderErr != nil {
+		return r.putOrderErr
+	}
+	r.orders[order.ID] = order
+	return nil
+}
+
+func (r *memoryRepository) GetOrder(ctx context.Context, id string) (*Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	order, ok := r.orders[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return order, nil
+}
+
+type fakeAccounts struct{}
+
+func (fakeAccounts) GetAccount(ctx context.Context, id string) (*account.Account, error) {
+	return &account.Account{ID: id, Name: "Ada"}, nil
+}
+
+func (fakeAccounts) GetAddresses(ctx context.Context, accountID string) ([]*account.Address, error) {
+	return nil, nil
+}
+
+func (fakeAccounts) GetAddress(ctx context.Context, accountID, id string) (*account.Address, error) {
+	return nil, status.Error(codes.NotFound, "address not found")
+}
+
+// fakeCatalog serves products from memory and records which reservations were
+// made and released.
+type fakeCatalog struct {
+	mu           sync.Mutex
+	products     map[string]*catalog.Product
+	reservations []string
+	released     map[string]bool
+}
+
+func (c *fakeCatalog) GetProduct(ctx context.Context, id string, includeInactive bool) (*catalog.Product, error) {
+	product, ok := c.products[id]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "product not found")
+	}
+	return product, nil
+}
+
+func (c *fakeCatalog) GetCategories(ctx context.Context, ids []string) ([]*catalog.Category, error) {
+	return nil, nil
+}
+
+func (c *fakeCatalog) ReserveStock(ctx context.Context, reservationID string, items []*catalog.StockItem) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reservations = append(c.reservations, reservationID)
+	return nil
+}
+
+func (c *fakeCatalog) ReleaseStock(ctx context.Context, reservationID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.released[reservationID] = true
+	return nil
+}
+
+func (c *fakeCatalog) onlyReservation(t *testing.T) string {
+	t.Helper()
+	if len(c.reservations) != 1 {
+		t.Fatalf("got %d stock reservations, want 1", len(c.reservations))
+	}
+	return c.reservations[0]
+}
+
+// localPayments calls a payment.Service in process, answering with the gRPC
+// statuses the payment server would.
+type localPayments struct {
+	service payment.Service
+}
+
+func (l localPayments) Authorize(ctx context.Context, orderID, accountID string, amount money.Money, method string) (*payment.Payment, error) {
+	p, err := l.service.Authorize(ctx, orderID, accountID, amount, method)
+	return p, paymentStatus(err)
+}
+
+func (l localPayments) Capture(ctx context.Context, paymentID string, amount money.Money) (*payment.Payment, error) {
+	p, err := l.service.Capture(ctx, paymentID, amount)
+	return p, paymentStatus(err)
+}
+
+func (l localPayments) Void(ctx context.Context, paymentID string) (*payment.Payment, error) {
+	p, err := l.service.Void(ctx, paymentID)
+	return p, paymentStatus(err)
+}
+
+func (l localPayments) Refund(ctx context.Context, paymentID string, amount money.Money, idempotencyKey string) (*payment.Payment, error) {
+	p, err := l.service.Refund(ctx, paymentID, amount, idempotencyKey)
+	return p, paymentStatus(err)
+}
+
+func (l localPayments) GetPaymentForOrder(ctx context.Context, orderID string) (*payment.Payment, error) {
+	p, err := l.service.GetPaymentForOrder(ctx, orderID)
+	return p, paymentStatus(err)
+}
+
+func paymentStatus(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, payment.ErrDeclined), errors.Is(err, payment.ErrInvalidTransition):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, payment.ErrProviderTimeout):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, payment.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, payment.ErrInvalidPayment):
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return err
+}
+
+// memoryPaymentRepository is a payment.Repository in memory, for one payment
+// per order.
+type memoryPaymentRepository struct {
+	mu       sync.Mutex
+	payments map[string]payment.Payment
+}
+
+func newMemoryPaymentRepository() *memoryPaymentRepository {
+	return &memoryPaymentRepository{payments: map[string]payment.Payment{}}
+}
+
+func (r *memoryPaymentRepository) Close() error {
+	return nil
+}
+
+func (r *memoryPaymentRepository) PutPayment(ctx context.Context, p *payment.Payment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.payments[p.ID] = *p
+	return nil
+}
+
+func (r *memoryPaymentRepository) UpdatePayment(ctx context.Context, p *payment.Payment, from payment.Status) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.payments[p.ID].Status != from {
+		return payment.ErrInvalidTransition
+	}
+	r.payments[p.ID] = *p
+	return nil
+}
+
+func (r *memoryPaymentRepository) GetPayment(ctx context.Context, id string) (*payment.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.payments[id]
+	if !ok {
+		return nil, payment.ErrNotFound
+	}
+	return &p, nil
+}
+
+func (r *memoryPaymentRepository) GetPaymentForOrder(ctx context.Context, orderID string) (*payment.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, p := range r.payments {
+		if p.OrderID == orderID {
+			return &p, nil
+		}
+	}
+	return nil, payment.ErrNotFound
+}
+
+func (r *memoryPaymentRepository) HasRefund(ctx context.Context, paymentID, idempotencyKey string) (bool, error) {
+	return false, nil
+}
+
+func (r *memoryPaymentRepository) PutRefund(ctx context.Context, p *payment.Payment, from payment.Status, idempotencyKey string, amount money.Money) error {
+	return r.UpdatePayment(ctx, p, from)
+}
@@ -0,0 +1,242 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"microservice/money"
+)
+
+var (
+	ErrInvalidCoupon       = errors.New("invalid coupon")
+	ErrCouponNotFound      = errors.New("coupon not found")
+	ErrCouponExists        = errors.New("coupon already exists")
+	ErrCouponExpired       = errors.New("coupon has expired")
+	ErrCouponUsedUp        = errors.New("coupon has reached its usage limit")
+	ErrCouponNotApplicable = errors.New("coupon does not apply to the order")
+)
+
+// maxCoupons is the most coupon codes a single order may use.
+const maxCoupons = 5
+
+// CouponKind is how a coupon discounts an order.
+type CouponKind string
+
+const (
+	// CouponPercentage takes PercentOff percent off each eligible line.
+	CouponPercentage CouponKind = "percentage"
+	// CouponFixedAmount takes AmountOff off the eligible lines as a whole,
+	// line by line until it is used up.
+	CouponFixedAmount CouponKind = "fixed_amount"
+	// CouponBuyXGetY makes GetQuantity units of an eligible line free for
+	// every BuyQuantity units paid for.
+	CouponBuyXGetY CouponKind = "buy_x_get_y"
+)
+
+// Coupon is a promotion customers redeem by entering its code when ordering.
+// Codes are matched case-insensitively and stored upper case.
+type Coupon struct {
+	Code        string       `json:"code"`
+	Description string       `json:"description"`
+	Kind        CouponKind   `json:"kind"`
+	PercentOff  int          `json:"percent_off,omitempty"`
+	AmountOff   *money.Money `json:"amount_off,omitempty"`
+	BuyQuantity int          `json:"buy_quantity,omitempty"`
+	GetQuantity int          `json:"get_quantity,omitempty"`
+	// CategoryIDs limits the coupon to products in these categories or in any
+	// category below them. Without any, every product is eligible.
+	CategoryIDs []string `json:"category_ids,omitempty"`
+	// UsageLimit caps how many orders may use the coupon; 0 is no limit.
+	// UsageCount is how many have.
+	UsageLimit int        `json:"usage_limit,omitempty"`
+	UsageCount int        `json:"usage_count"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Discount is the amount a coupon took off a line of an order.
+type Discount struct {
+	CouponCode string      `json:"coupon_code"`
+	Amount     money.Money `json:"amount"`
+}
+
+// CreateCoupon validates and stores a new coupon.
+func (s *orderService) CreateCoupon(ctx context.Context, coupon *Coupon) (*Coupon, error) {
+	c := *coupon
+	c.Code = strings.ToUpper(strings.TrimSpace(c.Code))
+	c.Description = strings.TrimSpace(c.Description)
+	c.UsageCount = 0
+	c.CreatedAt = time.Now().UTC()
+	if err := validateCoupon(&c); err != nil {
+		return nil, err
+	}
+	if err := s.repo.PutCoupon(ctx, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// GetCoupons returns every coupon, newest first.
+func (s *orderService) GetCoupons(ctx context.Context) ([]*Coupon, error) {
+	return s.repo.GetCoupons(ctx, nil)
+}
+
+func validateCoupon(c *Coupon) error {
+	if c.Code == "" || len(c.Code) > 64 || strings.ContainsAny(c.Code, " \t\r\n") {
+		return fmt.Errorf("%w: code must be 1 to 64 characters without spaces", ErrInvalidCoupon)
+	}
+	if c.UsageLimit < 0 {
+		return fmt.Errorf("%w: usage limit cannot be negative", ErrInvalidCoupon)
+	}
+	if c.ExpiresAt != nil && !c.ExpiresAt.After(c.CreatedAt) {
+		return fmt.Errorf("%w: expiry must be in the future", ErrInvalidCoupon)
+	}
+	switch c.Kind {
+	case CouponPercentage:
+		if c.PercentOff < 1 || c.PercentOff > 100 {
+			return fmt.Errorf("%w: percentage off must be between 1 and 100", ErrInvalidCoupon)
+		}
+		c.AmountOff, c.BuyQuantity, c.GetQuantity = nil, 0, 0
+	case CouponFixedAmount:
+		if c.AmountOff == nil || !money.ValidCurrency(c.AmountOff.Currency) || c.AmountOff.Units <= 0 {
+			return fmt.Errorf("%w: amount off must be positive", ErrInvalidCoupon)
+		}
+		c.PercentOff, c.BuyQuantity, c.GetQuantity = 0, 0, 0
+	case CouponBuyXGetY:
+		if c.BuyQuantity < 1 || c.GetQuantity < 1 {
+			return fmt.Errorf("%w: buy and get quantities must be positive", ErrInvalidCoupon)
+		}
+		c.PercentOff, c.AmountOff = 0, nil
+	default:
+		return fmt.Errorf("%w: unknown kind %q", ErrInvalidCoupon, c.Kind)
+	}
+	return nil
+}
+
+// normalizeCouponCodes upper-cases the codes given with an order and rejects
+// blank and repeated ones.
+func normalizeCouponCodes(codes []string) ([]string, error) {
+	if len(codes) > maxCoupons {
+		return nil, fmt.Errorf("%w: at most %d coupons per order", ErrInvalidOrder, maxCoupons)
+	}
+	normalized := make([]string, 0, len(codes))
+	seen := map[string]bool{}
+	for _, code := range codes {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if code == "" {
+			return nil, fmt.Errorf("%w: coupon code is empty", ErrInvalidOrder)
+		}
+		if seen[code] {
+			return nil, fmt.Errorf("%w: coupon %s is listed more than once", ErrInvalidOrder, code)
+		}
+		seen[code] = true
+		normalized = append(normalized, code)
+	}
+	return normalized, nil
+}
+
+// applyCoupons records on the order lines what each coupon takes off them.
+// Coupons apply in the order given, each to what the lines cost after the
+// ones before it, and never take a line below zero. Every coupon must still
+// be usable at now and take something off.
+func applyCoupons(products []*OrderedProduct, coupons []*Coupon, now time.Time) error {
+	for _, c := range coupons {
+		if c.ExpiresAt != nil && !now.Before(*c.ExpiresAt) {
+			return fmt.Errorf("%w: %s", ErrCouponExpired, c.Code)
+		}
+		if c.UsageLimit > 0 && c.UsageCount >= c.UsageLimit {
+			return fmt.Errorf("%w: %s", ErrCouponUsedUp, c.Code)
+		}
+
+		// A fixed amount is used up across lines rather than per line
+		var budget int64
+		if c.Kind == CouponFixedAmount {
+			if c.AmountOff.Currency != products[0].Price.Currency {
+				return fmt.Errorf("%w: %s is for orders in %s", ErrCouponNotApplicable, c.Code, c.AmountOff.Currency)
+			}
+			budget = c.AmountOff.Units
+		}
+
+		applied := false
+		for _, p := range products {
+			if !c.covers(p) {
+				continue
+			}
+			net, err := p.Total()
+			if err != nil {
+				return err
+			}
+			var off int64
+			switch c.Kind {
+			case CouponPercentage:
+				// Split to keep the multiplication from overflowing
+				off = net.Units/100*int64(c.PercentOff) + net.Units%100*int64(c.PercentOff)/100
+			case CouponFixedAmount:
+				off = min(budget, net.Units)
+				budget -= off
+			case CouponBuyXGetY:
+				free := p.Quantity / (c.BuyQuantity + c.GetQuantity) * c.GetQuantity
+				worth, err := p.Price.Mul(int64(free))
+				if err != nil {
+					return err
+				}
+				off = min(worth.Units, net.Units)
+			}
+			if off <= 0 {
+				continue
+			}
+			p.Discounts = append(p.Discounts, &Discount{CouponCode: c.Code, Amount: money.New(off, net.Currency)})
+			applied = true
+		}
+		if !applied {
+			return fmt.Errorf("%w: %s", ErrCouponNotApplicable, c.Code)
+		}
+	}
+	return nil
+}
+
+// covers reports whether a line of an order is eligible for the coupon.
+func (c *Coupon) covers(p *OrderedProduct) bool {
+	if len(c.CategoryIDs) == 0 {
+		return true
+	}
+	for _, id := range c.CategoryIDs {
+		for _, category := range p.categoryIDs {
+			if id == category {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Discount returns the total taken off the line by coupons.
+func (p *OrderedProduct) Discount() money.Money {
+	discount := money.New(0, p.Price.Currency)
+	for _, d := range p.Discounts {
+		discount.Units += d.Amount.Units
+	}
+	return discount
+}
+
+// Total returns what the line costs: price × quantity less its discounts.
+func (p *OrderedProduct) Total() (money.Money, error) {
+	total, err := p.Price.Mul(int64(p.Quantity))
+	if err != nil {
+		return money.Money{}, err
+	}
+	total.Units -= p.Discount().Units
+	return total, nil
+}
+
+// Discount returns the total taken off the order by coupons.
+func (o *Order) Discount() money.Money {
+	discount := money.New(0, o.Total.Currency)
+	for _, p := range o.Products {
+		discount.Units += p.Discount().Units
+	}
+	return discount
+}
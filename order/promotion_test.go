@@ -0,0 +1,133 @@
+package order
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"microservice/money"
+)
+
+func TestApplyCoupons(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	usd := func(units int64) *money.Money {
+		m := money.New(units, "USD")
+		return &m
+	}
+	type line struct {
+		price      int64
+		quantity   int
+		categories []string
+	}
+	tests := []struct {
+		name    string
+		lines   []line
+		coupons []*Coupon
+		// want is what is taken off each line in total.
+		want    []int64
+		wantErr error
+	}{
+		{
+			name:    "percentage rounds down",
+			lines:   []line{{price: 999, quantity: 1}},
+			coupons: []*Coupon{{Code: "P15", Kind: CouponPercentage, PercentOff: 15}},
+			want:    []int64{149},
+		},
+		{
+			name:    "percentage of everything",
+			lines:   []line{{price: 1250, quantity: 3}},
+			coupons: []*Coupon{{Code: "FREE", Kind: CouponPercentage, PercentOff: 100}},
+			want:    []int64{3750},
+		},
+		{
+			name:    "percentage of a large amount",
+			lines:   []line{{price: math.MaxInt64 / 2, quantity: 1}},
+			coupons: []*Coupon{{Code: "HALF", Kind: CouponPercentage, PercentOff: 50}},
+			want:    []int64{math.MaxInt64 / 4},
+		},
+		{
+			name:    "fixed amount spread over lines",
+			lines:   []line{{price: 1000, quantity: 1}, {price: 400, quantity: 2}},
+			coupons: []*Coupon{{Code: "F15", Kind: CouponFixedAmount, AmountOff: usd(1500)}},
+			want:    []int64{1000, 500},
+		},
+		{
+			name:    "fixed amount above the order",
+			lines:   []line{{price: 1000, quantity: 1}, {price: 400, quantity: 2}},
+			coupons: []*Coupon{{Code: "F50", Kind: CouponFixedAmount, AmountOff: usd(5000)}},
+			want:    []int64{1000, 800},
+		},
+		{
+			name:    "fixed amount in another currency",
+			lines:   []line{{price: 1000, quantity: 1}},
+			coupons: []*Coupon{{Code: "EUR5", Kind: CouponFixedAmount, AmountOff: &money.Money{Units: 500, Currency: "EUR"}}},
+			wantErr: ErrCouponNotApplicable,
+		},
+		{
+			name:    "buy two get one",
+			lines:   []line{{price: 300, quantity: 7}},
+			coupons: []*Coupon{{Code: "B2G1", Kind: CouponBuyXGetY, BuyQuantity: 2, GetQuantity: 1}},
+			want:    []int64{600},
+		},
+		{
+			name:    "buy one get two of a large quantity",
+			lines:   []line{{price: 1, quantity: math.MaxInt64}},
+			coupons: []*Coupon{{Code: "B1G2", Kind: CouponBuyXGetY, BuyQuantity: 1, GetQuantity: 2}},
+			want:    []int64{math.MaxInt64 / 3 * 2},
+		},
+		{
+			name:    "buy two get one of too few",
+			lines:   []line{{price: 300, quantity: 2}},
+			coupons: []*Coupon{{Code: "B2G1", Kind: CouponBuyXGetY, BuyQuantity: 2, GetQuantity: 1}},
+			wantErr: ErrCouponNotApplicable,
+		},
+		{
+			name:  "stacked on what is left",
+			lines: []line{{price: 1000, quantity: 1}},
+			coupons: []*Coupon{
+				{Code: "HALF", Kind: CouponPercentage, PercentOff: 50},
+				{Code: "F8", Kind: CouponFixedAmount, AmountOff: usd(800)},
+			},
+			want: []int64{1000},
+		},
+		{
+			name:    "limited to a category",
+			lines:   []line{{price: 1000, quantity: 1, categories: []string{"mugs"}}, {price: 1000, quantity: 1, categories: []string{"shirts"}}},
+			coupons: []*Coupon{{Code: "MUGS", Kind: CouponPercentage, PercentOff: 10, CategoryIDs: []string{"mugs"}}},
+			want:    []int64{100, 0},
+		},
+		{
+			name:    "expired",
+			lines:   []line{{price: 1000, quantity: 1}},
+			coupons: []*Coupon{{Code: "OLD", Kind: CouponPercentage, PercentOff: 10, ExpiresAt: &now}},
+			wantErr: ErrCouponExpired,
+		},
+		{
+			name:    "used up",
+			lines:   []line{{price: 1000, quantity: 1}},
+			coupons: []*Coupon{{Code: "ONCE", Kind: CouponPercentage, PercentOff: 10, UsageLimit: 1, UsageCount: 1}},
+			wantErr: ErrCouponUsedUp,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var products []*OrderedProduct
+			for _, l := range tt.lines {
+				products = append(products, &OrderedProduct{Price: money.New(l.price, "USD"), Quantity: l.quantity, categoryIDs: l.categories})
+			}
+			err := applyCoupons(products, tt.coupons, now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			for i, p := range products {
+				if got := p.Discount().Units; got != tt.want[i] {
+					t.Errorf("line %d discount = %d, want %d", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"microservice/money"
+
+	"github.com/segmentio/ksuid"
+)
+
+var (
+	ErrNotRefundable         = errors.New("order cannot be refunded")
+	ErrInvalidRefund         = errors.New("invalid refund")
+	ErrRefundExceedsQuantity = errors.New("refund exceeds ordered quantity")
+	ErrNothingToRefund       = errors.New("nothing left to refund")
+)
+
+// Refund records money returned for some or all of an order's lines. A
+// Pending refund is recorded, and counts against what is left to refund, but
+// its money has not yet been confirmed returned through the payment.
+type Refund struct {
+	ID        string        `json:"id"`
+	OrderID   string        `json:"order_id"`
+	Reason    string        `json:"reason"`
+	CreatedAt time.Time     `json:"created_at"`
+	Lines     []*RefundLine `json:"lines"`
+	Pending   bool          `json:"pending,omitempty"`
+}
+
+// RefundLine is the refunded quantity and amount of a single line of the
+// order: a product, or the variant of it with SKU.
+type RefundLine struct {
+	ProductID string      `json:"product_id"`
+	SKU       string      `json:"sku,omitempty"`
+	Quantity  int         `json:"quantity"`
+	Amount    money.Money `json:"amount"`
+}
+
+// Amount returns the total amount returned by the refund. All lines of a refund
+// share the order's currency.
+func (r *Refund) Amount() money.Money {
+	var amount money.Money
+	for i, l := range r.Lines {
+		if i == 0 {
+			amount.Currency = l.Amount.Currency
+		}
+		amount.Units += l.Amount.Units
+	}
+	return amount
+}
+
+// RefundedQuantity returns how many units of a product, or of its variant with
+// sku, have already been refunded.
+func (o *Order) RefundedQuantity(productID, sku string) int {
+	var quantity int
+	for _, r := range o.Refunds {
+		for _, l := range r.Lines {
+			if l.ProductID == productID && l.SKU == sku {
+				quantity += l.Quantity
+			}
+		}
+	}
+	return quantity
+}
+
+// refundedLineAmount returns how much has already been refunded for a product,
+// or for its variant with sku.
+func (o *Order) refundedLineAmount(productID, sku string) int64 {
+	var units int64
+	for _, r := range o.Refunds {
+		for _, l := range r.Lines {
+			if l.ProductID == productID && l.SKU == sku {
+				units += l.Amount.Units
+			}
+		}
+	}
+	return units
+}
+
+// RefundedAmount returns the total amount refunded on the order, in the
+// order's currency.
+func (o *Order) RefundedAmount() money.Money {
+	amount := money.New(0, o.Total.Currency)
+	for _, r := range o.Refunds {
+		amount.Units += r.Amount().Units
+	}
+	return amount
+}
+
+// newRefund builds a refund for the given lines of an order at the unit prices
+// the customer paid, net of discounts and including tax. An empty lines slice refunds everything
+// not yet refunded.
+func newRefund(o *Order, lines []*RefundLine, reason string) (*Refund, error) {
+	if len(lines) == 0 {
+		for _, p := range o.Products {
+			if remaining := p.Quantity - o.RefundedQuantity(p.ProductID, p.SKU); remaining > 0 {
+				lines = append(lines, &RefundLine{ProductID: p.ProductID, SKU: p.SKU, Quantity: remaining})
+			}
+		}
+		if len(lines) == 0 {
+			return nil, ErrNothingToRefund
+		}
+	}
+
+	ordered := map[lineKey]*OrderedProduct{}
+	for _, p := range o.Products {
+		ordered[lineKey{p.ProductID, p.SKU}] = p
+	}
+
+	refund := &Refund{
+		ID:        ksuid.New().String(),
+		OrderID:   o.ID,
+		Reason:    reason,
+		CreatedAt: time.Now().UTC(),
+	}
+	requested := map[lineKey]int{}
+	for _, l := range lines {
+		key := lineKey{l.ProductID, l.SKU}
+		product, ok := ordered[key]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s is not part of order %s", ErrInvalidRefund, key, o.ID)
+		}
+		if l.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: quantity must be positive", ErrInvalidRefund)
+		}
+		if _, dup := requested[key]; dup {
+			return nil, fmt.Errorf("%w: %s listed more than once", ErrInvalidRefund, key)
+		}
+		requested[key] = l.Quantity
+		remaining := product.Quantity - o.RefundedQuantity(l.ProductID, l.SKU)
+		if l.Quantity > remaining {
+			return nil, fmt.Errorf("%w: %s", ErrRefundExceedsQuantity, key)
+		}
+		paid, err := product.Total()
+		if err != nil {
+			return nil, err
+		}
+		paid.Units += product.Tax.Units
+		// Discounts and tax are shared evenly over the units, rounding down; the last
+		// units refunded make up the difference, so a line refunded in full
+		// returns exactly what was paid for it.
+		amount := money.New(paid.Units/int64(product.Quantity)*int64(l.Quantity)+
+			paid.Units%int64(product.Quantity)*int64(l.Quantity)/int64(product.Quantity), paid.Currency)
+		if l.Quantity == remaining {
+			amount.Units = paid.Units - o.refundedLineAmount(l.ProductID, l.SKU)
+		}
+		refund.Lines = append(refund.Lines, &RefundLine{
+			ProductID: l.ProductID,
+			SKU:       l.SKU,
+			Quantity:  l.Quantity,
+			Amount:    amount,
+		})
+	}
+	return refund, nil
+}
@@ -0,0 +1,92 @@
+package order
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"microservice/money"
+)
+
+func TestNewRefundSpreadsRemainder(t *testing.T) {
+	tests := []struct {
+		name     string
+		price    int64
+		quantity int
+		discount int64
+		tax      int64
+		// refunds are the quantities refunded one after another, 0 meaning
+		// everything not yet refunded.
+		refunds []int
+		want    []int64
+	}{
+		{name: "whole line", price: 1000, quantity: 3, discount: 1, refunds: []int{3}, want: []int64{2999}},
+		{name: "one at a time", price: 1000, quantity: 3, discount: 1, refunds: []int{1, 1, 1}, want: []int64{999, 999, 1001}},
+		{name: "two then one", price: 1000, quantity: 3, discount: 1, refunds: []int{2, 1}, want: []int64{1999, 1000}},
+		{name: "one then the rest", price: 1000, quantity: 3, discount: 1, refunds: []int{1, 0}, want: []int64{999, 2000}},
+		{name: "tax remainder", price: 333, quantity: 3, tax: 100, refunds: []int{1, 1, 1}, want: []int64{366, 366, 367}},
+		{name: "discount and tax", price: 1000, quantity: 4, discount: 250, tax: 713, refunds: []int{3, 1}, want: []int64{3347, 1116}},
+		{name: "large amounts", price: math.MaxInt64 / 4, quantity: 3, refunds: []int{2, 1}, want: []int64{math.MaxInt64 / 4 * 2, math.MaxInt64 / 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := &OrderedProduct{ProductID: "p1", Price: money.New(tt.price, "USD"), Quantity: tt.quantity, Tax: money.New(tt.tax, "USD")}
+			if tt.discount > 0 {
+				line.Discounts = []*Discount{{CouponCode: "SAVE", Amount: money.New(tt.discount, "USD")}}
+			}
+			o := &Order{ID: "o1", Products: []*OrderedProduct{line}}
+
+			var total int64
+			for i, quantity := range tt.refunds {
+				var lines []*RefundLine
+				if quantity > 0 {
+					lines = []*RefundLine{{ProductID: "p1", Quantity: quantity}}
+				}
+				refund, err := newRefund(o, lines, "")
+				if err != nil {
+					t.Fatalf("refund %d: %v", i, err)
+				}
+				if got := refund.Amount().Units; got != tt.want[i] {
+					t.Errorf("refund %d = %d, want %d", i, got, tt.want[i])
+				}
+				total += refund.Amount().Units
+				o.Refunds = append(o.Refunds, refund)
+			}
+			paid, err := line.Total()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if paid.Units+tt.tax != total {
+				t.Errorf("refunded %d in total, want the %d paid", total, paid.Units+tt.tax)
+			}
+		})
+	}
+}
+
+func TestNewRefundRejects(t *testing.T) {
+	line := &OrderedProduct{ProductID: "p1", Price: money.New(1000, "USD"), Quantity: 2, Tax: money.New(0, "USD")}
+	o := &Order{ID: "o1", Products: []*OrderedProduct{line}}
+	o.Refunds = []*Refund{{ID: "r1", Lines: []*RefundLine{{ProductID: "p1", Quantity: 1, Amount: money.New(1000, "USD")}}}}
+
+	tests := []struct {
+		name    string
+		lines   []*RefundLine
+		wantErr error
+	}{
+		{"more than is left", []*RefundLine{{ProductID: "p1", Quantity: 2}}, ErrRefundExceedsQuantity},
+		{"unknown line", []*RefundLine{{ProductID: "p2", Quantity: 1}}, ErrInvalidRefund},
+		{"unknown SKU", []*RefundLine{{ProductID: "p1", SKU: "RED", Quantity: 1}}, ErrInvalidRefund},
+		{"zero quantity", []*RefundLine{{ProductID: "p1", Quantity: 0}}, ErrInvalidRefund},
+		{"listed twice", []*RefundLine{{ProductID: "p1", Quantity: 1}, {ProductID: "p1", Quantity: 1}}, ErrInvalidRefund},
+	}
+	for _, tt := range tests {
+		if _, err := newRefund(o, tt.lines, ""); !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	o.Refunds = append(o.Refunds, &Refund{ID: "r2", Lines: []*RefundLine{{ProductID: "p1", Quantity: 1, Amount: money.New(1000, "USD")}}})
+	if _, err := newRefund(o, nil, ""); !errors.Is(err, ErrNothingToRefund) {
+		t.Errorf("refunding a fully refunded order: error = %v, want %v", err, ErrNothingToRefund)
+	}
+}
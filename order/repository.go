@@ -3,18 +3,64 @@ package order
 import (
 	"context"
 	"database/sql"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 
+	"microservice/money"
+
 	"github.com/lib/pq"
 	_ "github.com/lib/pq"
 )
 
+var (
+	ErrNotFound       = errors.New("order not found")
+	ErrStatusConflict = errors.New("order status was changed concurrently")
+)
+
 type Repository interface {
 	Close() error
 	PutOrder(ctx context.Context, order *Order) error
+	GetOrder(ctx context.Context, id string) (*Order, error)
+	GetOrderByIdempotencyKey(ctx context.Context, accountID, key string) (*Order, error)
 	GetOrdersForAccount(ctx context.Context, accountID string) ([]*Order, error)
+	UpdateOrderStatus(ctx context.Context, orderID string, from OrderStatus, change *StatusChange) error
+	CancelOrder(ctx context.Context, orderID string, from OrderStatus, change *StatusChange, refund *Refund) error
+	PutRefund(ctx context.Context, status OrderStatus, refund *Refund) error
+	// CompleteRefund marks a pending refund as done. Completing it again does
+	// nothing.
+	CompleteRefund(ctx context.Context, refund *Refund) error
+	// DeleteRefund removes a refund that is still pending.
+	DeleteRefund(ctx context.Context, refundID string) error
+	// ListOrdersWithPendingRefunds returns the orders with a refund left
+	// pending since before the given time.
+	ListOrdersWithPendingRefunds(ctx context.Context, before time.Time) ([]*Order, error)
+	PutCoupon(ctx context.Context, coupon *Coupon) error
+	GetCoupons(ctx context.Context, codes []string) ([]*Coupon, error)
+	PutSaga(ctx context.Context, saga *Saga) error
+	PutSagaStep(ctx context.Context, sagaID string, step *SagaStep) error
+	UpdateSagaState(ctx context.Context, sagaID string, state SagaState, reason string, at time.Time) error
+	ListStaleSagas(ctx context.Context, before time.Time) ([]*Saga, error)
+	RelayEvents(ctx context.Context, limit int) (int, error)
+	ListEvents(ctx context.Context, afterOffset uint64, limit int) ([]*Event, error)
 }
 
+// relayLockKey identifies the advisory lock that serializes relays, so that
+// event offsets are handed out in the order events become visible.
+const relayLockKey = 0x6f72646572 // "order"
+
+// migrateLockKey identifies the advisory lock that keeps services starting
+// together from migrating the schema at the same time.
+const migrateLockKey = 0x6f726465726d // "orderm"
+
+// schema creates the tables on a new database and brings one made by an
+// earlier version up to date. It is safe to run again.
+//
+//go:embed up.sql
+var schema string
+
 type postgresRepository struct {
 	db *sql.DB
 }
@@ -26,15 +72,45 @@ func NewPostgresRepository(databaseURL string) (Repository, error) {
 	}
 	err = db.Ping()
 	if err != nil {
+		db.Close()
 		return nil, err
 	}
-	return &postgresRepository{db: db}, nil
+	r := &postgresRepository{db: db}
+	if err := r.migrate(context.Background()); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// migrate runs the schema in one transaction, so that a database is either
+// fully upgraded or left as it was.
+func (r *postgresRepository) migrate(ctx context.Context) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+	if _, err = tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", migrateLockKey); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, schema); err != nil {
+		return fmt.Errorf("migrate schema: %w", err)
+	}
+	return tx.Commit()
 }
 
 func (r *postgresRepository) Close() error {
 	return r.db.Close()
 }
 
+// PutOrder stores an order together with its discounts, and counts a use of
+// each of its coupons. It fails with ErrCouponUsedUp if a coupon reached its
+// usage limit since the order was priced.
 func (r *postgresRepository) PutOrder(ctx context.Context, o *Order) (err error) {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -45,18 +121,57 @@ func (r *postgresRepository) PutOrder(ctx context.Context, o *Order) (err error)
 			tx.Rollback()
 			return
 		}
+		err = tx.Commit()
 	}()
-	_, err = tx.ExecContext(ctx, "INSERT INTO orders (id, account_id, created_at, total_price) VALUES ($1, $2, $3, $4)", o.ID, o.AccountID, o.CreatedAt, o.Total)
+	idempotencyKey := sql.NullString{String: o.IdempotencyKey, Valid: o.IdempotencyKey != ""}
+	shipping, err := json.Marshal(o.ShippingAddress)
 	if err != nil {
 		return err
 	}
-	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("order_products", "order_id", "product_id", "quantity"))
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO orders (id, account_id, created_at, subtotal_units, tax_units, total_units, currency, tax_region, shipping_address, payment_id, status, idempotency_key, request_hash, coupon_codes) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)",
+		o.ID, o.AccountID, o.CreatedAt, o.Subtotal.Units, o.Tax.Units, o.Total.Units, o.Total.Currency, o.TaxRegion, shipping, o.PaymentID, o.Status, idempotencyKey, o.RequestHash, pq.Array(o.CouponCodes))
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "orders_account_idempotency_key" {
+			return ErrDuplicateIdempotencyKey
+		}
+		return err
+	}
+	for _, code := range o.CouponCodes {
+		res, err := tx.ExecContext(ctx,
+			"UPDATE coupons SET usage_count = usage_count + 1 WHERE code = $1 AND (usage_limit = 0 OR usage_count < usage_limit)",
+			code)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return fmt.Errorf("%w: %s", ErrCouponUsedUp, code)
+		}
+	}
+	for _, c := range o.StatusHistory {
+		_, err = tx.ExecContext(ctx, "INSERT INTO order_status_history (order_id, status, changed_at) VALUES ($1, $2, $3)", o.ID, c.Status, c.ChangedAt)
+		if err != nil {
+			return err
+		}
+	}
+	err = insertEvent(ctx, tx, &Event{Type: EventOrderPlaced, OrderID: o.ID, OccurredAt: o.CreatedAt, Order: o})
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("order_products", "order_id", "product_id", "sku", "name", "description", "options", "price_units", "quantity", "tax_units"))
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 	for _, p := range o.Products {
-		_, err = stmt.ExecContext(ctx, o.ID, p.ProductID, p.Quantity)
+		options, err := json.Marshal(p.Options)
+		if err != nil {
+			return err
+		}
+		_, err = stmt.ExecContext(ctx, o.ID, p.ProductID, p.SKU, p.Name, p.Description, string(options), p.Price.Units, p.Quantity, p.Tax.Units)
 		if err != nil {
 			return err
 		}
@@ -65,20 +180,227 @@ func (r *postgresRepository) PutOrder(ctx context.Context, o *Order) (err error)
 	if err != nil {
 		return err
 	}
+	if err = stmt.Close(); err != nil {
+		return err
+	}
+	// Discounts refer to the order lines, so they go in once the copy is done
+	for _, p := range o.Products {
+		for _, d := range p.Discounts {
+			_, err = tx.ExecContext(ctx,
+				"INSERT INTO order_discounts (order_id, product_id, sku, coupon_code, amount_units) VALUES ($1, $2, $3, $4, $5)",
+				o.ID, p.ProductID, p.SKU, d.CouponCode, d.Amount.Units)
+			if err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
+func (r *postgresRepository) GetOrder(ctx context.Context, id string) (*Order, error) {
+	orders, err := r.queryOrders(ctx, "o.id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return nil, ErrNotFound
+	}
+	return orders[0], nil
+}
+
+func (r *postgresRepository) GetOrderByIdempotencyKey(ctx context.Context, accountID, key string) (*Order, error) {
+	orders, err := r.queryOrders(ctx, "o.account_id = $1 AND o.idempotency_key = $2", accountID, key)
+	if err != nil {
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return nil, ErrNotFound
+	}
+	return orders[0], nil
+}
+
 func (r *postgresRepository) GetOrdersForAccount(ctx context.Context, accountID string) ([]*Order, error) {
+	return r.queryOrders(ctx, "o.account_id = $1", accountID)
+}
+
+// UpdateOrderStatus moves an order from one status to another and appends the
+// change to its history. It fails with ErrStatusConflict if the order is no
+// longer in the expected status.
+func (r *postgresRepository) UpdateOrderStatus(ctx context.Context, orderID string, from OrderStatus, change *StatusChange) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+	if err = updateStatus(ctx, tx, orderID, from, change); err != nil {
+		return err
+	}
+	return insertEvent(ctx, tx, &Event{Type: EventOrderStatusChanged, OrderID: orderID, OccurredAt: change.ChangedAt, StatusChange: change})
+}
+
+// CancelOrder moves an order to cancelled and, if refund is not nil, records
+// the refund in the same transaction.
+func (r *postgresRepository) CancelOrder(ctx context.Context, orderID string, from OrderStatus, change *StatusChange, refund *Refund) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+	if err = updateStatus(ctx, tx, orderID, from, change); err != nil {
+		return err
+	}
+	if refund != nil {
+		if err = insertRefund(ctx, tx, refund); err != nil {
+			return err
+		}
+	}
+	return insertEvent(ctx, tx, &Event{Type: EventOrderCancelled, OrderID: orderID, OccurredAt: change.ChangedAt, StatusChange: change, Refund: refund})
+}
+
+// PutRefund records a refund for an order that is still in the given status.
+// It fails with ErrRefundExceedsQuantity if, together with earlier refunds,
+// more units would be refunded than were ordered. The OrderRefunded event of a
+// pending refund waits until it is completed.
+func (r *postgresRepository) PutRefund(ctx context.Context, status OrderStatus, refund *Refund) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+	var current string
+	err = tx.QueryRowContext(ctx, "SELECT status FROM orders WHERE id = $1 FOR UPDATE", refund.OrderID).Scan(&current)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if OrderStatus(current) != status {
+		return ErrStatusConflict
+	}
+	if err = insertRefund(ctx, tx, refund); err != nil {
+		return err
+	}
+	if refund.Pending {
+		return nil
+	}
+	return insertEvent(ctx, tx, &Event{Type: EventOrderRefunded, OrderID: refund.OrderID, OccurredAt: refund.CreatedAt, Refund: refund})
+}
+
+func (r *postgresRepository) CompleteRefund(ctx context.Context, refund *Refund) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+	res, err := tx.ExecContext(ctx, "UPDATE order_refunds SET pending = false WHERE id = $1 AND pending", refund.ID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil || n == 0 {
+		return err
+	}
+	refund.Pending = false
+	return insertEvent(ctx, tx, &Event{Type: EventOrderRefunded, OrderID: refund.OrderID, OccurredAt: time.Now().UTC(), Refund: refund})
+}
+
+func (r *postgresRepository) DeleteRefund(ctx context.Context, refundID string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM order_refunds WHERE id = $1 AND pending", refundID)
+	return err
+}
+
+func (r *postgresRepository) ListOrdersWithPendingRefunds(ctx context.Context, before time.Time) ([]*Order, error) {
+	return r.queryOrders(ctx, "o.id IN (SELECT order_id FROM order_refunds WHERE pending AND created_at < $1)", before)
+}
+
+func updateStatus(ctx context.Context, tx *sql.Tx, orderID string, from OrderStatus, change *StatusChange) error {
+	res, err := tx.ExecContext(ctx, "UPDATE orders SET status = $1 WHERE id = $2 AND status = $3", change.Status, orderID, from)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrStatusConflict
+	}
+	_, err = tx.ExecContext(ctx, "INSERT INTO order_status_history (order_id, status, changed_at) VALUES ($1, $2, $3)", orderID, change.Status, change.ChangedAt)
+	return err
+}
+
+func insertRefund(ctx context.Context, tx *sql.Tx, refund *Refund) error {
+	_, err := tx.ExecContext(ctx, "INSERT INTO order_refunds (id, order_id, reason, created_at, pending) VALUES ($1, $2, $3, $4, $5)", refund.ID, refund.OrderID, refund.Reason, refund.CreatedAt, refund.Pending)
+	if err != nil {
+		return err
+	}
+	for _, l := range refund.Lines {
+		_, err = tx.ExecContext(ctx, "INSERT INTO order_refund_lines (refund_id, order_id, product_id, sku, quantity, amount_units) VALUES ($1, $2, $3, $4, $5, $6)", refund.ID, refund.OrderID, l.ProductID, l.SKU, l.Quantity, l.Amount.Units)
+		if err != nil {
+			return err
+		}
+	}
+
+	var exceeded bool
+	err = tx.QueryRowContext(ctx,
+		`
+		SELECT EXISTS (
+			SELECT 1
+			FROM order_products op
+			JOIN order_refund_lines rl ON rl.order_id = op.order_id AND rl.product_id = op.product_id AND rl.sku = op.sku
+			WHERE op.order_id = $1
+			GROUP BY op.product_id, op.sku, op.quantity
+			HAVING SUM(rl.quantity) > op.quantity
+		)
+		`, refund.OrderID).Scan(&exceeded)
+	if err != nil {
+		return err
+	}
+	if exceeded {
+		return ErrRefundExceedsQuantity
+	}
+	return nil
+}
+
+// queryOrders loads the orders matching the given condition together with their
+// products, discounts, status history and refunds.
+func (r *postgresRepository) queryOrders(ctx context.Context, condition string, args ...interface{}) ([]*Order, error) {
 	rows, err := r.db.QueryContext(ctx,
 		`
-		SELECT 
-			o.id, o.account_id, o.created_at, o.total_price, 
-			op.product_id, op.quantity 
-		FROM orders o 
+		SELECT
+			o.id, o.account_id, o.created_at, o.subtotal_units, o.tax_units, o.total_units, o.currency, o.tax_region, COALESCE(o.shipping_address, 'null'), o.payment_id, o.status,
+			COALESCE(o.idempotency_key, ''), o.request_hash, o.coupon_codes,
+			op.product_id, op.sku, op.name, op.description, op.options, op.price_units, op.quantity, op.tax_units
+		FROM orders o
 		JOIN order_products op ON o.id = op.order_id
-		WHERE o.account_id = $1
-		ORDER BY o.created_at DESC
-		`, accountID)
+		WHERE `+condition+`
+		ORDER BY o.created_at DESC, o.id
+		`, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -89,15 +411,21 @@ func (r *postgresRepository) GetOrdersForAccount(ctx context.Context, accountID
 	var products []*OrderedProduct
 
 	for rows.Next() {
-		var orderID, dbAccountID, productID string
+		var orderID, dbAccountID, currency, taxRegion, paymentID, status, idempotencyKey, hash string
 		var createdAt time.Time
-		var total float64
-		var quantity int
+		var subtotalUnits, taxUnits, totalUnits, priceUnits, lineTaxUnits int64
+		var options, shipping []byte
+		var couponCodes []string
+		product := &OrderedProduct{}
 
-		err := rows.Scan(&orderID, &dbAccountID, &createdAt, &total, &productID, &quantity)
+		err := rows.Scan(&orderID, &dbAccountID, &createdAt, &subtotalUnits, &taxUnits, &totalUnits, &currency, &taxRegion, &shipping, &paymentID, &status, &idempotencyKey, &hash, pq.Array(&couponCodes),
+			&product.ProductID, &product.SKU, &product.Name, &product.Description, &options, &priceUnits, &product.Quantity, &lineTaxUnits)
 		if err != nil {
 			return nil, err
 		}
+		if err := json.Unmarshal(options, &product.Options); err != nil {
+			return nil, err
+		}
 
 		// If this row belongs to a new order, push the previous one to orders
 		if lastOrder == nil || lastOrder.ID != orderID {
@@ -106,18 +434,31 @@ func (r *postgresRepository) GetOrdersForAccount(ctx context.Context, accountID
 				orders = append(orders, lastOrder)
 			}
 			lastOrder = &Order{
-				ID:        orderID,
-				AccountID: dbAccountID,
-				CreatedAt: createdAt,
-				Total:     total,
+				ID:             orderID,
+				AccountID:      dbAccountID,
+				CreatedAt:      createdAt,
+				Subtotal:       money.New(subtotalUnits, currency),
+				Tax:            money.New(taxUnits, currency),
+				Total:          money.New(totalUnits, currency),
+				TaxRegion:      taxRegion,
+				PaymentID:      paymentID,
+				CouponCodes:    couponCodes,
+				Status:         OrderStatus(status),
+				IdempotencyKey: idempotencyKey,
+				RequestHash:    hash,
+			}
+			if err := json.Unmarshal(shipping, &lastOrder.ShippingAddress); err != nil {
+				return nil, err
 			}
 			products = []*OrderedProduct{}
 		}
 
-		products = append(products, &OrderedProduct{
-			ProductID: productID,
-			Quantity:  quantity,
-		})
+		product.Price = money.New(priceUnits, currency)
+		product.Tax = money.New(lineTaxUnits, currency)
+		products = append(products, product)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
 	// Append the last order if exists
@@ -126,5 +467,463 @@ func (r *postgresRepository) GetOrdersForAccount(ctx context.Context, accountID
 		orders = append(orders, lastOrder)
 	}
 
+	if err := r.loadDiscounts(ctx, orders); err != nil {
+		return nil, err
+	}
+	if err := r.loadStatusHistory(ctx, orders); err != nil {
+		return nil, err
+	}
+	if err := r.loadRefunds(ctx, orders); err != nil {
+		return nil, err
+	}
 	return orders, nil
 }
+
+// loadDiscounts fills in the discounts on the lines of the given orders.
+func (r *postgresRepository) loadDiscounts(ctx context.Context, orders []*Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+	type orderLine struct {
+		orderID string
+		line    lineKey
+	}
+	lines := map[orderLine]*OrderedProduct{}
+	ids := make([]string, 0, len(orders))
+	for _, o := range orders {
+		ids = append(ids, o.ID)
+		for _, p := range o.Products {
+			lines[orderLine{o.ID, lineKey{p.ProductID, p.SKU}}] = p
+		}
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`
+		SELECT d.order_id, d.product_id, d.sku, d.coupon_code, d.amount_units, o.currency
+		FROM order_discounts d
+		JOIN orders o ON o.id = d.order_id
+		WHERE d.order_id = ANY($1)
+		ORDER BY d.order_id, array_position(o.coupon_codes, d.coupon_code::text)
+		`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var orderID, productID, sku, code, currency string
+		var amountUnits int64
+		if err := rows.Scan(&orderID, &productID, &sku, &code, &amountUnits, &currency); err != nil {
+			return err
+		}
+		if p, ok := lines[orderLine{orderID, lineKey{productID, sku}}]; ok {
+			p.Discounts = append(p.Discounts, &Discount{CouponCode: code, Amount: money.New(amountUnits, currency)})
+		}
+	}
+	return rows.Err()
+}
+
+// loadStatusHistory fills in the status history of the given orders.
+func (r *postgresRepository) loadStatusHistory(ctx context.Context, orders []*Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+	byID := map[string]*Order{}
+	ids := make([]string, 0, len(orders))
+	for _, o := range orders {
+		byID[o.ID] = o
+		ids = append(ids, o.ID)
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT order_id, status, changed_at FROM order_status_history WHERE order_id = ANY($1) ORDER BY changed_at, id",
+		pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var orderID, status string
+		var changedAt time.Time
+		if err := rows.Scan(&orderID, &status, &changedAt); err != nil {
+			return err
+		}
+		if o, ok := byID[orderID]; ok {
+			o.StatusHistory = append(o.StatusHistory, &StatusChange{
+				Status:    OrderStatus(status),
+				ChangedAt: changedAt,
+			})
+		}
+	}
+	return rows.Err()
+}
+
+// loadRefunds fills in the refunds recorded against the given orders.
+func (r *postgresRepository) loadRefunds(ctx context.Context, orders []*Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+	byID := map[string]*Order{}
+	ids := make([]string, 0, len(orders))
+	for _, o := range orders {
+		byID[o.ID] = o
+		ids = append(ids, o.ID)
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`
+		SELECT
+			r.id, r.order_id, r.reason, r.created_at, r.pending,
+			rl.product_id, rl.sku, rl.quantity, rl.amount_units, o.currency
+		FROM order_refunds r
+		JOIN order_refund_lines rl ON rl.refund_id = r.id
+		JOIN orders o ON o.id = r.order_id
+		WHERE r.order_id = ANY($1)
+		ORDER BY r.created_at, r.id
+		`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var lastRefund *Refund
+	for rows.Next() {
+		var refundID, orderID, reason, productID, sku, currency string
+		var createdAt time.Time
+		var pending bool
+		var quantity int
+		var amountUnits int64
+		if err := rows.Scan(&refundID, &orderID, &reason, &createdAt, &pending, &productID, &sku, &quantity, &amountUnits, &currency); err != nil {
+			return err
+		}
+		if lastRefund == nil || lastRefund.ID != refundID {
+			lastRefund = &Refund{
+				ID:        refundID,
+				OrderID:   orderID,
+				Reason:    reason,
+				CreatedAt: createdAt,
+				Pending:   pending,
+			}
+			if o, ok := byID[orderID]; ok {
+				o.Refunds = append(o.Refunds, lastRefund)
+			}
+		}
+		lastRefund.Lines = append(lastRefund.Lines, &RefundLine{
+			ProductID: productID,
+			SKU:       sku,
+			Quantity:  quantity,
+			Amount:    money.New(amountUnits, currency),
+		})
+	}
+	return rows.Err()
+}
+
+func (r *postgresRepository) PutSaga(ctx context.Context, saga *Saga) error {
+	items, err := json.Marshal(saga.Items)
+	if err != nil {
+		return err
+	}
+	shipping, err := json.Marshal(saga.ShippingAddress)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx,
+		"INSERT INTO order_sagas (id, account_id, idempotency_key, items, coupon_codes, tax_region, shipping_address, state, error, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)",
+		saga.ID, saga.AccountID, saga.IdempotencyKey, items, pq.Array(saga.CouponCodes), saga.TaxRegion, shipping, saga.State, saga.Error, saga.CreatedAt, saga.UpdatedAt)
+	return err
+}
+
+// PutSagaStep records a step and marks the saga as having made progress.
+func (r *postgresRepository) PutSagaStep(ctx context.Context, sagaID string, step *SagaStep) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO order_saga_steps (saga_id, step, status, recorded_at) VALUES ($1, $2, $3, $4)",
+		sagaID, step.Name, step.Status, step.RecordedAt)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, "UPDATE order_sagas SET updated_at = $2 WHERE id = $1", sagaID, step.RecordedAt)
+	return err
+}
+
+func (r *postgresRepository) UpdateSagaState(ctx context.Context, sagaID string, state SagaState, reason string, at time.Time) error {
+	res, err := r.db.ExecContext(ctx,
+		"UPDATE order_sagas SET state = $2, error = $3, updated_at = $4 WHERE id = $1",
+		sagaID, state, reason, at)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListStaleSagas returns the running and compensating sagas last updated
+// before the given time, with their steps.
+func (r *postgresRepository) ListStaleSagas(ctx context.Context, before time.Time) ([]*Saga, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`
+		SELECT id, account_id, idempotency_key, items, coupon_codes, tax_region, COALESCE(shipping_address, 'null'), state, error, created_at, updated_at
+		FROM order_sagas
+		WHERE state IN ($1, $2) AND updated_at < $3
+		ORDER BY updated_at
+		`, SagaRunning, SagaCompensating, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sagas []*Saga
+	byID := map[string]*Saga{}
+	var ids []string
+	for rows.Next() {
+		saga := &Saga{}
+		var items, shipping []byte
+		err := rows.Scan(&saga.ID, &saga.AccountID, &saga.IdempotencyKey, &items, pq.Array(&saga.CouponCodes), &saga.TaxRegion, &shipping, &saga.State, &saga.Error, &saga.CreatedAt, &saga.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(items, &saga.Items); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(shipping, &saga.ShippingAddress); err != nil {
+			return nil, err
+		}
+		sagas = append(sagas, saga)
+		byID[saga.ID] = saga
+		ids = append(ids, saga.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(sagas) == 0 {
+		return nil, nil
+	}
+
+	stepRows, err := r.db.QueryContext(ctx,
+		"SELECT saga_id, step, status, recorded_at FROM order_saga_steps WHERE saga_id = ANY($1) ORDER BY id",
+		pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer stepRows.Close()
+	for stepRows.Next() {
+		var sagaID string
+		step := &SagaStep{}
+		if err := stepRows.Scan(&sagaID, &step.Name, &step.Status, &step.RecordedAt); err != nil {
+			return nil, err
+		}
+		if saga, ok := byID[sagaID]; ok {
+			saga.Steps = append(saga.Steps, step)
+		}
+	}
+	return sagas, stepRows.Err()
+}
+
+// PutCoupon stores a new coupon, failing with ErrCouponExists if its code is
+// taken.
+func (r *postgresRepository) PutCoupon(ctx context.Context, c *Coupon) error {
+	var amountUnits int64
+	var currency string
+	if c.AmountOff != nil {
+		amountUnits, currency = c.AmountOff.Units, c.AmountOff.Currency
+	}
+	categoryIDs := c.CategoryIDs
+	if categoryIDs == nil {
+		categoryIDs = []string{}
+	}
+	_, err := r.db.ExecContext(ctx,
+		`
+		INSERT INTO coupons (
+			code, description, kind, percent_off, amount_units, currency, buy_quantity, get_quantity,
+			category_ids, usage_limit, usage_count, expires_at, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		`,
+		c.Code, c.Description, c.Kind, c.PercentOff, amountUnits, currency, c.BuyQuantity, c.GetQuantity,
+		pq.Array(categoryIDs), c.UsageLimit, c.UsageCount, c.ExpiresAt, c.CreatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return fmt.Errorf("%w: %s", ErrCouponExists, c.Code)
+		}
+		return err
+	}
+	return nil
+}
+
+// GetCoupons returns the coupons with the given codes, in the order given,
+// failing with ErrCouponNotFound if one does not exist. Without codes it
+// returns every coupon, newest first.
+func (r *postgresRepository) GetCoupons(ctx context.Context, codes []string) ([]*Coupon, error) {
+	query := `
+		SELECT
+			code, description, kind, percent_off, amount_units, currency, buy_quantity, get_quantity,
+			category_ids, usage_limit, usage_count, expires_at, created_at
+		FROM coupons
+		`
+	var args []interface{}
+	if len(codes) > 0 {
+		query += "WHERE code = ANY($1)"
+		args = append(args, pq.Array(codes))
+	} else {
+		query += "ORDER BY created_at DESC, code"
+	}
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	coupons := []*Coupon{}
+	byCode := map[string]*Coupon{}
+	for rows.Next() {
+		c := &Coupon{}
+		var amountUnits int64
+		var currency string
+		var expiresAt sql.NullTime
+		err := rows.Scan(&c.Code, &c.Description, &c.Kind, &c.PercentOff, &amountUnits, &currency, &c.BuyQuantity, &c.GetQuantity,
+			pq.Array(&c.CategoryIDs), &c.UsageLimit, &c.UsageCount, &expiresAt, &c.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if currency != "" {
+			amountOff := money.New(amountUnits, currency)
+			c.AmountOff = &amountOff
+		}
+		if expiresAt.Valid {
+			c.ExpiresAt = &expiresAt.Time
+		}
+		coupons = append(coupons, c)
+		byCode[c.Code] = c
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(codes) == 0 {
+		return coupons, nil
+	}
+
+	ordered := make([]*Coupon, 0, len(codes))
+	for _, code := range codes {
+		c, ok := byCode[code]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrCouponNotFound, code)
+		}
+		ordered = append(ordered, c)
+	}
+	return ordered, nil
+}
+
+func insertEvent(ctx context.Context, tx *sql.Tx, event *Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO order_outbox (order_id, type, payload, created_at) VALUES ($1, $2, $3, $4)",
+		event.OrderID, event.Type, payload, event.OccurredAt)
+	return err
+}
+
+// RelayEvents moves up to limit events from the outbox to the published event
+// log, assigning their offsets.
+func (r *postgresRepository) RelayEvents(ctx context.Context, limit int) (n int, err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+	if _, err = tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", relayLockKey); err != nil {
+		return 0, err
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT id, order_id, type, payload, created_at FROM order_outbox ORDER BY id LIMIT $1", limit)
+	if err != nil {
+		return 0, err
+	}
+	type outboxEntry struct {
+		id         int64
+		orderID    string
+		eventType  string
+		payload    []byte
+		occurredAt time.Time
+	}
+	var entries []outboxEntry
+	for rows.Next() {
+		var e outboxEntry
+		if err = rows.Scan(&e.id, &e.orderID, &e.eventType, &e.payload, &e.occurredAt); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		entries = append(entries, e)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(entries) == 0 {
+		return 0, nil
+	}
+
+	now := time.Now().UTC()
+	ids := make([]int64, 0, len(entries))
+	for _, e := range entries {
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO order_events (order_id, type, payload, occurred_at, published_at) VALUES ($1, $2, $3, $4, $5)",
+			e.orderID, e.eventType, e.payload, e.occurredAt, now)
+		if err != nil {
+			return 0, err
+		}
+		ids = append(ids, e.id)
+	}
+	if _, err = tx.ExecContext(ctx, "DELETE FROM order_outbox WHERE id = ANY($1)", pq.Array(ids)); err != nil {
+		return 0, err
+	}
+	return len(entries), nil
+}
+
+func (r *postgresRepository) ListEvents(ctx context.Context, afterOffset uint64, limit int) ([]*Event, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT id, payload FROM order_events WHERE id > $1 ORDER BY id LIMIT $2", afterOffset, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*Event
+	for rows.Next() {
+		var offset int64
+		var payload []byte
+		if err := rows.Scan(&offset, &payload); err != nil {
+			return nil, err
+		}
+		event := &Event{}
+		if err := json.Unmarshal(payload, event); err != nil {
+			return nil, err
+		}
+		event.Offset = uint64(offset)
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,112 @@
+package order
+
+import (
+	"context"
+	"time"
+)
+
+// SagaState is the lifecycle state of an order placement saga.
+type SagaState string
+
+const (
+	SagaRunning      SagaState = "running"
+	SagaCompleted    SagaState = "completed"
+	SagaCompensating SagaState = "compensating"
+	SagaCompensated  SagaState = "compensated"
+)
+
+// StepStatus is the progress of a single saga step.
+type StepStatus string
+
+const (
+	StepStarted     StepStatus = "started"
+	StepCompleted   StepStatus = "completed"
+	StepCompensated StepStatus = "compensated"
+)
+
+// Saga is the durable record of placing one order. Every step is recorded
+// before and after it runs, so that a saga interrupted by a crash can be
+// rolled back by undoing each step that was started. The saga's ID doubles as
+// the ID of the order it places and of the stock reserved for it.
+type Saga struct {
+	ID             string      `json:"id"`
+	AccountID      string      `json:"account_id"`
+	IdempotencyKey string      `json:"idempotency_key,omitempty"`
+	Items          []*SagaItem `json:"items"`
+	CouponCodes    []string    `json:"coupon_codes,omitempty"`
+	TaxRegion      string      `json:"tax_region,omitempty"`
+	// ShippingAddress is the address given with the order, which may only
+	// refer to the address book until the account has been verified.
+	ShippingAddress *Address    `json:"shipping_address,omitempty"`
+	State           SagaState   `json:"state"`
+	Error           string      `json:"error,omitempty"`
+	Steps           []*SagaStep `json:"steps"`
+	CreatedAt       time.Time   `json:"created_at"`
+	UpdatedAt       time.Time   `json:"updated_at"`
+
+	// order is the priced order once the products have been snapshotted, and
+	// placed the order handed back to the caller. paymentMethod is what the
+	// order is paid with. None of them is persisted: sagas are only ever
+	// resumed to be rolled back.
+	order         *Order
+	placed        *Order
+	paymentMethod string
+}
+
+// SagaItem is a product, or a variant of it when SKU is set, and quantity
+// requested by the order being placed.
+type SagaItem struct {
+	ProductID string `json:"product_id"`
+	SKU       string `json:"sku,omitempty"`
+	Quantity  int    `json:"quantity"`
+}
+
+// SagaStep records that a step of a saga reached a status.
+type SagaStep struct {
+	Name       string     `json:"name"`
+	Status     StepStatus `json:"status"`
+	RecordedAt time.Time  `json:"recorded_at"`
+}
+
+// stepStatus returns the latest status recorded for the named step.
+func (s *Saga) stepStatus(name string) (StepStatus, bool) {
+	for i := len(s.Steps) - 1; i >= 0; i-- {
+		if s.Steps[i].Name == name {
+			return s.Steps[i].Status, true
+		}
+	}
+	return "", false
+}
+
+// StartSaga stores a new saga.
+func (s *orderService) StartSaga(ctx context.Context, saga *Saga) error {
+	return s.repo.PutSaga(ctx, saga)
+}
+
+// RecordSagaStep durably records that a step reached a status and, once
+// stored, appends it to the saga's steps.
+func (s *orderService) RecordSagaStep(ctx context.Context, saga *Saga, name string, status StepStatus) error {
+	step := &SagaStep{Name: name, Status: status, RecordedAt: time.Now().UTC()}
+	if err := s.repo.PutSagaStep(ctx, saga.ID, step); err != nil {
+		return err
+	}
+	saga.Steps = append(saga.Steps, step)
+	saga.UpdatedAt = step.RecordedAt
+	return nil
+}
+
+// SetSagaState moves a saga to a new state, recording why it got there.
+func (s *orderService) SetSagaState(ctx context.Context, saga *Saga, state SagaState, reason string) error {
+	now := time.Now().UTC()
+	if err := s.repo.UpdateSagaState(ctx, saga.ID, state, reason, now); err != nil {
+		return err
+	}
+	saga.State, saga.Error, saga.UpdatedAt = state, reason, now
+	return nil
+}
+
+// StaleSagas returns the unfinished sagas that have not made progress for at
+// least idle, oldest first.
+func (s *orderService) StaleSagas(ctx context.Context, idle time.Duration) ([]*Saga, error) {
+	return s.repo.ListStaleSagas(ctx, time.Now().UTC().Add(-idle))
+}
@@ -2,31 +2,40 @@ package order
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
 	"net"
+	"time"
 
 	"microservice/account"
+	"microservice/auth"
 	"microservice/catalog"
+	"microservice/money"
 	pb "microservice/order/pb"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 )
 
 type grpcServer struct {
 	pb.UnimplementedOrderServiceServer
 	service       Service
-	accountClient *account.Client
 	catalogClient *catalog.Client
+	placement     *placementSaga
+	events        *eventRelay
 }
 
-// ListenGRPC starts a gRPC server for the Order service.
-func ListenGRPC(service Service, accountURL, catalogURL string, port int) error {
+// ListenGRPC starts a gRPC server for the Order service. It calls the catalog
+// as the order service, with tokens signed by serviceSigner.
+func ListenGRPC(service Service, accountURL, catalogURL string, serviceSigner *auth.Signer, port int) error {
 	accountClient, err := account.NewClient(accountURL)
 	if err != nil {
 		return err
 	}
-	catalogClient, err := catalog.NewClient(catalogURL)
+	catalogClient, err := catalog.NewServiceClient(catalogURL, serviceSigner, "order")
 	if err != nil {
 		accountClient.Close()
 		return err
@@ -38,137 +47,343 @@ func ListenGRPC(service Service, accountURL, catalogURL string, port int) error
 		catalogClient.Close()
 		return err
 	}
+	placement := &placementSaga{service: service, accountClient: accountClient, catalogClient: catalogClient}
+	// Settle placements left unfinished by a previous run, then keep watching
+	go placement.RecoverEvery(recoveryInterval)
+
+	go settleRefundsEvery(service, recoveryInterval)
+
+	events := newEventRelay(service)
+	go events.Run(relayInterval)
+
 	s := grpc.NewServer()
-	pb.RegisterOrderServiceServer(s, &grpcServer{service: service, accountClient: accountClient, catalogClient: catalogClient})
+	pb.RegisterOrderServiceServer(s, &grpcServer{service: service, catalogClient: catalogClient, placement: placement, events: events})
 	reflection.Register(s)
 	return s.Serve(lis)
 }
 
+// settleRefundsEvery retries refunds left pending for longer than
+// refundTimeout now and then at every interval, forever.
+func settleRefundsEvery(service Service, interval time.Duration) {
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		if err := service.SettleRefunds(ctx, refundTimeout); err != nil {
+			log.Printf("failed to settle pending refunds: %v", err)
+		}
+		cancel()
+		time.Sleep(interval)
+	}
+}
+
 func (s *grpcServer) PostOrder(ctx context.Context, req *pb.PostOrderRequest) (*pb.PostOrderResponse, error) {
-	// Verify account exists
-	_, err := s.accountClient.GetAccount(ctx, req.AccountId)
-	if err != nil {
-		return nil, err
+	var shipping *Address
+	switch {
+	case req.ShippingAddressId != "" && req.ShippingAddress != nil:
+		return nil, status.Error(codes.InvalidArgument, "give either a shipping address or its ID, not both")
+	case req.ShippingAddressId != "":
+		shipping = &Address{AddressID: req.ShippingAddressId}
+	case req.ShippingAddress != nil:
+		shipping = addressFromProto(req.ShippingAddress)
+		// Addresses given in full are not from the address book
+		shipping.AddressID = ""
 	}
 
-	var products []*OrderedProduct
-	var total float64 = 0.0
+	// Return the original order if this request is a retry
+	if req.IdempotencyKey != "" {
+		var requested []*OrderedProduct
+		for _, item := range req.Products {
+			requested = append(requested, &OrderedProduct{
+				ProductID: item.ProductId,
+				SKU:       item.Sku,
+				Quantity:  int(item.Quantity),
+			})
+		}
+		order, err := s.service.ReplayOrder(ctx, req.AccountId, requested, req.CouponCodes, req.TaxRegion, shipping, req.IdempotencyKey)
+		if err == nil {
+			return &pb.PostOrderResponse{Order: ToProto(order)}, nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			return nil, grpcError(err)
+		}
+	}
 
+	items := make([]*SagaItem, 0, len(req.Products))
 	for _, item := range req.Products {
-		product, err := s.catalogClient.GetProduct(ctx, item.ProductId)
+		items = append(items, &SagaItem{ProductID: item.ProductId, SKU: item.Sku, Quantity: int(item.Quantity)})
+	}
+	placed, err := s.placement.Place(ctx, req.AccountId, items, req.CouponCodes, req.TaxRegion, shipping, req.PaymentMethod, req.IdempotencyKey)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &pb.PostOrderResponse{Order: ToProto(placed)}, nil
+}
+
+func (s *grpcServer) GetOrderForAccount(ctx context.Context, req *pb.GetOrderForAccountRequest) (*pb.GetOrderForAccountResponse, error) {
+	accountOrders, err := s.service.GetOrdersForAccount(ctx, req.AccountId)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	var orders []*pb.Order
+	for _, order := range accountOrders {
+		orders = append(orders, ToProto(order))
+	}
+
+	return &pb.GetOrderForAccountResponse{Orders: orders}, nil
+}
+
+func (s *grpcServer) GetOrders(ctx context.Context, req *pb.GetOrdersRequest) (*pb.GetOrdersResponse, error) {
+	// This method could return all orders or orders based on some criteria
+	// For now, return the order from the request
+	return &pb.GetOrdersResponse{Orders: []*pb.Order{req.Order}}, nil
+}
+
+func (s *grpcServer) UpdateOrderStatus(ctx context.Context, req *pb.UpdateOrderStatusRequest) (*pb.UpdateOrderStatusResponse, error) {
+	if OrderStatus(req.Status) == StatusCancelled {
+		order, err := s.cancelOrder(ctx, req.OrderId, "")
 		if err != nil {
 			return nil, err
 		}
+		return &pb.UpdateOrderStatusResponse{Order: ToProto(order)}, nil
+	}
 
-		orderedProduct := &OrderedProduct{
-			ProductID: product.ID,
-			Quantity:  int(item.Quantity),
+	order, err := s.service.UpdateOrderStatus(ctx, req.OrderId, OrderStatus(req.Status))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	if order.Status == StatusShipped {
+		// The goods have left, so the reserved stock is gone for good. If it
+		// cannot be committed, the caller ships the order again to retry.
+		if err := s.catalogClient.CommitStock(ctx, order.ID); err != nil {
+			return nil, status.Errorf(codes.Unavailable, "order %s shipped but its stock was not committed, ship it again to retry: %v", order.ID, err)
 		}
-		products = append(products, orderedProduct)
-		total += product.Price * float64(item.Quantity)
 	}
+	return &pb.UpdateOrderStatusResponse{Order: ToProto(order)}, nil
+}
 
-	order, err := s.service.PostOrder(ctx, req.AccountId, products)
+func (s *grpcServer) CancelOrder(ctx context.Context, req *pb.CancelOrderRequest) (*pb.CancelOrderResponse, error) {
+	order, err := s.cancelOrder(ctx, req.OrderId, req.Reason)
 	if err != nil {
 		return nil, err
 	}
+	return &pb.CancelOrderResponse{Order: ToProto(order)}, nil
+}
 
-	// Update the total calculated from catalog service
-	order.Total = total
-
-	// Get product details for response
-	productIDs := []string{}
-	for _, p := range order.Products {
-		productIDs = append(productIDs, p.ProductID)
+// SubscribeOrderEvents streams order events after the requested offset, then
+// keeps streaming new ones as they are published until the client goes away.
+func (s *grpcServer) SubscribeOrderEvents(req *pb.SubscribeOrderEventsRequest, stream pb.OrderService_SubscribeOrderEventsServer) error {
+	err := s.events.Subscribe(stream.Context(), req.AfterOffset, func(event *Event) error {
+		return stream.Send(eventToProto(event))
+	})
+	if errors.Is(err, context.Canceled) {
+		return nil
 	}
+	return err
+}
 
-	catalogProducts, err := s.catalogClient.GetProducts(ctx, productIDs, "", 0, 0)
+// cancelOrder cancels an order and returns its reserved stock to the catalog.
+func (s *grpcServer) cancelOrder(ctx context.Context, orderID, reason string) (*Order, error) {
+	order, err := s.service.CancelOrder(ctx, orderID, reason)
 	if err != nil {
-		return nil, err
+		return nil, grpcError(err)
 	}
+	s.releaseStock(order.ID)
+	return order, nil
+}
 
-	orderProto := &pb.Order{
-		Id:        order.ID,
-		AccountId: order.AccountID,
-		Total:     order.Total,
-		Products:  []*pb.OrderedProduct{},
+// releaseStock returns the stock reserved for an order. It runs on its own
+// context so that it still happens when the request's context has expired.
+func (s *grpcServer) releaseStock(orderID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.catalogClient.ReleaseStock(ctx, orderID); err != nil {
+		log.Printf("failed to release stock for order %s: %v", orderID, err)
 	}
-	orderProto.CreatedAt, _ = order.CreatedAt.MarshalBinary()
+}
 
-	for _, p := range order.Products {
-		for _, catalogProduct := range catalogProducts {
-			if catalogProduct.ID == p.ProductID {
-				orderProto.Products = append(orderProto.Products, &pb.OrderedProduct{
-					Id:          catalogProduct.ID,
-					Name:        catalogProduct.Name,
-					Description: catalogProduct.Description,
-					Price:       catalogProduct.Price,
-					Quantity:    uint32(p.Quantity),
-				})
-				break
-			}
-		}
+func (s *grpcServer) RefundOrder(ctx context.Context, req *pb.RefundOrderRequest) (*pb.RefundOrderResponse, error) {
+	var lines []*RefundLine
+	for _, l := range req.Lines {
+		lines = append(lines, &RefundLine{
+			ProductID: l.ProductId,
+			SKU:       l.Sku,
+			Quantity:  int(l.Quantity),
+		})
 	}
 
-	return &pb.PostOrderResponse{Order: orderProto}, nil
+	order, err := s.service.RefundOrder(ctx, req.OrderId, lines, req.Reason)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.RefundOrderResponse{Order: ToProto(order)}, nil
 }
 
-func (s *grpcServer) GetOrderForAccount(ctx context.Context, req *pb.GetOrderForAccountRequest) (*pb.GetOrderForAccountResponse, error) {
-	accountOrders, err := s.service.GetOrdersForAccount(ctx, req.AccountId)
+func (s *grpcServer) CreateCoupon(ctx context.Context, req *pb.CreateCouponRequest) (*pb.CreateCouponResponse, error) {
+	if req.Coupon == nil {
+		return nil, status.Error(codes.InvalidArgument, "coupon is required")
+	}
+	coupon, err := s.service.CreateCoupon(ctx, couponFromProto(req.Coupon))
 	if err != nil {
-		return nil, err
+		return nil, grpcError(err)
 	}
+	return &pb.CreateCouponResponse{Coupon: couponToProto(coupon)}, nil
+}
 
-	productIDs := map[string]bool{}
-	for _, order := range accountOrders {
-		for _, product := range order.Products {
-			productIDs[product.ProductID] = true
-		}
+func (s *grpcServer) GetCoupons(ctx context.Context, req *pb.GetCouponsRequest) (*pb.GetCouponsResponse, error) {
+	coupons, err := s.service.GetCoupons(ctx)
+	if err != nil {
+		return nil, grpcError(err)
 	}
-	ids := []string{}
-	for id := range productIDs {
-		ids = append(ids, id)
+	resp := &pb.GetCouponsResponse{Coupons: []*pb.Coupon{}}
+	for _, c := range coupons {
+		resp.Coupons = append(resp.Coupons, couponToProto(c))
 	}
+	return resp, nil
+}
 
-	catalogProducts, err := s.catalogClient.GetProducts(ctx, ids, "", 0, 0)
-	if err != nil {
-		return nil, err
+// grpcError maps order domain errors onto gRPC status codes.
+func grpcError(err error) error {
+	switch {
+	case errors.Is(err, ErrNotFound), errors.Is(err, ErrCouponNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, ErrUnknownStatus), errors.Is(err, ErrInvalidRefund), errors.Is(err, ErrInvalidOrder),
+		errors.Is(err, ErrInvalidCoupon), errors.Is(err, ErrNoTaxRate):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, ErrInvalidTransition), errors.Is(err, ErrStatusConflict),
+		errors.Is(err, ErrNotRefundable), errors.Is(err, ErrRefundExceedsQuantity),
+		errors.Is(err, ErrNothingToRefund), errors.Is(err, ErrAccountDeleted), errors.Is(err, errPaymentRefused),
+		errors.Is(err, ErrCouponExpired), errors.Is(err, ErrCouponUsedUp), errors.Is(err, ErrCouponNotApplicable):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, ErrIdempotencyKeyReused), errors.Is(err, ErrCouponExists):
+		return status.Error(codes.AlreadyExists, err.Error())
 	}
+	return err
+}
 
-	productMap := map[string]*catalog.Product{}
-	for _, p := range catalogProducts {
-		productMap[p.ID] = p
+// ToProto converts an order to its protobuf form, for services that pass
+// orders on.
+func ToProto(order *Order) *pb.Order {
+	orderProto := &pb.Order{
+		Id:            order.ID,
+		AccountId:     order.AccountID,
+		Subtotal:      money.ToProto(order.Subtotal),
+		Tax:           money.ToProto(order.Tax),
+		Total:         money.ToProto(order.Total),
+		TaxRegion:     order.TaxRegion,
+		PaymentId:     order.PaymentID,
+		Products:      []*pb.OrderedProduct{},
+		Status:        string(order.Status),
+		StatusHistory: []*pb.OrderStatusChange{},
+		CouponCodes:   order.CouponCodes,
+	}
+	orderProto.CreatedAt, _ = order.CreatedAt.MarshalBinary()
+	if order.ShippingAddress != nil {
+		orderProto.ShippingAddress = addressToProto(order.ShippingAddress)
 	}
 
-	var orders []*pb.Order
-	for _, order := range accountOrders {
-		orderProto := &pb.Order{
-			Id:        order.ID,
-			AccountId: order.AccountID,
-			Total:     order.Total,
-			Products:  []*pb.OrderedProduct{},
+	for _, orderedProduct := range order.Products {
+		productProto := &pb.OrderedProduct{
+			Id:          orderedProduct.ProductID,
+			Sku:         orderedProduct.SKU,
+			Name:        orderedProduct.Name,
+			Description: orderedProduct.Description,
+			Price:       money.ToProto(orderedProduct.Price),
+			Quantity:    uint32(orderedProduct.Quantity),
+			Options:     orderedProduct.Options,
+			Tax:         money.ToProto(orderedProduct.Tax),
 		}
-		orderProto.CreatedAt, _ = order.CreatedAt.MarshalBinary()
-
-		for _, orderedProduct := range order.Products {
-			if catalogProduct, exists := productMap[orderedProduct.ProductID]; exists {
-				orderProto.Products = append(orderProto.Products, &pb.OrderedProduct{
-					Id:          catalogProduct.ID,
-					Name:        catalogProduct.Name,
-					Description: catalogProduct.Description,
-					Price:       catalogProduct.Price,
-					Quantity:    uint32(orderedProduct.Quantity),
-				})
-			}
+		for _, d := range orderedProduct.Discounts {
+			productProto.Discounts = append(productProto.Discounts, &pb.Discount{
+				CouponCode: d.CouponCode,
+				Amount:     money.ToProto(d.Amount),
+			})
 		}
-		orders = append(orders, orderProto)
+		orderProto.Products = append(orderProto.Products, productProto)
 	}
 
-	return &pb.GetOrderForAccountResponse{Orders: orders}, nil
+	for _, change := range order.StatusHistory {
+		orderProto.StatusHistory = append(orderProto.StatusHistory, statusChangeToProto(change))
+	}
+
+	for _, refund := range order.Refunds {
+		orderProto.Refunds = append(orderProto.Refunds, refundToProto(refund))
+	}
+	return orderProto
 }
 
-func (s *grpcServer) GetOrders(ctx context.Context, req *pb.GetOrdersRequest) (*pb.GetOrdersResponse, error) {
-	// This method could return all orders or orders based on some criteria
-	// For now, return the order from the request
-	return &pb.GetOrdersResponse{Orders: []*pb.Order{req.Order}}, nil
+func statusChangeToProto(change *StatusChange) *pb.OrderStatusChange {
+	changeProto := &pb.OrderStatusChange{Status: string(change.Status)}
+	changeProto.ChangedAt, _ = change.ChangedAt.MarshalBinary()
+	return changeProto
+}
+
+func refundToProto(refund *Refund) *pb.Refund {
+	refundProto := &pb.Refund{Id: refund.ID, Reason: refund.Reason, Pending: refund.Pending}
+	refundProto.CreatedAt, _ = refund.CreatedAt.MarshalBinary()
+	for _, l := range refund.Lines {
+		refundProto.Lines = append(refundProto.Lines, &pb.RefundLine{
+			ProductId: l.ProductID,
+			Sku:       l.SKU,
+			Quantity:  uint32(l.Quantity),
+			Amount:    money.ToProto(l.Amount),
+		})
+	}
+	return refundProto
+}
+
+func couponToProto(c *Coupon) *pb.Coupon {
+	couponProto := &pb.Coupon{
+		Code:        c.Code,
+		Description: c.Description,
+		Kind:        string(c.Kind),
+		PercentOff:  uint32(c.PercentOff),
+		BuyQuantity: uint32(c.BuyQuantity),
+		GetQuantity: uint32(c.GetQuantity),
+		CategoryIds: c.CategoryIDs,
+		UsageLimit:  uint32(c.UsageLimit),
+		UsageCount:  uint32(c.UsageCount),
+	}
+	if c.AmountOff != nil {
+		couponProto.AmountOff = money.ToProto(*c.AmountOff)
+	}
+	if c.ExpiresAt != nil {
+		couponProto.ExpiresAt, _ = c.ExpiresAt.MarshalBinary()
+	}
+	couponProto.CreatedAt, _ = c.CreatedAt.MarshalBinary()
+	return couponProto
+}
+
+// eventToProto converts an order event to its protobuf form.
+func eventToProto(event *Event) *pb.OrderEvent {
+	eventProto := &pb.OrderEvent{
+		Offset:  event.Offset,
+		Type:    string(event.Type),
+		OrderId: event.OrderID,
+	}
+	eventProto.OccurredAt, _ = event.OccurredAt.MarshalBinary()
+	if event.Order != nil {
+		eventProto.Order = ToProto(event.Order)
+	}
+	if event.StatusChange != nil {
+		eventProto.StatusChange = statusChangeToProto(event.StatusChange)
+	}
+	if event.Refund != nil {
+		eventProto.Refund = refundToProto(event.Refund)
+	}
+	return eventProto
+}
+
+func addressToProto(a *Address) *pb.Address {
+	return &pb.Address{
+		AddressId:  a.AddressID,
+		Name:       a.Name,
+		Line1:      a.Line1,
+		Line2:      a.Line2,
+		City:       a.City,
+		Region:     a.Region,
+		PostalCode: a.PostalCode,
+		Country:    a.Country,
+		Phone:      a.Phone,
+	}
 }
@@ -2,53 +2,544 @@ package order
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
 	"time"
 
-	"github.com/segmentio/ksuid"
+	"microservice/money"
+	"microservice/payment"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	ErrInvalidOrder   = errors.New("invalid order")
+	ErrAccountDeleted = errors.New("account has been deleted")
 )
 
 type Service interface {
-	PostOrder(ctx context.Context, accountID string, products []*OrderedProduct) (*Order, error)
+	NewOrder(ctx context.Context, orderID, accountID string, products []*OrderedProduct, couponCodes []string, taxRegion string, shipping *Address, idempotencyKey string) (*Order, error)
+	AuthorizePayment(ctx context.Context, order *Order, method string) error
+	VoidPayment(ctx context.Context, orderID string) error
+	PostOrder(ctx context.Context, order *Order) (*Order, error)
+	ReplayOrder(ctx context.Context, accountID string, products []*OrderedProduct, couponCodes []string, taxRegion string, shipping *Address, idempotencyKey string) (*Order, error)
 	GetOrdersForAccount(ctx context.Context, accountID string) ([]*Order, error)
+	GetOrder(ctx context.Context, orderID string) (*Order, error)
+	UpdateOrderStatus(ctx context.Context, orderID string, status OrderStatus) (*Order, error)
+	CancelOrder(ctx context.Context, orderID string, reason string) (*Order, error)
+	RefundOrder(ctx context.Context, orderID string, lines []*RefundLine, reason string) (*Order, error)
+	SettleRefunds(ctx context.Context, idle time.Duration) error
+	CreateCoupon(ctx context.Context, coupon *Coupon) (*Coupon, error)
+	GetCoupons(ctx context.Context) ([]*Coupon, error)
+	StartSaga(ctx context.Context, saga *Saga) error
+	RecordSagaStep(ctx context.Context, saga *Saga, name string, status StepStatus) error
+	SetSagaState(ctx context.Context, saga *Saga, state SagaState, reason string) error
+	StaleSagas(ctx context.Context, idle time.Duration) ([]*Saga, error)
+	RelayEvents(ctx context.Context, limit int) (int, error)
+	ListEvents(ctx context.Context, afterOffset uint64, limit int) ([]*Event, error)
 }
 
+// Order is a purchase by an account. Subtotal sums the lines before
+// discounts; Total is what the customer pays: the subtotal less discounts plus
+// Tax, charged at the rates of TaxRegion. ShippingAddress is where the order
+// ships to, if anywhere. PaymentID is the payment authorized for the total
+// when the order was placed, and is empty for orders with nothing to pay.
 type Order struct {
-	ID        string            `json:"id"`
-	AccountID string            `json:"account_id"`
-	CreatedAt time.Time         `json:"created_at"`
-	Total     float64           `json:"total"`
-	Products  []*OrderedProduct `json:"products"`
+	ID              string            `json:"id"`
+	AccountID       string            `json:"account_id"`
+	CreatedAt       time.Time         `json:"created_at"`
+	Subtotal        money.Money       `json:"subtotal"`
+	Tax             money.Money       `json:"tax"`
+	Total           money.Money       `json:"total"`
+	TaxRegion       string            `json:"tax_region,omitempty"`
+	ShippingAddress *Address          `json:"shipping_address,omitempty"`
+	PaymentID       string            `json:"payment_id,omitempty"`
+	Products        []*OrderedProduct `json:"products"`
+	CouponCodes     []string          `json:"coupon_codes,omitempty"`
+	Status          OrderStatus       `json:"status"`
+	StatusHistory   []*StatusChange   `json:"status_history"`
+	Refunds         []*Refund         `json:"refunds"`
+	IdempotencyKey  string            `json:"idempotency_key,omitempty"`
+	RequestHash     string            `json:"-"`
 }
 
+// OrderedProduct is a line of an order. Name, Description and Price are a
+// snapshot of the catalog product taken when the order was placed, and so are
+// the Options of the variant bought when SKU is set. An order has one line per
+// product and SKU. Discounts lists what each coupon of the order took off the
+// line, and Tax is the tax charged on what is left.
 type OrderedProduct struct {
-	ProductID string `json:"product_id"`
-	Quantity  int    `json:"quantity"`
+	ProductID   string            `json:"product_id"`
+	SKU         string            `json:"sku,omitempty"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Options     map[string]string `json:"options,omitempty"`
+	Price       money.Money       `json:"price"`
+	Quantity    int               `json:"quantity"`
+	Discounts   []*Discount       `json:"discounts,omitempty"`
+	Tax         money.Money       `json:"tax"`
+
+	// categoryIDs are the categories of the product and every category above
+	// them, for scoping coupons, and taxClass its tax class, while the order
+	// is priced. Neither is persisted.
+	categoryIDs []string
+	taxClass    string
+}
+
+// lineKey identifies a line of an order: a product, or a variant of it.
+type lineKey struct {
+	productID string
+	sku       string
+}
+
+func (l lineKey) String() string {
+	if l.sku == "" {
+		return "product " + l.productID
+	}
+	return "product " + l.productID + " SKU " + l.sku
+}
+
+// PaymentClient is what the order service needs of the payment service. It is
+// satisfied by *payment.Client, and its errors are gRPC statuses as that
+// client returns them.
+type PaymentClient interface {
+	Authorize(ctx context.Context, orderID, accountID string, amount money.Money, method string) (*payment.Payment, error)
+	Capture(ctx context.Context, paymentID string, amount money.Money) (*payment.Payment, error)
+	Void(ctx context.Context, paymentID string) (*payment.Payment, error)
+	Refund(ctx context.Context, paymentID string, amount money.Money, idempotencyKey string) (*payment.Payment, error)
+	GetPaymentForOrder(ctx context.Context, orderID string) (*payment.Payment, error)
 }
 
 type orderService struct {
-	repo Repository
+	repo             Repository
+	tax              TaxCalculator
+	defaultTaxRegion string
+	payments         PaymentClient
 }
 
-func NewOrderService(repo Repository) Service {
-	return &orderService{repo: repo}
+// NewOrderService constructs a Service that taxes orders with tax, in
+// defaultTaxRegion unless they name a region, and takes payment for them
+// through payments. Orders without a region are not taxed when there is no
+// default.
+func NewOrderService(repo Repository, tax TaxCalculator, defaultTaxRegion string, payments PaymentClient) Service {
+	return &orderService{repo: repo, tax: tax, defaultTaxRegion: normalizeTaxRegion(defaultTaxRegion), payments: payments}
 }
 
-func (s *orderService) PostOrder(ctx context.Context, accountID string, products []*OrderedProduct) (*Order, error) {
+// NewOrder prices a pending order without storing it, so that resources can
+// be reserved under its ID before the order is placed with PostOrder. The
+// coupons are applied in the order given, and their use only counted once the
+// order is stored; tax is charged on what the lines cost after them, in
+// taxRegion or else where the order ships to. Where it ships to may be a
+// region without tax rates, in which case the order is taxed as if it shipped
+// nowhere: in the default region, if there is one. A shipping address taken
+// from the address book must already have been copied from it.
+func (s *orderService) NewOrder(ctx context.Context, orderID, accountID string, products []*OrderedProduct, couponCodes []string, taxRegion string, shipping *Address, idempotencyKey string) (*Order, error) {
+	codes, err := normalizeCouponCodes(couponCodes)
+	if err != nil {
+		return nil, err
+	}
+	shipping, err = normalizeShippingAddress(shipping)
+	if err != nil {
+		return nil, err
+	}
+	if shipping != nil && shipping.Name == "" {
+		return nil, fmt.Errorf("%w: shipping address %s was not resolved", ErrInvalidOrder, shipping.AddressID)
+	}
+	subtotal, err := orderSubtotal(products)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if len(codes) > 0 {
+		coupons, err := s.repo.GetCoupons(ctx, codes)
+		if err != nil {
+			return nil, err
+		}
+		if err := applyCoupons(products, coupons, now); err != nil {
+			return nil, err
+		}
+	}
+
+	requestedRegion := normalizeTaxRegion(taxRegion)
+	region := requestedRegion
+	if region == "" && shipping != nil {
+		region = shipping.taxRegion()
+	}
+	if region == "" {
+		region = s.defaultTaxRegion
+	}
+	tax, err := s.taxOrder(ctx, region, products)
+	if errors.Is(err, ErrNoTaxRate) && requestedRegion == "" && region != s.defaultTaxRegion {
+		region = s.defaultTaxRegion
+		tax, err = s.taxOrder(ctx, region, products)
+	}
+	if err != nil {
+		return nil, err
+	}
+	total := subtotal
+	for _, p := range products {
+		total.Units += p.Tax.Units - p.Discount().Units
+	}
+
 	order := &Order{
-		ID:        ksuid.New().String(),
-		AccountID: accountID,
-		CreatedAt: time.Now().UTC(),
-		Products:  products,
+		ID:              orderID,
+		AccountID:       accountID,
+		CreatedAt:       now,
+		Subtotal:        subtotal,
+		Tax:             tax,
+		Total:           total,
+		TaxRegion:       region,
+		ShippingAddress: shipping,
+		Products:        products,
+		CouponCodes:     codes,
+		Status:          StatusPending,
+		StatusHistory:   []*StatusChange{{Status: StatusPending, ChangedAt: now}},
+		IdempotencyKey:  idempotencyKey,
+		RequestHash:     requestHash(accountID, products, codes, requestedRegion, shipping),
 	}
-	// Note: Total calculation should be done at the server layer where we have product prices
-	order.Total = 0.0
+	return order, nil
+}
 
+// taxOrder sets the tax of every line of an order taxed in region and returns
+// their sum. Without a region nothing is taxed.
+func (s *orderService) taxOrder(ctx context.Context, region string, products []*OrderedProduct) (money.Money, error) {
+	tax := money.New(0, products[0].Price.Currency)
+	for _, p := range products {
+		p.Tax = money.New(0, p.Price.Currency)
+	}
+	if region == "" {
+		return tax, nil
+	}
+
+	lines := make([]*TaxLine, 0, len(products))
+	for _, p := range products {
+		amount, err := p.Total()
+		if err != nil {
+			return money.Money{}, err
+		}
+		lines = append(lines, &TaxLine{ProductID: p.ProductID, SKU: p.SKU, TaxClass: p.taxClass, Amount: amount})
+	}
+	taxes, err := s.tax.Tax(ctx, region, lines)
+	if err != nil {
+		return money.Money{}, err
+	}
+	if len(taxes) != len(products) {
+		return money.Money{}, fmt.Errorf("tax calculator returned %d amounts for %d lines", len(taxes), len(products))
+	}
+	for i, p := range products {
+		if taxes[i].Currency != tax.Currency || taxes[i].IsNegative() {
+			return money.Money{}, fmt.Errorf("tax calculator returned %s %s for %s", taxes[i].Decimal(), taxes[i].Currency, lineKey{p.ProductID, p.SKU})
+		}
+		p.Tax = taxes[i]
+		if tax, err = tax.Add(taxes[i]); err != nil {
+			return money.Money{}, err
+		}
+	}
+	return tax, nil
+}
+
+// AuthorizePayment holds the total of an order built by NewOrder on the
+// customer's payment method and records the payment on the order. Orders with
+// nothing to pay are left alone. Authorizing the same order again returns the
+// payment already made.
+func (s *orderService) AuthorizePayment(ctx context.Context, order *Order, method string) error {
+	if order.Total.IsZero() {
+		return nil
+	}
+	p, err := s.payments.Authorize(ctx, order.ID, order.AccountID, order.Total, method)
+	if err != nil {
+		return err
+	}
+	order.PaymentID = p.ID
+	return nil
+}
+
+// VoidPayment releases the payment authorized for an order, if there is one.
+func (s *orderService) VoidPayment(ctx context.Context, orderID string) error {
+	p, err := s.payments.GetPaymentForOrder(ctx, orderID)
+	if status.Code(err) == codes.NotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, err = s.payments.Void(ctx, p.ID)
+	return err
+}
+
+// PostOrder stores an order built by NewOrder. When the order carries an
+// idempotency key that was already used, the original order is returned
+// instead, so callers must compare IDs to know whether theirs was stored.
+func (s *orderService) PostOrder(ctx context.Context, order *Order) (*Order, error) {
 	if err := s.repo.PutOrder(ctx, order); err != nil {
+		if errors.Is(err, ErrDuplicateIdempotencyKey) {
+			// Lost a race with a concurrent request carrying the same key.
+			return s.replayOrder(ctx, order.AccountID, order.IdempotencyKey, order.RequestHash)
+		}
 		return nil, err
 	}
 	return order, nil
 }
 
+// ReplayOrder returns the order the account already placed under
+// idempotencyKey. It fails with ErrNotFound if there is none, and with
+// ErrIdempotencyKeyReused if that order was placed with a different payload.
+func (s *orderService) ReplayOrder(ctx context.Context, accountID string, products []*OrderedProduct, couponCodes []string, taxRegion string, shipping *Address, idempotencyKey string) (*Order, error) {
+	if idempotencyKey == "" {
+		return nil, ErrNotFound
+	}
+	codes, err := normalizeCouponCodes(couponCodes)
+	if err != nil {
+		return nil, err
+	}
+	shipping, err = normalizeShippingAddress(shipping)
+	if err != nil {
+		return nil, err
+	}
+	return s.replayOrder(ctx, accountID, idempotencyKey, requestHash(accountID, products, codes, normalizeTaxRegion(taxRegion), shipping))
+}
+
+// replayOrder returns the order placed under idempotencyKey if its request
+// hashed to hash.
+func (s *orderService) replayOrder(ctx context.Context, accountID, idempotencyKey, hash string) (*Order, error) {
+	order, err := s.repo.GetOrderByIdempotencyKey(ctx, accountID, idempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+	if order.RequestHash != hash {
+		return nil, ErrIdempotencyKeyReused
+	}
+	return order, nil
+}
+
 func (s *orderService) GetOrdersForAccount(ctx context.Context, accountID string) ([]*Order, error) {
 	return s.repo.GetOrdersForAccount(ctx, accountID)
 }
+
+func (s *orderService) GetOrder(ctx context.Context, orderID string) (*Order, error) {
+	return s.repo.GetOrder(ctx, orderID)
+}
+
+// UpdateOrderStatus moves an order to a new status if the transition is allowed
+// and records it in the order's status history. Shipping an order first moves
+// it to shipping, out of reach of CancelOrder, and then captures its payment,
+// less anything refunded, before it ships. If the capture fails the order
+// stays shipping: shipping it again retries the capture, and moving it back to
+// paid gives up. Shipping an order that already shipped returns it unchanged,
+// so that what follows the shipment can be retried.
+func (s *orderService) UpdateOrderStatus(ctx context.Context, orderID string, status OrderStatus) (*Order, error) {
+	if !status.Valid() {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownStatus, status)
+	}
+	switch status {
+	case StatusCancelled:
+		return nil, fmt.Errorf("%w: use CancelOrder to cancel an order", ErrInvalidTransition)
+	case StatusShipping:
+		return nil, fmt.Errorf("%w: orders are shipping only while they ship", ErrInvalidTransition)
+	}
+	order, err := s.repo.GetOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if status == StatusShipped && order.Status == StatusShipped {
+		return order, nil
+	}
+	if !order.Status.CanTransitionTo(status) {
+		return nil, fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, order.Status, status)
+	}
+	if status == StatusShipped {
+		if order.Status != StatusShipping {
+			if err := s.setStatus(ctx, order, StatusShipping); err != nil {
+				return nil, err
+			}
+			// Read the refunds again: one may have been made before the order
+			// left paid
+			if order, err = s.repo.GetOrder(ctx, orderID); err != nil {
+				return nil, err
+			}
+		}
+		if err := s.capturePayment(ctx, order); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.setStatus(ctx, order, status); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// setStatus moves an order to status, provided it is still in the status it
+// was read in, and records the change.
+func (s *orderService) setStatus(ctx context.Context, order *Order, status OrderStatus) error {
+	change := &StatusChange{Status: status, ChangedAt: time.Now().UTC()}
+	if err := s.repo.UpdateOrderStatus(ctx, order.ID, order.Status, change); err != nil {
+		return err
+	}
+	order.Status = status
+	order.StatusHistory = append(order.StatusHistory, change)
+	return nil
+}
+
+// CancelOrder cancels an order and voids its payment. If the order was already
+// paid, everything not yet refunded is refunded in the same step.
+func (s *orderService) CancelOrder(ctx context.Context, orderID string, reason string) (*Order, error) {
+	order, err := s.repo.GetOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if !order.Status.CanTransitionTo(StatusCancelled) {
+		return nil, fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, order.Status, StatusCancelled)
+	}
+
+	var refund *Refund
+	if order.Status.Refundable() {
+		refund, err = newRefund(order, nil, reason)
+		if err != nil && !errors.Is(err, ErrNothingToRefund) {
+			return nil, err
+		}
+	}
+
+	change := &StatusChange{Status: StatusCancelled, ChangedAt: time.Now().UTC()}
+	if err := s.repo.CancelOrder(ctx, orderID, order.Status, change, refund); err != nil {
+		return nil, err
+	}
+	order.Status = StatusCancelled
+	order.StatusHistory = append(order.StatusHistory, change)
+	if refund != nil {
+		order.Refunds = append(order.Refunds, refund)
+	}
+	if order.PaymentID != "" {
+		// Orders are only captured once shipped, so there is nothing to give back
+		if _, err := s.payments.Void(context.WithoutCancel(ctx), order.PaymentID); err != nil {
+			log.Printf("failed to void payment %s of cancelled order %s: %v", order.PaymentID, order.ID, err)
+		}
+	}
+	return order, nil
+}
+
+// RefundOrder refunds the given lines of a paid order, or everything not yet
+// refunded when no lines are given. The money goes back through the payment
+// once it has been captured; before that, the capture takes less.
+//
+// A refund through the payment is recorded as pending before any money moves,
+// under its own ID as the idempotency key. If the payment refuses it, it is
+// removed again; if the payment's answer is lost, the refund is returned
+// still pending and SettleRefunds finishes it.
+func (s *orderService) RefundOrder(ctx context.Context, orderID string, lines []*RefundLine, reason string) (*Order, error) {
+	order, err := s.repo.GetOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if !order.Status.Refundable() {
+		return nil, fmt.Errorf("%w: order is %s", ErrNotRefundable, order.Status)
+	}
+
+	refund, err := newRefund(order, lines, reason)
+	if err != nil {
+		return nil, err
+	}
+	refund.Pending = order.PaymentID != "" && order.Status != StatusPaid && !refund.Amount().IsZero()
+	if err := s.repo.PutRefund(ctx, order.Status, refund); err != nil {
+		return nil, err
+	}
+	order.Refunds = append(order.Refunds, refund)
+	if !refund.Pending {
+		return order, nil
+	}
+	err = s.settleRefund(context.WithoutCancel(ctx), order.PaymentID, refund)
+	if errors.Is(err, errPaymentRefused) {
+		return nil, err
+	}
+	if err != nil {
+		log.Printf("refund %s of order %s left pending: %v", refund.ID, order.ID, err)
+	}
+	return order, nil
+}
+
+// refundTimeout is how long a refund may stay pending before SettleRefunds
+// retries it.
+const refundTimeout = time.Minute
+
+// errPaymentRefused wraps the payment service's answer when it refuses a
+// refund, which then moved no money.
+var errPaymentRefused = errors.New("payment refused the refund")
+
+// settleRefund returns the money of a pending refund through the payment and
+// completes the refund, or removes it if the payment refuses.
+func (s *orderService) settleRefund(ctx context.Context, paymentID string, refund *Refund) error {
+	_, err := s.payments.Refund(ctx, paymentID, refund.Amount(), refund.ID)
+	switch status.Code(err) {
+	case codes.OK:
+		return s.repo.CompleteRefund(ctx, refund)
+	case codes.FailedPrecondition, codes.InvalidArgument:
+		if deleteErr := s.repo.DeleteRefund(ctx, refund.ID); deleteErr != nil {
+			return deleteErr
+		}
+		return fmt.Errorf("%w: %w", errPaymentRefused, err)
+	}
+	return err
+}
+
+// SettleRefunds retries the refunds left pending for longer than idle. Each
+// goes to the payment under the same idempotency key as before, so money that
+// did go back is not returned twice.
+func (s *orderService) SettleRefunds(ctx context.Context, idle time.Duration) error {
+	orders, err := s.repo.ListOrdersWithPendingRefunds(ctx, time.Now().UTC().Add(-idle))
+	if err != nil {
+		return err
+	}
+	for _, order := range orders {
+		for _, refund := range order.Refunds {
+			if !refund.Pending {
+				continue
+			}
+			if err := s.settleRefund(ctx, order.PaymentID, refund); err != nil {
+				log.Printf("failed to settle refund %s of order %s: %v", refund.ID, order.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// capturePayment takes the payment of an order about to ship: its total less
+// what was refunded, or nothing at all if that is everything, in which case
+// the payment is voided.
+func (s *orderService) capturePayment(ctx context.Context, order *Order) error {
+	if order.PaymentID == "" {
+		return nil
+	}
+	amount := order.Total
+	amount.Units -= order.RefundedAmount().Units
+	var err error
+	if amount.Units > 0 {
+		_, err = s.payments.Capture(ctx, order.PaymentID, amount)
+	} else {
+		_, err = s.payments.Void(ctx, order.PaymentID)
+	}
+	return err
+}
+
+// orderSubtotal sums price × quantity over the order lines, before discounts.
+// All lines must be priced in the same currency.
+func orderSubtotal(products []*OrderedProduct) (money.Money, error) {
+	if len(products) == 0 {
+		return money.Money{}, fmt.Errorf("%w: no products", ErrInvalidOrder)
+	}
+	total := money.New(0, products[0].Price.Currency)
+	for _, p := range products {
+		if p.Quantity <= 0 {
+			return money.Money{}, fmt.Errorf("%w: quantity of product %s must be positive", ErrInvalidOrder, p.ProductID)
+		}
+		line, err := p.Price.Mul(int64(p.Quantity))
+		if err != nil {
+			return money.Money{}, err
+		}
+		if total, err = total.Add(line); err != nil {
+			return money.Money{}, fmt.Errorf("%w: %v", ErrInvalidOrder, err)
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,69 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"microservice/money"
+)
+
+func TestNewOrderTaxRegion(t *testing.T) {
+	tests := []struct {
+		name          string
+		defaultRegion string
+		taxRegion     string
+		shipsTo       *Address
+		wantRegion    string
+		wantTax       int64
+		wantErr       error
+	}{
+		{name: "shipping subdivision with rules", shipsTo: shipTo("US", "CA"), wantRegion: "US-CA", wantTax: 725},
+		{name: "shipping region without rules falls back to default", defaultRegion: "DE", shipsTo: shipTo("US", "WA"), wantRegion: "DE", wantTax: 1900},
+		{name: "shipping country without rules and no default", shipsTo: shipTo("IT", ""), wantRegion: "", wantTax: 0},
+		{name: "requested region without rules", defaultRegion: "DE", taxRegion: "IT", wantErr: ErrNoTaxRate},
+	}
+	calculator, err := NewTableTaxCalculator(DefaultTaxRules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewOrderService(nil, calculator, tt.defaultRegion, nil)
+			products := []*OrderedProduct{{ProductID: "p1", Price: money.New(10000, "USD"), Quantity: 1}}
+
+			order, err := service.NewOrder(context.Background(), "o1", "a1", products, nil, tt.taxRegion, tt.shipsTo, "")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewOrder error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if order.TaxRegion != tt.wantRegion {
+				t.Errorf("tax region = %q, want %q", order.TaxRegion, tt.wantRegion)
+			}
+			if order.Tax.Units != tt.wantTax {
+				t.Errorf("tax = %d, want %d", order.Tax.Units, tt.wantTax)
+			}
+		})
+	}
+}
+
+func shipTo(country, region string) *Address {
+	return &Address{Name: "Ada", Line1: "1 Main St", City: "Springfield", Region: region, Country: country}
+}
+
+func TestShippingShippedOrderAgain(t *testing.T) {
+	repo := &memoryRepository{orders: map[string]*Order{
+		"o1": {ID: "o1", Status: StatusShipped, Total: money.New(1000, "USD"), PaymentID: "p1"},
+	}}
+	service := NewOrderService(repo, nil, "", nil)
+
+	order, err := service.UpdateOrderStatus(context.Background(), "o1", StatusShipped)
+	if err != nil {
+		t.Fatalf("shipping again: %v", err)
+	}
+	if order.Status != StatusShipped || len(order.StatusHistory) != 0 {
+		t.Errorf("order = %s with %d status changes, want it shipped and unchanged", order.Status, len(order.StatusHistory))
+	}
+}
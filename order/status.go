@@ -0,0 +1,69 @@
+package order
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrUnknownStatus     = errors.New("unknown order status")
+	ErrInvalidTransition = errors.New("invalid order status transition")
+)
+
+// OrderStatus is the lifecycle state of an order. An order is shipping while
+// its payment is captured, on its way to shipped; it cannot be cancelled then,
+// so no cancellation can slip in between the capture and the order shipping.
+type OrderStatus string
+
+const (
+	StatusPending   OrderStatus = "pending"
+	StatusPaid      OrderStatus = "paid"
+	StatusShipping  OrderStatus = "shipping"
+	StatusShipped   OrderStatus = "shipped"
+	StatusDelivered OrderStatus = "delivered"
+	StatusCancelled OrderStatus = "cancelled"
+)
+
+// orderTransitions lists the statuses each status may move to. Shipping goes
+// back to paid only when the capture cannot be made, so that the order can be
+// cancelled.
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	StatusPending:   {StatusPaid, StatusCancelled},
+	StatusPaid:      {StatusShipped, StatusCancelled},
+	StatusShipping:  {StatusShipped, StatusPaid},
+	StatusShipped:   {StatusDelivered},
+	StatusDelivered: {},
+	StatusCancelled: {},
+}
+
+// StatusChange records a single transition in an order's lifecycle.
+type StatusChange struct {
+	Status    OrderStatus `json:"status"`
+	ChangedAt time.Time   `json:"changed_at"`
+}
+
+// Valid reports whether s is a known order status.
+func (s OrderStatus) Valid() bool {
+	_, ok := orderTransitions[s]
+	return ok
+}
+
+// CanTransitionTo reports whether an order in status s may move to next.
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, allowed := range orderTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Refundable reports whether money taken for an order in status s may be
+// returned to the customer.
+func (s OrderStatus) Refundable() bool {
+	switch s {
+	case StatusPaid, StatusShipped, StatusDelivered:
+		return true
+	}
+	return false
+}
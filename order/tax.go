@@ -0,0 +1,145 @@
+package order
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"microservice/money"
+)
+
+var (
+	ErrInvalidTaxRule = errors.New("invalid tax rule")
+	ErrNoTaxRate      = errors.New("no tax rate for region")
+)
+
+// TaxClassStandard is the tax class of products that do not name one, and the
+// rate charged on classes a region has no rule for.
+const TaxClassStandard = "standard"
+
+// TaxLine is a line of an order as a TaxCalculator sees it.
+type TaxLine struct {
+	ProductID string
+	SKU       string
+	TaxClass  string
+	// Amount is what the line costs after discounts.
+	Amount money.Money
+}
+
+// TaxCalculator works out the tax owed on the lines of an order.
+type TaxCalculator interface {
+	// Tax returns the tax on each line, in the order given, of an order
+	// taxed in region.
+	Tax(ctx context.Context, region string, lines []*TaxLine) ([]money.Money, error)
+}
+
+// TaxRule is the rate charged on a tax class in a region.
+type TaxRule struct {
+	// Region is a country code such as "DE", or a subdivision such as
+	// "US-CA". Subdivisions without rules of their own use their country's.
+	Region   string `json:"region"`
+	TaxClass string `json:"tax_class"`
+	// Rate is in basis points: 1925 is 19.25%.
+	Rate int `json:"rate"`
+}
+
+// DefaultTaxRules are the rules used when no others are configured. They
+// cover a few regions as an example rather than as tax advice.
+var DefaultTaxRules = []TaxRule{
+	{Region: "DE", TaxClass: TaxClassStandard, Rate: 1900},
+	{Region: "DE", TaxClass: "reduced", Rate: 700},
+	{Region: "DE", TaxClass: "zero", Rate: 0},
+	{Region: "FR", TaxClass: TaxClassStandard, Rate: 2000},
+	{Region: "FR", TaxClass: "reduced", Rate: 550},
+	{Region: "FR", TaxClass: "zero", Rate: 0},
+	{Region: "GB", TaxClass: TaxClassStandard, Rate: 2000},
+	{Region: "GB", TaxClass: "reduced", Rate: 500},
+	{Region: "GB", TaxClass: "zero", Rate: 0},
+	{Region: "US-CA", TaxClass: TaxClassStandard, Rate: 725},
+	{Region: "US-CA", TaxClass: "zero", Rate: 0},
+	{Region: "US-NY", TaxClass: TaxClassStandard, Rate: 400},
+	{Region: "US-NY", TaxClass: "zero", Rate: 0},
+	{Region: "US-TX", TaxClass: TaxClassStandard, Rate: 625},
+	{Region: "US-TX", TaxClass: "zero", Rate: 0},
+}
+
+// LoadTaxRules reads a JSON array of tax rules.
+func LoadTaxRules(r io.Reader) ([]TaxRule, error) {
+	var rules []TaxRule
+	if err := json.NewDecoder(r).Decode(&rules); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTaxRule, err)
+	}
+	return rules, nil
+}
+
+// tableTaxCalculator charges each line the rate of its tax class in the
+// order's region, looked up in a table of rules.
+type tableTaxCalculator struct {
+	// rates maps regions to tax classes to rates in basis points.
+	rates map[string]map[string]int
+}
+
+// NewTableTaxCalculator returns a TaxCalculator that looks rates up in rules.
+func NewTableTaxCalculator(rules []TaxRule) (TaxCalculator, error) {
+	rates := map[string]map[string]int{}
+	for _, rule := range rules {
+		region := normalizeTaxRegion(rule.Region)
+		if region == "" || rule.TaxClass == "" {
+			return nil, fmt.Errorf("%w: region and tax class are required", ErrInvalidTaxRule)
+		}
+		if rule.Rate < 0 || rule.Rate > 10000 {
+			return nil, fmt.Errorf("%w: rate of %s in %s must be between 0 and 10000 basis points", ErrInvalidTaxRule, rule.TaxClass, region)
+		}
+		if rates[region] == nil {
+			rates[region] = map[string]int{}
+		}
+		if _, dup := rates[region][rule.TaxClass]; dup {
+			return nil, fmt.Errorf("%w: %s in %s is listed more than once", ErrInvalidTaxRule, rule.TaxClass, region)
+		}
+		rates[region][rule.TaxClass] = rule.Rate
+	}
+	return &tableTaxCalculator{rates: rates}, nil
+}
+
+func (t *tableTaxCalculator) Tax(ctx context.Context, region string, lines []*TaxLine) ([]money.Money, error) {
+	rates, ok := t.rates[region]
+	if !ok {
+		if country, _, found := strings.Cut(region, "-"); found {
+			rates, ok = t.rates[country]
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoTaxRate, region)
+	}
+
+	taxes := make([]money.Money, 0, len(lines))
+	for _, l := range lines {
+		class := l.TaxClass
+		if class == "" {
+			class = TaxClassStandard
+		}
+		rate, ok := rates[class]
+		if !ok {
+			rate, ok = rates[TaxClassStandard]
+		}
+		if !ok {
+			return nil, fmt.Errorf("%w: %s has no %s or standard rate", ErrNoTaxRate, region, class)
+		}
+		taxes = append(taxes, money.New(applyRate(l.Amount.Units, rate), l.Amount.Currency))
+	}
+	return taxes, nil
+}
+
+// applyRate returns rate basis points of units, rounded half up. The amount
+// is split to keep the multiplication from overflowing.
+func applyRate(units int64, rate int) int64 {
+	return units/10000*int64(rate) + (units%10000*int64(rate)+5000)/10000
+}
+
+// normalizeTaxRegion upper-cases a region code such as "us-ca".
+func normalizeTaxRegion(region string) string {
+	return strings.ToUpper(strings.TrimSpace(region))
+}
@@ -0,0 +1,32 @@
+package order
+
+import (
+	"math"
+	"testing"
+)
+
+func TestApplyRate(t *testing.T) {
+	tests := []struct {
+		units int64
+		rate  int
+		want  int64
+	}{
+		{0, 1900, 0},
+		{10000, 725, 725},
+		{100, 1900, 19},
+		{999, 725, 72},
+		{1069, 725, 78},
+		{200, 725, 15},
+		{1, 5000, 1},
+		{1, 4999, 0},
+		{3, 1666, 0},
+		{12345, 0, 0},
+		{math.MaxInt64, 10000, math.MaxInt64},
+		{math.MaxInt64, 5000, math.MaxInt64/2 + 1},
+	}
+	for _, tt := range tests {
+		if got := applyRate(tt.units, tt.rate); got != tt.want {
+			t.Errorf("applyRate(%d, %d) = %d, want %d", tt.units, tt.rate, got, tt.want)
+		}
+	}
+}
@@ -1,14 +1,243 @@
+-- This file creates the schema on a new database and brings a database made
+-- by an earlier version of it up to date, so it is safe to run again: each
+-- table is followed by the changes it went through, which are no-ops once
+-- made.
 
 CREATE TABLE IF NOT EXISTS orders (
     id CHAR(27) PRIMARY KEY,
     created_at TIMESTAMP WITH TIME ZONE NOT NULL,
     account_id CHAR(27) NOT NULL,
-    total_price MONEY NOT NULL
+    -- Lines before discounts; the total is the subtotal less discounts plus tax.
+    subtotal_units BIGINT NOT NULL,
+    tax_units BIGINT NOT NULL DEFAULT 0,
+    total_units BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    -- Region the order was taxed in; empty if it was not taxed.
+    tax_region VARCHAR(16) NOT NULL DEFAULT '',
+    -- Copy of the address the order ships to; null if it ships nowhere.
+    shipping_address JSONB,
+    -- Payment authorized for the order; empty if there was nothing to pay.
+    payment_id VARCHAR(27) NOT NULL DEFAULT '',
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    idempotency_key VARCHAR(255),
+    request_hash CHAR(64) NOT NULL DEFAULT '',
+    -- Coupons applied to the order, in the order they were applied.
+    coupon_codes TEXT[] NOT NULL DEFAULT '{}',
+    CONSTRAINT orders_account_idempotency_key UNIQUE (account_id, idempotency_key)
 );
 
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS subtotal_units BIGINT;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS tax_units BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS total_units BIGINT;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS currency CHAR(3);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS tax_region VARCHAR(16) NOT NULL DEFAULT '';
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS shipping_address JSONB;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS payment_id VARCHAR(27) NOT NULL DEFAULT '';
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'pending';
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS idempotency_key VARCHAR(255);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS request_hash CHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS coupon_codes TEXT[] NOT NULL DEFAULT '{}';
+
+DO $$
+BEGIN
+    -- Totals used to be MONEY, which is formatted by lc_monetary: en_US in
+    -- the postgres image, so they are US dollars with two decimals.
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_schema = current_schema() AND table_name = 'orders' AND column_name = 'total_price') THEN
+        UPDATE orders SET total_units = round(total_price::numeric * 100), currency = 'USD';
+        ALTER TABLE orders DROP COLUMN total_price;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'orders_account_idempotency_key') THEN
+        ALTER TABLE orders ADD CONSTRAINT orders_account_idempotency_key UNIQUE (account_id, idempotency_key);
+    END IF;
+END $$;
+
+-- Orders from before tax and discounts cost what their lines did.
+UPDATE orders SET subtotal_units = total_units WHERE subtotal_units IS NULL;
+ALTER TABLE orders ALTER COLUMN subtotal_units SET NOT NULL;
+ALTER TABLE orders ALTER COLUMN total_units SET NOT NULL;
+ALTER TABLE orders ALTER COLUMN currency SET NOT NULL;
+
 CREATE TABLE IF NOT EXISTS order_products (
     order_id CHAR(27) REFERENCES orders (id) ON DELETE CASCADE,
     product_id CHAR(27) NOT NULL,
+    -- Empty for products sold without variants.
+    sku VARCHAR(64) NOT NULL DEFAULT '',
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    options JSONB NOT NULL DEFAULT '{}',
+    price_units BIGINT NOT NULL,
     quantity INT NOT NULL,
-    PRIMARY KEY (product_id, order_id)
-);
\ No newline at end of file
+    tax_units BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (product_id, sku, order_id)
+);
+
+ALTER TABLE order_products ADD COLUMN IF NOT EXISTS sku VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE order_products ADD COLUMN IF NOT EXISTS name VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE order_products ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '';
+ALTER TABLE order_products ADD COLUMN IF NOT EXISTS options JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE order_products ADD COLUMN IF NOT EXISTS price_units BIGINT;
+ALTER TABLE order_products ADD COLUMN IF NOT EXISTS tax_units BIGINT NOT NULL DEFAULT 0;
+
+DO $$
+DECLARE
+    fk RECORD;
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_schema = current_schema() AND table_name = 'order_products' AND column_name = 'price') THEN
+        UPDATE order_products SET price_units = round(price::numeric * 100);
+        ALTER TABLE order_products DROP COLUMN price;
+    END IF;
+    -- The SKU joined the key. The refund lines referring to the old key are
+    -- pointed at the new one below.
+    IF NOT EXISTS (SELECT 1 FROM information_schema.key_column_usage
+                   WHERE table_schema = current_schema() AND constraint_name = 'order_products_pkey' AND column_name = 'sku') THEN
+        FOR fk IN SELECT conrelid::regclass AS tbl, conname FROM pg_constraint
+                  WHERE contype = 'f' AND confrelid = 'order_products'::regclass LOOP
+            EXECUTE format('ALTER TABLE %s DROP CONSTRAINT %I', fk.tbl, fk.conname);
+        END LOOP;
+        ALTER TABLE order_products DROP CONSTRAINT order_products_pkey;
+        ALTER TABLE order_products ADD PRIMARY KEY (product_id, sku, order_id);
+    END IF;
+END $$;
+
+-- Lines from before prices were copied onto orders have no price to convert.
+UPDATE order_products SET price_units = 0 WHERE price_units IS NULL;
+ALTER TABLE order_products ALTER COLUMN price_units SET NOT NULL;
+
+-- Promotions customers redeem by code. Only the columns of the coupon's kind
+-- are set: percent_off, amount_units and currency, or buy and get quantities.
+CREATE TABLE IF NOT EXISTS coupons (
+    code VARCHAR(64) PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    kind VARCHAR(16) NOT NULL,
+    percent_off INT NOT NULL DEFAULT 0,
+    amount_units BIGINT NOT NULL DEFAULT 0,
+    currency CHAR(3) NOT NULL DEFAULT '',
+    buy_quantity INT NOT NULL DEFAULT 0,
+    get_quantity INT NOT NULL DEFAULT 0,
+    category_ids TEXT[] NOT NULL DEFAULT '{}',
+    -- 0 means no limit.
+    usage_limit INT NOT NULL DEFAULT 0,
+    usage_count INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- What each coupon took off each line of an order, in the order's currency.
+CREATE TABLE IF NOT EXISTS order_discounts (
+    order_id CHAR(27) NOT NULL,
+    product_id CHAR(27) NOT NULL,
+    sku VARCHAR(64) NOT NULL DEFAULT '',
+    coupon_code VARCHAR(64) NOT NULL REFERENCES coupons (code),
+    amount_units BIGINT NOT NULL CHECK (amount_units > 0),
+    PRIMARY KEY (order_id, product_id, sku, coupon_code),
+    FOREIGN KEY (product_id, sku, order_id) REFERENCES order_products (product_id, sku, order_id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS order_status_history (
+    id SERIAL PRIMARY KEY,
+    order_id CHAR(27) REFERENCES orders (id) ON DELETE CASCADE,
+    status VARCHAR(16) NOT NULL,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS order_status_history_order_id_idx ON order_status_history (order_id);
+
+CREATE TABLE IF NOT EXISTS order_refunds (
+    id CHAR(27) PRIMARY KEY,
+    order_id CHAR(27) REFERENCES orders (id) ON DELETE CASCADE,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    -- Set until the payment confirms the money went back.
+    pending BOOLEAN NOT NULL DEFAULT false
+);
+
+ALTER TABLE order_refunds ADD COLUMN IF NOT EXISTS pending BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS order_refunds_pending_idx ON order_refunds (created_at) WHERE pending;
+
+CREATE TABLE IF NOT EXISTS order_refund_lines (
+    refund_id CHAR(27) REFERENCES order_refunds (id) ON DELETE CASCADE,
+    order_id CHAR(27) NOT NULL,
+    product_id CHAR(27) NOT NULL,
+    sku VARCHAR(64) NOT NULL DEFAULT '',
+    quantity INT NOT NULL CHECK (quantity > 0),
+    amount_units BIGINT NOT NULL,
+    PRIMARY KEY (refund_id, product_id, sku),
+    FOREIGN KEY (product_id, sku, order_id) REFERENCES order_products (product_id, sku, order_id) ON DELETE CASCADE
+);
+
+ALTER TABLE order_refund_lines ADD COLUMN IF NOT EXISTS sku VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE order_refund_lines ADD COLUMN IF NOT EXISTS amount_units BIGINT;
+
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_schema = current_schema() AND table_name = 'order_refund_lines' AND column_name = 'amount') THEN
+        UPDATE order_refund_lines SET amount_units = round(amount::numeric * 100);
+        ALTER TABLE order_refund_lines DROP COLUMN amount;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM information_schema.key_column_usage
+                   WHERE table_schema = current_schema() AND constraint_name = 'order_refund_lines_pkey' AND column_name = 'sku') THEN
+        ALTER TABLE order_refund_lines DROP CONSTRAINT order_refund_lines_pkey;
+        ALTER TABLE order_refund_lines ADD PRIMARY KEY (refund_id, product_id, sku);
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint
+                   WHERE contype = 'f' AND conrelid = 'order_refund_lines'::regclass AND confrelid = 'order_products'::regclass) THEN
+        ALTER TABLE order_refund_lines
+            ADD FOREIGN KEY (product_id, sku, order_id) REFERENCES order_products (product_id, sku, order_id) ON DELETE CASCADE;
+    END IF;
+END $$;
+
+ALTER TABLE order_refund_lines ALTER COLUMN amount_units SET NOT NULL;
+
+CREATE TABLE IF NOT EXISTS order_sagas (
+    id CHAR(27) PRIMARY KEY,
+    account_id CHAR(27) NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL DEFAULT '',
+    items JSONB NOT NULL,
+    coupon_codes TEXT[] NOT NULL DEFAULT '{}',
+    tax_region VARCHAR(16) NOT NULL DEFAULT '',
+    shipping_address JSONB,
+    state VARCHAR(16) NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+ALTER TABLE order_sagas ADD COLUMN IF NOT EXISTS coupon_codes TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE order_sagas ADD COLUMN IF NOT EXISTS tax_region VARCHAR(16) NOT NULL DEFAULT '';
+ALTER TABLE order_sagas ADD COLUMN IF NOT EXISTS shipping_address JSONB;
+
+CREATE INDEX IF NOT EXISTS order_sagas_state_idx ON order_sagas (state, updated_at);
+
+CREATE TABLE IF NOT EXISTS order_saga_steps (
+    id SERIAL PRIMARY KEY,
+    saga_id CHAR(27) REFERENCES order_sagas (id) ON DELETE CASCADE,
+    step VARCHAR(32) NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS order_saga_steps_saga_id_idx ON order_saga_steps (saga_id);
+
+-- Events are written here in the same transaction as the change they describe
+-- and moved to order_events by the relay.
+CREATE TABLE IF NOT EXISTS order_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    order_id CHAR(27) NOT NULL,
+    type VARCHAR(32) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Published events; id is the offset subscribers resume from.
+CREATE TABLE IF NOT EXISTS order_events (
+    id BIGSERIAL PRIMARY KEY,
+    order_id CHAR(27) NOT NULL,
+    type VARCHAR(32) NOT NULL,
+    payload JSONB NOT NULL,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    published_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
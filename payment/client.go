@@ -0,0 +1,99 @@
+package payment
+
+import (
+	"context"
+
+	"microservice/auth"
+	"microservice/money"
+	pb "microservice/payment/pb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+type Client struct {
+	conn    *grpc.ClientConn
+	service pb.PaymentServiceClient
+}
+
+// NewServiceClient returns a client that calls the payment service as the
+// named service, with tokens signed by signer. Only services may call it.
+func NewServiceClient(url string, signer *auth.Signer, name string) (*Client, error) {
+	conn, err := grpc.Dial(url,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(auth.ServiceUnaryClientInterceptor(signer, name)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	service := pb.NewPaymentServiceClient(conn)
+	return &Client{conn: conn, service: service}, nil
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Authorize holds amount on the payment method for an order. Declines fail
+// with codes.FailedPrecondition and provider timeouts with codes.Unavailable,
+// carrying an ErrorInfo with reason ReasonDeclined or ReasonProviderTimeout.
+func (c *Client) Authorize(ctx context.Context, orderID, accountID string, amount money.Money, method string) (*Payment, error) {
+	resp, err := c.service.Authorize(ctx, &pb.AuthorizeRequest{
+		OrderId:       orderID,
+		AccountId:     accountID,
+		Amount:        money.ToProto(amount),
+		PaymentMethod: method,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return paymentFromProto(resp.Payment), nil
+}
+
+func (c *Client) Capture(ctx context.Context, paymentID string, amount money.Money) (*Payment, error) {
+	resp, err := c.service.Capture(ctx, &pb.CaptureRequest{PaymentId: paymentID, Amount: money.ToProto(amount)})
+	if err != nil {
+		return nil, err
+	}
+	return paymentFromProto(resp.Payment), nil
+}
+
+func (c *Client) Void(ctx context.Context, paymentID string) (*Payment, error) {
+	resp, err := c.service.Void(ctx, &pb.VoidRequest{PaymentId: paymentID})
+	if err != nil {
+		return nil, err
+	}
+	return paymentFromProto(resp.Payment), nil
+}
+
+// Refund returns amount of what was captured. Retrying with the same
+// idempotencyKey does not refund twice.
+func (c *Client) Refund(ctx context.Context, paymentID string, amount money.Money, idempotencyKey string) (*Payment, error) {
+	resp, err := c.service.Refund(ctx, &pb.RefundRequest{
+		PaymentId:      paymentID,
+		Amount:         money.ToProto(amount),
+		IdempotencyKey: idempotencyKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return paymentFromProto(resp.Payment), nil
+}
+
+func (c *Client) GetPayment(ctx context.Context, paymentID string) (*Payment, error) {
+	resp, err := c.service.GetPayment(ctx, &pb.GetPaymentRequest{PaymentId: paymentID})
+	if err != nil {
+		return nil, err
+	}
+	return paymentFromProto(resp.Payment), nil
+}
+
+// GetPaymentForOrder returns the payment of an order, failing with
+// codes.NotFound if it has none.
+func (c *Client) GetPaymentForOrder(ctx context.Context, orderID string) (*Payment, error) {
+	resp, err := c.service.GetPayment(ctx, &pb.GetPaymentRequest{OrderId: orderID})
+	if err != nil {
+		return nil, err
+	}
+	return paymentFromProto(resp.Payment), nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"microservice/auth"
+	"microservice/payment"
+
+	githubenv "github.com/kelseyhightower/envconfig"
+	"github.com/tinrab/retry"
+)
+
+type Config struct {
+	DatabaseURL string `envconfig:"DATABASE_URL" required:"true"`
+	// Provider names the payment processor. Only the in-process fake, which
+	// approves everything but the fake_declined and fake_timeout payment
+	// methods, is built in. There is no default, so that no deployment takes
+	// fake payments without asking for them.
+	Provider string `envconfig:"PAYMENT_PROVIDER" required:"true"`
+	// ServiceSecret verifies the service tokens of the order service, the
+	// only caller.
+	ServiceSecret string `envconfig:"SERVICE_SECRET" required:"true"`
+}
+
+func main() {
+	var cfg Config
+	if err := githubenv.Process("", &cfg); err != nil {
+		log.Fatal(err)
+	}
+
+	var provider payment.Provider
+	switch cfg.Provider {
+	case "fake":
+		provider = payment.NewFakeProvider()
+	default:
+		log.Fatalf("unknown payment provider %q", cfg.Provider)
+	}
+
+	var r payment.Repository
+	retry.ForeverSleep(2*time.Second, func(_ int) (err error) {
+		r, err = payment.NewPostgresRepository(cfg.DatabaseURL)
+		if err != nil {
+			log.Println("retry connecting ", err)
+		}
+		return
+	})
+	defer r.Close()
+
+	log.Println("Listening on port 8080...")
+	s := payment.NewService(r, provider)
+	log.Fatal(payment.ListenGRPC(s, auth.NewServiceVerifier([]byte(cfg.ServiceSecret)), 8080))
+}
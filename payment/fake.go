@@ -0,0 +1,242 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"microservice/money"
+)
+
+// Operation names a Provider method, for scripting the fake provider.
+type Operation string
+
+const (
+	OpAuthorize Operation = "authorize"
+	OpCapture   Operation = "capture"
+	OpVoid      Operation = "void"
+	OpRefund    Operation = "refund"
+)
+
+// FakeOutcome is how the fake provider answers a call.
+type FakeOutcome string
+
+const (
+	FakeApprove FakeOutcome = "approve"
+	FakeDecline FakeOutcome = "decline"
+	// FakeTimeout fails with ErrProviderTimeout straight away, without the
+	// operation taking effect.
+	FakeTimeout FakeOutcome = "timeout"
+)
+
+// Payment methods the fake provider always declines or times out on when
+// authorizing. Every other method, the empty one included, is approved.
+const (
+	FakeMethodDeclined = "fake_declined"
+	FakeMethodTimeout  = "fake_timeout"
+)
+
+// FakeProvider is an in-process Provider for tests and local development. It
+// checks amounts the way a real processor would, so that the same calls always
+// get the same answers.
+//
+// Its authorizations are kept in memory, but each reference names the payment
+// and amount authorized, as in fake_<payment>_<units>_<currency>, so that a
+// restarted fake still recognises the references stored with payments. What
+// was done with them before the restart is forgotten, so the fake assumes they
+// may have been captured in full: they can be refunded up to the amount
+// authorized, or captured or voided once in case they were not. The payment
+// service keeps track of the rest anyway.
+type FakeProvider struct {
+	mu      sync.Mutex
+	scripts map[Operation][]FakeOutcome
+	// authorizations maps references to what was done with them, and
+	// references the payment each was authorized for.
+	authorizations map[string]*fakeAuthorization
+	references     map[string]string
+}
+
+type fakeAuthorization struct {
+	amount   money.Money
+	captured money.Money
+	refunded money.Money
+	voided   bool
+	// restored is set on authorizations rebuilt from their reference after a
+	// restart, which may have been captured before it.
+	restored bool
+}
+
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{
+		scripts:        map[Operation][]FakeOutcome{},
+		authorizations: map[string]*fakeAuthorization{},
+		references:     map[string]string{},
+	}
+}
+
+// Script queues outcomes for the next calls of op, which are answered with
+// them in order before falling back to the usual behaviour.
+func (f *FakeProvider) Script(op Operation, outcomes ...FakeOutcome) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.scripts[op] = append(f.scripts[op], outcomes...)
+}
+
+// scripted pops the next scripted outcome of op, or returns FakeApprove if
+// there is none. The caller must hold f.mu.
+func (f *FakeProvider) scripted(op Operation) FakeOutcome {
+	queue := f.scripts[op]
+	if len(queue) == 0 {
+		return FakeApprove
+	}
+	f.scripts[op] = queue[1:]
+	return queue[0]
+}
+
+func (f *FakeProvider) answer(ctx context.Context, op Operation, outcome FakeOutcome) error {
+	if ctx.Err() != nil {
+		outcome = FakeTimeout
+	}
+	switch outcome {
+	case FakeDecline:
+		return fmt.Errorf("%w: fake %s declined", ErrDeclined, op)
+	case FakeTimeout:
+		return fmt.Errorf("%w: fake %s", ErrProviderTimeout, op)
+	}
+	return nil
+}
+
+func (f *FakeProvider) Authorize(ctx context.Context, paymentID, method string, amount money.Money) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	outcome := f.scripted(OpAuthorize)
+	if outcome == FakeApprove {
+		switch method {
+		case FakeMethodDeclined:
+			outcome = FakeDecline
+		case FakeMethodTimeout:
+			outcome = FakeTimeout
+		}
+	}
+	if err := f.answer(ctx, OpAuthorize, outcome); err != nil {
+		return "", err
+	}
+	if reference, ok := f.references[paymentID]; ok {
+		return reference, nil
+	}
+	if amount.Units <= 0 {
+		return "", fmt.Errorf("%w: amount must be positive", ErrDeclined)
+	}
+
+	reference := fmt.Sprintf("fake_%s_%d_%s", paymentID, amount.Units, amount.Currency)
+	f.references[paymentID] = reference
+	f.authorizations[reference] = newFakeAuthorization(amount)
+	return reference, nil
+}
+
+func (f *FakeProvider) FindAuthorization(ctx context.Context, paymentID string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.answer(ctx, OpAuthorize, FakeApprove); err != nil {
+		return "", err
+	}
+	return f.references[paymentID], nil
+}
+
+func newFakeAuthorization(amount money.Money) *fakeAuthorization {
+	return &fakeAuthorization{
+		amount:   amount,
+		captured: money.New(0, amount.Currency),
+		refunded: money.New(0, amount.Currency),
+	}
+}
+
+// authorization returns the authorization with reference, rebuilding it from
+// the reference if it was made before the fake was restarted. The caller must
+// hold f.mu.
+func (f *FakeProvider) authorization(reference string) (*fakeAuthorization, bool) {
+	if a, ok := f.authorizations[reference]; ok {
+		return a, true
+	}
+	parts := strings.Split(reference, "_")
+	if len(parts) != 4 || parts[0] != "fake" || !money.ValidCurrency(parts[3]) {
+		return nil, false
+	}
+	units, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil || units <= 0 {
+		return nil, false
+	}
+	a := newFakeAuthorization(money.New(units, parts[3]))
+	a.restored = true
+	f.authorizations[reference] = a
+	f.references[parts[1]] = reference
+	return a, true
+}
+
+// refundable returns how much of a can still be refunded.
+func (a *fakeAuthorization) refundable() int64 {
+	captured := a.captured.Units
+	if a.restored && captured == 0 {
+		captured = a.amount.Units
+	}
+	return captured - a.refunded.Units
+}
+
+func (f *FakeProvider) Capture(ctx context.Context, reference string, amount money.Money) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.answer(ctx, OpCapture, f.scripted(OpCapture)); err != nil {
+		return err
+	}
+	a, ok := f.authorization(reference)
+	switch {
+	case !ok:
+		return fmt.Errorf("%w: unknown authorization %s", ErrDeclined, reference)
+	case a.voided:
+		return fmt.Errorf("%w: %s was voided", ErrDeclined, reference)
+	case a.captured.Units > 0 || a.refunded.Units > 0:
+		return fmt.Errorf("%w: %s was already captured", ErrDeclined, reference)
+	case amount.Currency != a.amount.Currency || amount.Units <= 0 || amount.Units > a.amount.Units:
+		return fmt.Errorf("%w: cannot capture %s %s of %s", ErrDeclined, amount.Decimal(), amount.Currency, reference)
+	}
+	a.captured = amount
+	return nil
+}
+
+func (f *FakeProvider) Void(ctx context.Context, reference string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.answer(ctx, OpVoid, f.scripted(OpVoid)); err != nil {
+		return err
+	}
+	a, ok := f.authorization(reference)
+	switch {
+	case !ok:
+		return fmt.Errorf("%w: unknown authorization %s", ErrDeclined, reference)
+	case a.captured.Units > 0 || a.refunded.Units > 0:
+		return fmt.Errorf("%w: %s was already captured", ErrDeclined, reference)
+	}
+	a.voided = true
+	return nil
+}
+
+func (f *FakeProvider) Refund(ctx context.Context, reference string, amount money.Money) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.answer(ctx, OpRefund, f.scripted(OpRefund)); err != nil {
+		return err
+	}
+	a, ok := f.authorization(reference)
+	switch {
+	case !ok:
+		return fmt.Errorf("%w: unknown authorization %s", ErrDeclined, reference)
+	case a.voided:
+		return fmt.Errorf("%w: %s was voided", ErrDeclined, reference)
+	case amount.Currency != a.amount.Currency || amount.Units <= 0 || amount.Units > a.refundable():
+		return fmt.Errorf("%w: cannot refund %s %s of %s", ErrDeclined, amount.Decimal(), amount.Currency, reference)
+	}
+	a.refunded.Units += amount.Units
+	return nil
+}
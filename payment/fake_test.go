@@ -0,0 +1,54 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"microservice/money"
+)
+
+func TestFakeProviderAfterRestart(t *testing.T) {
+	ctx := context.Background()
+	f := NewFakeProvider()
+	reference, err := f.Authorize(ctx, "p1", "", money.New(1000, "USD"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Capture(ctx, reference, money.New(800, "USD")); err != nil {
+		t.Fatal(err)
+	}
+
+	f = NewFakeProvider()
+	if err := f.Refund(ctx, reference, money.New(500, "USD")); err != nil {
+		t.Fatalf("refund after restart: %v", err)
+	}
+	if err := f.Capture(ctx, reference, money.New(800, "USD")); !errors.Is(err, ErrDeclined) {
+		t.Errorf("capture of a refunded authorization: error = %v, want %v", err, ErrDeclined)
+	}
+	if err := f.Refund(ctx, reference, money.New(600, "USD")); !errors.Is(err, ErrDeclined) {
+		t.Errorf("refund beyond the amount authorized: error = %v, want %v", err, ErrDeclined)
+	}
+}
+
+func TestFakeProviderCapturesOnceAfterRestart(t *testing.T) {
+	ctx := context.Background()
+	reference, err := NewFakeProvider().Authorize(ctx, "p1", "", money.New(1000, "USD"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := NewFakeProvider()
+	if err := f.Capture(ctx, reference, money.New(800, "USD")); err != nil {
+		t.Fatalf("capture after restart: %v", err)
+	}
+	if err := f.Capture(ctx, reference, money.New(800, "USD")); !errors.Is(err, ErrDeclined) {
+		t.Errorf("second capture: error = %v, want %v", err, ErrDeclined)
+	}
+	if err := f.Refund(ctx, reference, money.New(900, "USD")); !errors.Is(err, ErrDeclined) {
+		t.Errorf("refund beyond the amount captured: error = %v, want %v", err, ErrDeclined)
+	}
+	if err := f.Refund(ctx, reference, money.New(800, "USD")); err != nil {
+		t.Errorf("refund of the amount captured: %v", err)
+	}
+}
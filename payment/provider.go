@@ -0,0 +1,43 @@
+package payment
+
+import (
+	"context"
+	"errors"
+
+	"microservice/money"
+)
+
+var (
+	// ErrDeclined is returned when the provider refuses an operation, such as
+	// an authorization the customer's funds do not cover.
+	ErrDeclined = errors.New("payment declined")
+	// ErrProviderTimeout is returned when the provider does not answer in
+	// time. Whether the operation took effect is unknown.
+	ErrProviderTimeout = errors.New("payment provider timed out")
+)
+
+// gRPC ErrorInfo reasons attached to ErrDeclined and ErrProviderTimeout.
+const (
+	ReasonDeclined        = "PAYMENT_DECLINED"
+	ReasonProviderTimeout = "PAYMENT_PROVIDER_TIMEOUT"
+)
+
+// Provider moves money through a payment processor. Every method may fail with
+// ErrDeclined or ErrProviderTimeout, wrapped with the provider's reason.
+type Provider interface {
+	// Authorize holds amount on the payment method and returns the provider's
+	// reference for the authorization. paymentID identifies the attempt, so
+	// that providers can recognise retries.
+	Authorize(ctx context.Context, paymentID, method string, amount money.Money) (string, error)
+	// FindAuthorization returns the reference of the authorization made for
+	// paymentID, or "" if there is none. It settles authorizations that timed
+	// out, which may or may not have been made.
+	FindAuthorization(ctx context.Context, paymentID string) (string, error)
+	// Capture takes amount, at most what was authorized, and releases the
+	// rest.
+	Capture(ctx context.Context, reference string, amount money.Money) error
+	// Void releases an authorization that was not captured.
+	Void(ctx context.Context, reference string) error
+	// Refund returns amount of what was captured.
+	Refund(ctx context.Context, reference string, amount money.Money) error
+}
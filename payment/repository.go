@@ -0,0 +1,146 @@
+package payment
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"microservice/money"
+
+	"github.com/lib/pq"
+	_ "github.com/lib/pq"
+)
+
+// errPaymentExists is returned by PutPayment when the order already has a
+// payment.
+var errPaymentExists = errors.New("order already has a payment")
+
+type Repository interface {
+	Close() error
+	PutPayment(ctx context.Context, p *Payment) error
+	// UpdatePayment stores p if the stored payment is still in status from.
+	UpdatePayment(ctx context.Context, p *Payment, from Status) error
+	GetPayment(ctx context.Context, id string) (*Payment, error)
+	GetPaymentForOrder(ctx context.Context, orderID string) (*Payment, error)
+	HasRefund(ctx context.Context, paymentID, idempotencyKey string) (bool, error)
+	// PutRefund stores p, whose refunded amount includes amount, and records
+	// the refund under idempotencyKey if there is one.
+	PutRefund(ctx context.Context, p *Payment, from Status, idempotencyKey string, amount money.Money) error
+}
+
+type postgresRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresRepository(databaseURL string) (Repository, error) {
+	db, err := sql.Open("postgres", databaseURL)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Ping()
+	if err != nil {
+		return nil, err
+	}
+	return &postgresRepository{db: db}, nil
+}
+
+func (r *postgresRepository) Close() error {
+	return r.db.Close()
+}
+
+const paymentColumns = "id, order_id, account_id, status, amount_units, captured_units, refunded_units, currency, reference, failure_reason, created_at, updated_at"
+
+func (r *postgresRepository) PutPayment(ctx context.Context, p *Payment) error {
+	_, err := r.db.ExecContext(ctx,
+		"INSERT INTO payments ("+paymentColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)",
+		p.ID, p.OrderID, p.AccountID, p.Status, p.Amount.Units, p.Captured.Units, p.Refunded.Units, p.Amount.Currency,
+		p.Reference, p.FailureReason, p.CreatedAt, p.UpdatedAt)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "payments_order_id_key" {
+		return errPaymentExists
+	}
+	return err
+}
+
+func (r *postgresRepository) UpdatePayment(ctx context.Context, p *Payment, from Status) error {
+	return updatePayment(ctx, r.db, p, from)
+}
+
+func (r *postgresRepository) GetPayment(ctx context.Context, id string) (*Payment, error) {
+	return scanPayment(r.db.QueryRowContext(ctx, "SELECT "+paymentColumns+" FROM payments WHERE id = $1", id))
+}
+
+func (r *postgresRepository) GetPaymentForOrder(ctx context.Context, orderID string) (*Payment, error) {
+	return scanPayment(r.db.QueryRowContext(ctx, "SELECT "+paymentColumns+" FROM payments WHERE order_id = $1", orderID))
+}
+
+func (r *postgresRepository) HasRefund(ctx context.Context, paymentID, idempotencyKey string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM payment_refunds WHERE payment_id = $1 AND idempotency_key = $2)",
+		paymentID, idempotencyKey).Scan(&exists)
+	return exists, err
+}
+
+func (r *postgresRepository) PutRefund(ctx context.Context, p *Payment, from Status, idempotencyKey string, amount money.Money) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+	if err = updatePayment(ctx, tx, p, from); err != nil {
+		return err
+	}
+	if idempotencyKey == "" {
+		return nil
+	}
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO payment_refunds (payment_id, idempotency_key, amount_units, created_at) VALUES ($1, $2, $3, $4)",
+		p.ID, idempotencyKey, amount.Units, p.UpdatedAt)
+	return err
+}
+
+// execer is satisfied by both *sql.DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func updatePayment(ctx context.Context, db execer, p *Payment, from Status) error {
+	res, err := db.ExecContext(ctx, `
+		UPDATE payments SET status = $3, captured_units = $4, refunded_units = $5, reference = $6, failure_reason = $7, updated_at = $8
+		WHERE id = $1 AND status = $2
+		`, p.ID, from, p.Status, p.Captured.Units, p.Refunded.Units, p.Reference, p.FailureReason, p.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: payment %s is no longer %s", ErrInvalidTransition, p.ID, from)
+	}
+	return nil
+}
+
+func scanPayment(row *sql.Row) (*Payment, error) {
+	p := &Payment{}
+	var currency string
+	err := row.Scan(&p.ID, &p.OrderID, &p.AccountID, &p.Status, &p.Amount.Units, &p.Captured.Units, &p.Refunded.Units,
+		&currency, &p.Reference, &p.FailureReason, &p.CreatedAt, &p.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.Amount.Currency, p.Captured.Currency, p.Refunded.Currency = currency, currency, currency
+	return p, nil
+}
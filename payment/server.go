@@ -0,0 +1,157 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"microservice/auth"
+	"microservice/money"
+	pb "microservice/payment/pb"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// grpcServer implements the generated gRPC PaymentServiceServer.
+type grpcServer struct {
+	pb.UnimplementedPaymentServiceServer
+	service Service
+}
+
+// requiredRoles lists the RPCs with the role needed to call them. Payments
+// are only moved and read by the order service.
+var requiredRoles = map[string]string{
+	pb.PaymentService_Authorize_FullMethodName:  auth.RoleService,
+	pb.PaymentService_Capture_FullMethodName:    auth.RoleService,
+	pb.PaymentService_Void_FullMethodName:       auth.RoleService,
+	pb.PaymentService_Refund_FullMethodName:     auth.RoleService,
+	pb.PaymentService_GetPayment_FullMethodName: auth.RoleService,
+}
+
+// ListenGRPC starts a gRPC server for the Payment service that authenticates
+// callers with verifier.
+func ListenGRPC(service Service, verifier *auth.Verifier, port int) error {
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return err
+	}
+	s := grpc.NewServer(
+		grpc.UnaryInterceptor(auth.UnaryServerInterceptor(verifier, requiredRoles)),
+	)
+	pb.RegisterPaymentServiceServer(s, &grpcServer{service: service})
+	reflection.Register(s)
+	return s.Serve(lis)
+}
+
+func (s *grpcServer) Authorize(ctx context.Context, req *pb.AuthorizeRequest) (*pb.PaymentResponse, error) {
+	payment, err := s.service.Authorize(ctx, req.OrderId, req.AccountId, money.FromProto(req.Amount), req.PaymentMethod)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.PaymentResponse{Payment: paymentToProto(payment)}, nil
+}
+
+func (s *grpcServer) Capture(ctx context.Context, req *pb.CaptureRequest) (*pb.PaymentResponse, error) {
+	payment, err := s.service.Capture(ctx, req.PaymentId, money.FromProto(req.Amount))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.PaymentResponse{Payment: paymentToProto(payment)}, nil
+}
+
+func (s *grpcServer) Void(ctx context.Context, req *pb.VoidRequest) (*pb.PaymentResponse, error) {
+	payment, err := s.service.Void(ctx, req.PaymentId)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.PaymentResponse{Payment: paymentToProto(payment)}, nil
+}
+
+func (s *grpcServer) Refund(ctx context.Context, req *pb.RefundRequest) (*pb.PaymentResponse, error) {
+	payment, err := s.service.Refund(ctx, req.PaymentId, money.FromProto(req.Amount), req.IdempotencyKey)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.PaymentResponse{Payment: paymentToProto(payment)}, nil
+}
+
+func (s *grpcServer) GetPayment(ctx context.Context, req *pb.GetPaymentRequest) (*pb.PaymentResponse, error) {
+	var payment *Payment
+	var err error
+	switch {
+	case req.PaymentId != "" && req.OrderId != "":
+		return nil, status.Error(codes.InvalidArgument, "set either payment_id or order_id, not both")
+	case req.PaymentId != "":
+		payment, err = s.service.GetPayment(ctx, req.PaymentId)
+	default:
+		payment, err = s.service.GetPaymentForOrder(ctx, req.OrderId)
+	}
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.PaymentResponse{Payment: paymentToProto(payment)}, nil
+}
+
+// grpcError maps payment domain errors onto gRPC status codes. Declines and
+// provider timeouts carry an ErrorInfo detail so callers can tell them apart.
+func grpcError(err error) error {
+	switch {
+	case errors.Is(err, ErrDeclined):
+		return withReason(codes.FailedPrecondition, ReasonDeclined, err)
+	case errors.Is(err, ErrProviderTimeout):
+		return withReason(codes.Unavailable, ReasonProviderTimeout, err)
+	case errors.Is(err, ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, ErrInvalidPayment):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, ErrInvalidTransition):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return err
+}
+
+func withReason(code codes.Code, reason string, err error) error {
+	st, detailErr := status.New(code, err.Error()).WithDetails(&errdetails.ErrorInfo{
+		Reason: reason,
+		Domain: "payment",
+	})
+	if detailErr != nil {
+		return status.Error(code, err.Error())
+	}
+	return st.Err()
+}
+
+func paymentToProto(p *Payment) *pb.Payment {
+	paymentProto := &pb.Payment{
+		Id:        p.ID,
+		OrderId:   p.OrderID,
+		AccountId: p.AccountID,
+		Status:    string(p.Status),
+		Amount:    money.ToProto(p.Amount),
+		Captured:  money.ToProto(p.Captured),
+		Refunded:  money.ToProto(p.Refunded),
+	}
+	paymentProto.CreatedAt, _ = p.CreatedAt.MarshalBinary()
+	paymentProto.UpdatedAt, _ = p.UpdatedAt.MarshalBinary()
+	return paymentProto
+}
+
+func paymentFromProto(p *pb.Payment) *Payment {
+	payment := &Payment{
+		ID:        p.Id,
+		OrderID:   p.OrderId,
+		AccountID: p.AccountId,
+		Status:    Status(p.Status),
+		Amount:    money.FromProto(p.Amount),
+		Captured:  money.FromProto(p.Captured),
+		Refunded:  money.FromProto(p.Refunded),
+	}
+	payment.CreatedAt.UnmarshalBinary(p.CreatedAt)
+	payment.UpdatedAt.UnmarshalBinary(p.UpdatedAt)
+	return payment
+}
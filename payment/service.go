@@ -0,0 +1,256 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"microservice/money"
+
+	"github.com/segmentio/ksuid"
+)
+
+var (
+	ErrNotFound          = errors.New("payment not found")
+	ErrInvalidPayment    = errors.New("invalid payment")
+	ErrInvalidTransition = errors.New("invalid payment status transition")
+)
+
+// Status is the lifecycle state of a payment.
+type Status string
+
+const (
+	// StatusPending is a payment whose authorization was sent to the provider
+	// without an answer yet, or whose answer never came because the provider
+	// timed out. Whether the provider holds money for it is unknown until it
+	// is authorized again or voided.
+	StatusPending    Status = "pending"
+	StatusAuthorized Status = "authorized"
+	StatusCaptured   Status = "captured"
+	StatusVoided     Status = "voided"
+	// StatusRefunded is a payment whose capture was refunded in full.
+	StatusRefunded Status = "refunded"
+	// StatusFailed is a payment the provider declined. Authorizing the order
+	// again retries it.
+	StatusFailed Status = "failed"
+)
+
+type Service interface {
+	Authorize(ctx context.Context, orderID, accountID string, amount money.Money, method string) (*Payment, error)
+	Capture(ctx context.Context, id string, amount money.Money) (*Payment, error)
+	Void(ctx context.Context, id string) (*Payment, error)
+	Refund(ctx context.Context, id string, amount money.Money, idempotencyKey string) (*Payment, error)
+	GetPayment(ctx context.Context, id string) (*Payment, error)
+	GetPaymentForOrder(ctx context.Context, orderID string) (*Payment, error)
+}
+
+// Payment is the money taken for one order. It is authorized when the order is
+// placed, which holds Amount on the customer's payment method, then captured,
+// taking up to that amount, or voided, releasing it. Refunded is what has been
+// returned of what was captured.
+type Payment struct {
+	ID        string      `json:"id"`
+	OrderID   string      `json:"order_id"`
+	AccountID string      `json:"account_id"`
+	Status    Status      `json:"status"`
+	Amount    money.Money `json:"amount"`
+	Captured  money.Money `json:"captured"`
+	Refunded  money.Money `json:"refunded"`
+	// Reference is the provider's name for the authorization.
+	Reference string `json:"reference,omitempty"`
+	// FailureReason is why the last authorization attempt failed.
+	FailureReason string    `json:"failure_reason,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type paymentService struct {
+	repo     Repository
+	provider Provider
+}
+
+// NewService constructs a Service that moves money through provider.
+func NewService(repo Repository, provider Provider) Service {
+	return &paymentService{repo: repo, provider: provider}
+}
+
+// Authorize holds amount on the payment method for an order. An order has at
+// most one payment: once authorized, authorizing the order again returns it,
+// while an authorization that failed or was interrupted is retried.
+func (s *paymentService) Authorize(ctx context.Context, orderID, accountID string, amount money.Money, method string) (*Payment, error) {
+	if orderID == "" {
+		return nil, fmt.Errorf("%w: order is required", ErrInvalidPayment)
+	}
+	if !money.ValidCurrency(amount.Currency) || amount.Units <= 0 {
+		return nil, fmt.Errorf("%w: amount must be positive", ErrInvalidPayment)
+	}
+
+	now := time.Now().UTC()
+	payment := &Payment{
+		ID:        ksuid.New().String(),
+		OrderID:   orderID,
+		AccountID: accountID,
+		Status:    StatusPending,
+		Amount:    amount,
+		Captured:  money.New(0, amount.Currency),
+		Refunded:  money.New(0, amount.Currency),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	err := s.repo.PutPayment(ctx, payment)
+	if errors.Is(err, errPaymentExists) {
+		payment, err = s.repo.GetPaymentForOrder(ctx, orderID)
+		if err != nil {
+			return nil, err
+		}
+		if payment.Amount != amount {
+			return nil, fmt.Errorf("%w: order %s already has a payment of %s %s", ErrInvalidPayment, orderID, payment.Amount.Decimal(), payment.Amount.Currency)
+		}
+		if payment.Status != StatusPending && payment.Status != StatusFailed {
+			return payment, nil
+		}
+		if err := s.setStatus(ctx, payment, StatusPending); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	reference, authErr := s.provider.Authorize(ctx, payment.ID, method, amount)
+	if authErr != nil {
+		payment.FailureReason = authErr.Error()
+		// Money may be held after a timeout, so the payment stays pending
+		// for a retry or a void to settle
+		status := StatusFailed
+		if errors.Is(authErr, ErrProviderTimeout) {
+			status = StatusPending
+		}
+		if err := s.setStatus(context.WithoutCancel(ctx), payment, status); err != nil {
+			return nil, err
+		}
+		return nil, authErr
+	}
+	payment.Reference = reference
+	payment.FailureReason = ""
+	if err := s.setStatus(context.WithoutCancel(ctx), payment, StatusAuthorized); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// Capture takes amount, at most what was authorized, and releases the rest.
+// Capturing a captured payment again with the same amount returns it.
+func (s *paymentService) Capture(ctx context.Context, id string, amount money.Money) (*Payment, error) {
+	payment, err := s.repo.GetPayment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status == StatusCaptured && payment.Captured == amount {
+		return payment, nil
+	}
+	if payment.Status != StatusAuthorized {
+		return nil, fmt.Errorf("%w: cannot capture a payment that is %s", ErrInvalidTransition, payment.Status)
+	}
+	if amount.Currency != payment.Amount.Currency || amount.Units <= 0 || amount.Units > payment.Amount.Units {
+		return nil, fmt.Errorf("%w: capture must be positive and at most %s %s", ErrInvalidPayment, payment.Amount.Decimal(), payment.Amount.Currency)
+	}
+
+	if err := s.provider.Capture(ctx, payment.Reference, amount); err != nil {
+		return nil, err
+	}
+	payment.Captured = amount
+	if err := s.setStatus(context.WithoutCancel(ctx), payment, StatusCaptured); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// Void releases an authorized payment. A pending payment, whose authorization
+// may have been made without an answer, is first looked up with the provider
+// and voided if it was. Voiding a voided or failed payment, which holds
+// nothing, returns it unchanged.
+func (s *paymentService) Void(ctx context.Context, id string) (*Payment, error) {
+	payment, err := s.repo.GetPayment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status == StatusVoided || payment.Status == StatusFailed {
+		return payment, nil
+	}
+	if payment.Status == StatusPending {
+		payment.Reference, err = s.provider.FindAuthorization(ctx, payment.ID)
+		if err != nil {
+			return nil, err
+		}
+	} else if payment.Status != StatusAuthorized {
+		return nil, fmt.Errorf("%w: cannot void a payment that is %s", ErrInvalidTransition, payment.Status)
+	}
+
+	if payment.Reference != "" {
+		if err := s.provider.Void(ctx, payment.Reference); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.setStatus(context.WithoutCancel(ctx), payment, StatusVoided); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// Refund returns amount of what was captured. A non-empty idempotencyKey that
+// was already used returns the payment without refunding again.
+func (s *paymentService) Refund(ctx context.Context, id string, amount money.Money, idempotencyKey string) (*Payment, error) {
+	payment, err := s.repo.GetPayment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if idempotencyKey != "" {
+		refunded, err := s.repo.HasRefund(ctx, id, idempotencyKey)
+		if err != nil {
+			return nil, err
+		}
+		if refunded {
+			return payment, nil
+		}
+	}
+	if payment.Status != StatusCaptured {
+		return nil, fmt.Errorf("%w: cannot refund a payment that is %s", ErrInvalidTransition, payment.Status)
+	}
+	left := payment.Captured.Units - payment.Refunded.Units
+	if amount.Currency != payment.Captured.Currency || amount.Units <= 0 || amount.Units > left {
+		return nil, fmt.Errorf("%w: refund must be positive and at most %s %s", ErrInvalidPayment,
+			money.New(left, payment.Captured.Currency).Decimal(), payment.Captured.Currency)
+	}
+
+	if err := s.provider.Refund(ctx, payment.Reference, amount); err != nil {
+		return nil, err
+	}
+	from := payment.Status
+	payment.Refunded.Units += amount.Units
+	if payment.Refunded.Units == payment.Captured.Units {
+		payment.Status = StatusRefunded
+	}
+	payment.UpdatedAt = time.Now().UTC()
+	if err := s.repo.PutRefund(context.WithoutCancel(ctx), payment, from, idempotencyKey, amount); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+func (s *paymentService) GetPayment(ctx context.Context, id string) (*Payment, error) {
+	return s.repo.GetPayment(ctx, id)
+}
+
+func (s *paymentService) GetPaymentForOrder(ctx context.Context, orderID string) (*Payment, error) {
+	return s.repo.GetPaymentForOrder(ctx, orderID)
+}
+
+// setStatus moves a payment to status and stores it. It fails with
+// ErrInvalidTransition if the stored payment changed status meanwhile.
+func (s *paymentService) setStatus(ctx context.Context, payment *Payment, status Status) error {
+	from := payment.Status
+	payment.Status = status
+	payment.UpdatedAt = time.Now().UTC()
+	return s.repo.UpdatePayment(ctx, payment, from)
+}
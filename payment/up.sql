@@ -0,0 +1,26 @@
+-- One payment per order. Amounts are in minor units of currency.
+CREATE TABLE IF NOT EXISTS payments (
+    id CHAR(27) PRIMARY KEY,
+    order_id CHAR(27) NOT NULL,
+    account_id CHAR(27) NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    amount_units BIGINT NOT NULL CHECK (amount_units > 0),
+    captured_units BIGINT NOT NULL DEFAULT 0,
+    refunded_units BIGINT NOT NULL DEFAULT 0 CHECK (refunded_units <= captured_units),
+    currency CHAR(3) NOT NULL,
+    -- The provider's reference for the authorization; empty until it is made.
+    reference VARCHAR(255) NOT NULL DEFAULT '',
+    failure_reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    CONSTRAINT payments_order_id_key UNIQUE (order_id)
+);
+
+-- Refunds made with an idempotency key, so that a retry refunds only once.
+CREATE TABLE IF NOT EXISTS payment_refunds (
+    payment_id CHAR(27) REFERENCES payments (id) ON DELETE CASCADE,
+    idempotency_key VARCHAR(255) NOT NULL,
+    amount_units BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (payment_id, idempotency_key)
+);